@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "tasks_deleted_at_idx";
+
+UPDATE "tasks" SET "deleted_at" = '0001-01-01' WHERE "deleted_at" IS NULL;
@@ -0,0 +1,5 @@
+-- tasks were written with a zero deleted_at before soft deletes, they are
+-- live tasks and have to read as such
+UPDATE "tasks" SET "deleted_at" = NULL WHERE "deleted_at" = '0001-01-01';
+
+CREATE INDEX IF NOT EXISTS "tasks_deleted_at_idx" ON "tasks" ("deleted_at");
@@ -0,0 +1,4 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "search_vector" TSVECTOR
+   GENERATED ALWAYS AS (to_tsvector('simple', coalesce("title", '') || ' ' || coalesce("todo", ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS "tasks_search_vector_idx" ON "tasks" USING GIN ("search_vector");
@@ -0,0 +1,6 @@
+ALTER TABLE "tasks"
+   ADD COLUMN IF NOT EXISTS "due_at" TIMESTAMP,
+   ADD COLUMN IF NOT EXISTS "remind_at" TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS "tasks_open_due_at_idx" ON "tasks" ("due_at")
+   WHERE "completed" = FALSE AND "deleted_at" IS NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "tasks_pending_due_notification_idx";
+DROP INDEX IF EXISTS "tasks_pending_reminder_idx";
+
+ALTER TABLE "tasks"
+   DROP COLUMN IF EXISTS "due_notified_at",
+   DROP COLUMN IF EXISTS "reminded_at";
@@ -0,0 +1,9 @@
+ALTER TABLE "tasks"
+   ADD COLUMN IF NOT EXISTS "reminded_at" TIMESTAMP,
+   ADD COLUMN IF NOT EXISTS "due_notified_at" TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS "tasks_pending_reminder_idx" ON "tasks" ("remind_at")
+   WHERE "reminded_at" IS NULL AND "completed" = FALSE AND "deleted_at" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_pending_due_notification_idx" ON "tasks" ("due_at")
+   WHERE "due_notified_at" IS NULL AND "completed" = FALSE AND "deleted_at" IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "tasks_series_id_due_at_idx";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "series_id";
+
+DROP TABLE IF EXISTS "task_series";
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "task_series" (
+   "id" BIGINT PRIMARY KEY,
+   "rule" TEXT NOT NULL,
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "updated_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "stopped_at" TIMESTAMP
+);
+
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "series_id" BIGINT REFERENCES "task_series" ("id");
+
+-- guards against generating the same occurrence twice when a task is
+-- completed, reopened and completed again
+CREATE UNIQUE INDEX IF NOT EXISTS "tasks_series_id_due_at_idx" ON "tasks" ("series_id", "due_at")
+   WHERE "deleted_at" IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "parent_id" BIGINT REFERENCES "tasks" ("id") ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "tasks_parent_id_idx" ON "tasks" ("parent_id");
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "projects" (
+   "id" BIGINT PRIMARY KEY,
+   "name" TEXT NOT NULL,
+   "description" TEXT NOT NULL DEFAULT '',
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "updated_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "deleted_at" TIMESTAMP
+);
+
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "project_id" BIGINT REFERENCES "projects" ("id") ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_project_id_idx" ON "tasks" ("project_id");
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "tags" (
+   "id" BIGINT PRIMARY KEY,
+   "name" TEXT NOT NULL UNIQUE,
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "updated_at" TIMESTAMP NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE IF NOT EXISTS "task_tags" (
+   "task_id" BIGINT NOT NULL REFERENCES "tasks" ("id") ON DELETE CASCADE,
+   "tag_id" BIGINT NOT NULL REFERENCES "tags" ("id") ON DELETE CASCADE,
+   PRIMARY KEY ("task_id", "tag_id")
+);
+
+CREATE INDEX IF NOT EXISTS "task_tags_tag_id_idx" ON "task_tags" ("tag_id");
@@ -0,0 +1,2 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "priority" TEXT NOT NULL DEFAULT 'none'
+   CHECK ("priority" IN ('none', 'low', 'medium', 'high', 'urgent'));
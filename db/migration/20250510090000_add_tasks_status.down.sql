@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS "tasks_status_idx";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "completed";
+
+ALTER TABLE "tasks" ADD COLUMN "completed" BOOLEAN DEFAULT FALSE;
+
+UPDATE "tasks" SET "completed" = ("status" = 'done');
+
+CREATE INDEX IF NOT EXISTS "tasks_open_due_at_idx" ON "tasks" ("due_at")
+   WHERE "completed" = FALSE AND "deleted_at" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_pending_reminder_idx" ON "tasks" ("remind_at")
+   WHERE "reminded_at" IS NULL AND "completed" = FALSE AND "deleted_at" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_pending_due_notification_idx" ON "tasks" ("due_at")
+   WHERE "due_notified_at" IS NULL AND "completed" = FALSE AND "deleted_at" IS NULL;
+
+ALTER TABLE "tasks"
+   DROP COLUMN IF EXISTS "completed_at",
+   DROP COLUMN IF EXISTS "started_at",
+   DROP COLUMN IF EXISTS "status";
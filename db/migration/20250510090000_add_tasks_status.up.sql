@@ -0,0 +1,23 @@
+ALTER TABLE "tasks"
+   ADD COLUMN IF NOT EXISTS "status" TEXT NOT NULL DEFAULT 'todo',
+   ADD COLUMN IF NOT EXISTS "started_at" TIMESTAMP,
+   ADD COLUMN IF NOT EXISTS "completed_at" TIMESTAMP;
+
+UPDATE "tasks" SET "status" = 'done', "completed_at" = "updated_at" WHERE "completed" = TRUE;
+
+-- completed is derived from the status from now on, dropping the column drops
+-- the partial indexes on it as well
+ALTER TABLE "tasks" DROP COLUMN "completed";
+
+ALTER TABLE "tasks" ADD COLUMN "completed" BOOLEAN GENERATED ALWAYS AS ("status" = 'done') STORED;
+
+CREATE INDEX IF NOT EXISTS "tasks_status_idx" ON "tasks" ("status");
+
+CREATE INDEX IF NOT EXISTS "tasks_open_due_at_idx" ON "tasks" ("due_at")
+   WHERE "completed" = FALSE AND "deleted_at" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_pending_reminder_idx" ON "tasks" ("remind_at")
+   WHERE "reminded_at" IS NULL AND "completed" = FALSE AND "deleted_at" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_pending_due_notification_idx" ON "tasks" ("due_at")
+   WHERE "due_notified_at" IS NULL AND "completed" = FALSE AND "deleted_at" IS NULL;
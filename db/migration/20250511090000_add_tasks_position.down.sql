@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "tasks_position_idx";
+
+DROP INDEX IF EXISTS "tasks_status_position_idx";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "position";
@@ -0,0 +1,17 @@
+-- positions are fractional ranks compared bytewise, see utils.RankBetween
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "position" TEXT COLLATE "C";
+
+-- existing tasks keep their creation order, the trailing digit keeps the
+-- ranks from ending with the lowest digit
+UPDATE "tasks" SET "position" = ranked."position"
+FROM (
+   SELECT "id", LPAD(TO_HEX(ROW_NUMBER() OVER (ORDER BY "created_at", "id")), 8, '0') || 'V' AS "position"
+   FROM "tasks"
+) AS ranked
+WHERE "tasks"."id" = ranked."id";
+
+ALTER TABLE "tasks" ALTER COLUMN "position" SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_status_position_idx" ON "tasks" ("status", "position");
+
+CREATE INDEX IF NOT EXISTS "tasks_position_idx" ON "tasks" ("position");
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS "task_events" (
+   "id" BIGINT PRIMARY KEY,
+   "task_id" BIGINT NOT NULL REFERENCES "tasks" ("id") ON DELETE CASCADE,
+   "kind" VARCHAR(16) NOT NULL,
+   "actor" VARCHAR(255) NOT NULL DEFAULT '',
+   "changes" JSONB NOT NULL DEFAULT '{}',
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX IF NOT EXISTS "task_events_task_id_created_at_idx" ON "task_events" ("task_id", "created_at" DESC);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS "api_keys" (
+   "id" BIGINT PRIMARY KEY,
+   "name" TEXT NOT NULL,
+   "prefix" VARCHAR(16) NOT NULL,
+   "key_hash" CHAR(64) NOT NULL UNIQUE,
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "last_used_at" TIMESTAMP,
+   "revoked_at" TIMESTAMP
+);
@@ -0,0 +1,7 @@
+ALTER TABLE "api_keys" DROP COLUMN IF EXISTS "user_id";
+
+DROP INDEX IF EXISTS "tasks_owner_id_idx";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "owner_id";
+
+DROP TABLE IF EXISTS "users";
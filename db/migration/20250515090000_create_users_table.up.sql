@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "users" (
+   "id" BIGINT PRIMARY KEY,
+   "email" VARCHAR(255) NOT NULL UNIQUE,
+   "password_hash" VARCHAR(255) NOT NULL,
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "updated_at" TIMESTAMP NOT NULL DEFAULT 'now()'
+);
+
+-- tasks created before accounts existed keep a NULL owner, they are only
+-- listed while authentication is disabled
+ALTER TABLE "tasks"
+   ADD COLUMN IF NOT EXISTS "owner_id" BIGINT REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "tasks_owner_id_idx" ON "tasks" ("owner_id");
+
+-- keys without a user are rejected, they have to be minted again for one
+ALTER TABLE "api_keys"
+   ADD COLUMN IF NOT EXISTS "user_id" BIGINT REFERENCES "users" ("id") ON DELETE CASCADE;
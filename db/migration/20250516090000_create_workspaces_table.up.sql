@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS "workspaces" (
+   "id" BIGINT PRIMARY KEY,
+   "name" VARCHAR(255) NOT NULL,
+   "slug" VARCHAR(63) NOT NULL UNIQUE,
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   "updated_at" TIMESTAMP NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE IF NOT EXISTS "workspace_members" (
+   "workspace_id" BIGINT NOT NULL REFERENCES "workspaces" ("id") ON DELETE CASCADE,
+   "user_id" BIGINT NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+   "role" VARCHAR(16) NOT NULL,
+   "created_at" TIMESTAMP NOT NULL DEFAULT 'now()',
+   PRIMARY KEY ("workspace_id", "user_id")
+);
+
+CREATE INDEX IF NOT EXISTS "workspace_members_user_id_idx" ON "workspace_members" ("user_id");
+
+-- tasks without a workspace are the personal tasks of their owner
+ALTER TABLE "tasks"
+   ADD COLUMN IF NOT EXISTS "workspace_id" BIGINT REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "tasks_workspace_id_idx" ON "tasks" ("workspace_id");
@@ -0,0 +1,25 @@
+DROP INDEX IF EXISTS "projects_owner_id_idx";
+DROP INDEX IF EXISTS "projects_workspace_id_idx";
+DROP INDEX IF EXISTS "tags_owner_id_name_key";
+DROP INDEX IF EXISTS "tags_workspace_id_name_key";
+
+-- tags of several tenants sharing a name are merged into the oldest one,
+-- projects stay apart
+INSERT INTO "task_tags" ("task_id", "tag_id")
+SELECT "task_tags"."task_id", (SELECT MIN("first"."id") FROM "tags" AS "first" WHERE "first"."name" = "tags"."name")
+FROM "task_tags"
+JOIN "tags" ON "tags"."id" = "task_tags"."tag_id"
+ON CONFLICT DO NOTHING;
+
+DELETE FROM "tags"
+WHERE "id" > (SELECT MIN("first"."id") FROM "tags" AS "first" WHERE "first"."name" = "tags"."name");
+
+ALTER TABLE "tags" ADD CONSTRAINT "tags_name_key" UNIQUE ("name");
+
+ALTER TABLE "projects"
+   DROP COLUMN IF EXISTS "owner_id",
+   DROP COLUMN IF EXISTS "workspace_id";
+
+ALTER TABLE "tags"
+   DROP COLUMN IF EXISTS "owner_id",
+   DROP COLUMN IF EXISTS "workspace_id";
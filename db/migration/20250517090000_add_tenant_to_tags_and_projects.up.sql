@@ -0,0 +1,86 @@
+-- tags and projects belong to a tenant like the tasks do. The ones of a
+-- workspace have no owner, they stay when the member who created them goes.
+ALTER TABLE "tags"
+   ADD COLUMN IF NOT EXISTS "workspace_id" BIGINT REFERENCES "workspaces" ("id") ON DELETE CASCADE,
+   ADD COLUMN IF NOT EXISTS "owner_id" BIGINT REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "projects"
+   ADD COLUMN IF NOT EXISTS "workspace_id" BIGINT REFERENCES "workspaces" ("id") ON DELETE CASCADE,
+   ADD COLUMN IF NOT EXISTS "owner_id" BIGINT REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "tags" DROP CONSTRAINT IF EXISTS "tags_name_key";
+
+-- the tags and projects were shared by every tenant, each tenant whose tasks
+-- use one gets a copy of it. The copies get IDs past the greatest one, the
+-- ID generator only hands out greater ones. Tags and projects which no task
+-- uses are left without a tenant, like the tasks created before accounts.
+CREATE TEMPORARY TABLE "tag_copies" AS
+SELECT
+   "tenants".*,
+   (SELECT COALESCE(MAX("id"), 0) FROM "tags")
+      + ROW_NUMBER() OVER (ORDER BY "tag_id", "workspace_id", "owner_id") AS "id"
+FROM (
+   SELECT DISTINCT
+      "task_tags"."tag_id",
+      "tasks"."workspace_id",
+      CASE WHEN "tasks"."workspace_id" IS NULL THEN "tasks"."owner_id" END AS "owner_id"
+   FROM "task_tags"
+   JOIN "tasks" ON "tasks"."id" = "task_tags"."task_id"
+) AS "tenants";
+
+INSERT INTO "tags" ("id", "name", "workspace_id", "owner_id", "created_at", "updated_at")
+SELECT "tag_copies"."id", "tags"."name", "tag_copies"."workspace_id", "tag_copies"."owner_id", "tags"."created_at", "tags"."updated_at"
+FROM "tag_copies"
+JOIN "tags" ON "tags"."id" = "tag_copies"."tag_id";
+
+UPDATE "task_tags"
+SET "tag_id" = "tag_copies"."id"
+FROM "tasks", "tag_copies"
+WHERE "tasks"."id" = "task_tags"."task_id"
+   AND "tag_copies"."tag_id" = "task_tags"."tag_id"
+   AND "tag_copies"."workspace_id" IS NOT DISTINCT FROM "tasks"."workspace_id"
+   AND "tag_copies"."owner_id" IS NOT DISTINCT FROM CASE WHEN "tasks"."workspace_id" IS NULL THEN "tasks"."owner_id" END;
+
+DELETE FROM "tags" WHERE "id" IN (SELECT "tag_id" FROM "tag_copies");
+
+DROP TABLE "tag_copies";
+
+CREATE TEMPORARY TABLE "project_copies" AS
+SELECT
+   "tenants".*,
+   (SELECT COALESCE(MAX("id"), 0) FROM "projects")
+      + ROW_NUMBER() OVER (ORDER BY "project_id", "workspace_id", "owner_id") AS "id"
+FROM (
+   SELECT DISTINCT
+      "project_id",
+      "workspace_id",
+      CASE WHEN "workspace_id" IS NULL THEN "owner_id" END AS "owner_id"
+   FROM "tasks"
+   WHERE "project_id" IS NOT NULL
+) AS "tenants";
+
+INSERT INTO "projects" ("id", "name", "description", "workspace_id", "owner_id", "created_at", "updated_at", "deleted_at")
+SELECT "project_copies"."id", "projects"."name", "projects"."description", "project_copies"."workspace_id", "project_copies"."owner_id", "projects"."created_at", "projects"."updated_at", "projects"."deleted_at"
+FROM "project_copies"
+JOIN "projects" ON "projects"."id" = "project_copies"."project_id";
+
+UPDATE "tasks"
+SET "project_id" = "project_copies"."id"
+FROM "project_copies"
+WHERE "project_copies"."project_id" = "tasks"."project_id"
+   AND "project_copies"."workspace_id" IS NOT DISTINCT FROM "tasks"."workspace_id"
+   AND "project_copies"."owner_id" IS NOT DISTINCT FROM CASE WHEN "tasks"."workspace_id" IS NULL THEN "tasks"."owner_id" END;
+
+DELETE FROM "projects" WHERE "id" IN (SELECT "project_id" FROM "project_copies");
+
+DROP TABLE "project_copies";
+
+-- tag names are unique within a workspace and within the personal tags of
+-- an owner, the tags without an owner included
+CREATE UNIQUE INDEX IF NOT EXISTS "tags_workspace_id_name_key" ON "tags" ("workspace_id", "name")
+   WHERE "workspace_id" IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "tags_owner_id_name_key" ON "tags" ("owner_id", "name") NULLS NOT DISTINCT
+   WHERE "workspace_id" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "projects_workspace_id_idx" ON "projects" ("workspace_id");
+CREATE INDEX IF NOT EXISTS "projects_owner_id_idx" ON "projects" ("owner_id");
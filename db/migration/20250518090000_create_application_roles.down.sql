@@ -0,0 +1,19 @@
+DROP POLICY IF EXISTS "projects_tenant_isolation" ON "projects";
+ALTER TABLE "projects" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "projects" DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "tags_tenant_isolation" ON "tags";
+ALTER TABLE "tags" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "tags" DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "tasks_tenant_isolation" ON "tasks";
+ALTER TABLE "tasks" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "tasks" DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS "app_tenant_visible"(BIGINT, BIGINT);
+
+-- the roles stay, other databases of the cluster may still use them
+ALTER DEFAULT PRIVILEGES IN SCHEMA "public"
+   REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM "todo_app", "todo_worker";
+REVOKE SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA "public" FROM "todo_app", "todo_worker";
+REVOKE USAGE ON SCHEMA "public" FROM "todo_app", "todo_worker";
@@ -0,0 +1,62 @@
+-- the migrations run as the owner of the tables, which row level security
+-- does not apply to when it is a superuser. The API connects as todo_app,
+-- which the policies apply to, and the workers as todo_worker, which
+-- bypasses them to go through the tasks of every tenant. Roles are shared
+-- by the databases of the cluster, their passwords are set outside of the
+-- migrations.
+DO $$
+BEGIN
+   IF NOT EXISTS (SELECT FROM "pg_roles" WHERE "rolname" = 'todo_app') THEN
+      CREATE ROLE "todo_app" LOGIN NOSUPERUSER NOBYPASSRLS;
+   END IF;
+   IF NOT EXISTS (SELECT FROM "pg_roles" WHERE "rolname" = 'todo_worker') THEN
+      CREATE ROLE "todo_worker" LOGIN NOSUPERUSER BYPASSRLS;
+   END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA "public" TO "todo_app", "todo_worker";
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA "public" TO "todo_app", "todo_worker";
+REVOKE ALL ON "schema_migrations" FROM "todo_app", "todo_worker";
+
+-- the tables of the later migrations are granted as well
+ALTER DEFAULT PRIVILEGES IN SCHEMA "public"
+   GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO "todo_app", "todo_worker";
+
+-- app.workspace_id and app.owner_id are set by the transactions of the
+-- repositories to the IDs of the tenant, 'none' standing for a missing ID.
+-- Rows of a workspace are shared by its members, the other ones are the
+-- personal rows of their owner. Sessions which set neither see no row.
+CREATE OR REPLACE FUNCTION "app_tenant_visible"("workspace_id" BIGINT, "owner_id" BIGINT)
+RETURNS BOOLEAN
+LANGUAGE SQL
+STABLE
+AS $$
+   SELECT CASE
+      WHEN COALESCE(current_setting('app.workspace_id', TRUE), '') = ''
+         OR COALESCE(current_setting('app.owner_id', TRUE), '') = '' THEN FALSE
+      WHEN current_setting('app.workspace_id', TRUE) <> 'none' THEN
+         "workspace_id" = current_setting('app.workspace_id', TRUE)::BIGINT
+      ELSE
+         "workspace_id" IS NULL
+         AND "owner_id" IS NOT DISTINCT FROM NULLIF(current_setting('app.owner_id', TRUE), 'none')::BIGINT
+   END
+$$;
+
+ALTER TABLE "tasks" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "tasks" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "tasks_tenant_isolation" ON "tasks"
+   USING ("app_tenant_visible"("workspace_id", "owner_id"));
+
+ALTER TABLE "tags" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "tags" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "tags_tenant_isolation" ON "tags"
+   USING ("app_tenant_visible"("workspace_id", "owner_id"));
+
+ALTER TABLE "projects" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "projects" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "projects_tenant_isolation" ON "projects"
+   USING ("app_tenant_visible"("workspace_id", "owner_id"));
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"todo-app/internal/config"
+	"todo-app/internal/db"
+	"todo-app/internal/model"
+	_repo "todo-app/internal/repository"
+	"todo-app/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// initialize logger configurations
+func initLogger() {
+	logLevel := logrus.ErrorLevel
+	switch config.Env() {
+	case "dev", "development":
+		logLevel = logrus.InfoLevel
+	}
+
+	logrus.SetFormatter(&logrus.TextFormatter{
+		ForceColors:     true,
+		DisableSorting:  true,
+		DisableColors:   false,
+		FullTimestamp:   true,
+		TimestampFormat: "15:04:05 02-01-2006",
+	})
+
+	logrus.SetOutput(os.Stdout)
+	logrus.SetReportCaller(true)
+	logrus.SetLevel(logLevel)
+}
+
+// initialize the generator of new IDs
+func initIDGenerator() {
+	kind := config.IDGenerator()
+	node, ok := config.IDNode()
+	if kind == utils.IDGeneratorSnowflake && !ok {
+		logrus.Fatal("Failed to create id generator: id.node is not set")
+	}
+
+	idGenerator, err := utils.NewIDGenerator(kind, node)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"generator": kind, "node": node}).Fatal("Failed to create id generator: ", err)
+	}
+	utils.SetIDGenerator(idGenerator)
+}
+
+func init() {
+	config.GetConf()
+	initLogger()
+	initIDGenerator()
+}
+
+// main mints an API key and prints it, the key cannot be shown again
+func main() {
+	name := flag.String("name", "", "name of the API key")
+	email := flag.String("email", "", "email of the user the API key acts for")
+
+	flag.Parse()
+	if *name == "" || *email == "" {
+		logrus.Fatal("name and email are required")
+	}
+
+	db.InitializePostgresConn(config.AppDatabaseDSN())
+
+	user, err := _repo.NewUserRepository(db.PostgresDB).FindByEmail(context.Background(), model.NormalizeEmail(*email))
+	if err != nil {
+		logrus.WithField("email", *email).Fatal("Failed to find user: ", err)
+	}
+
+	key, secret, err := model.NewAPIKey(*name, user.ID)
+	if err != nil {
+		logrus.Fatal("Failed to mint API key: ", err)
+	}
+
+	if err := _repo.NewAPIKeyRepository(db.PostgresDB).Create(context.Background(), key); err != nil {
+		logrus.WithField("name", *name).Fatal("Failed to create API key: ", err)
+	}
+
+	fmt.Println(secret)
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"crypto/rsa"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"todo-app/internal/config"
 	"todo-app/internal/db"
 	_taskHTTPHndlr "todo-app/internal/delivery/http"
+	"todo-app/internal/model"
+	"todo-app/internal/notifier"
 	_repo "todo-app/internal/repository"
 	_taskUscase "todo-app/internal/usecase"
+	"todo-app/internal/utils"
+	"todo-app/internal/worker"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
@@ -36,22 +45,146 @@ func initLogger() {
 	logrus.SetLevel(logLevel)
 }
 
-// run initLogger() before running main()
+// initialize the generator of new IDs
+func initIDGenerator() {
+	kind := config.IDGenerator()
+	node, ok := config.IDNode()
+	if kind == utils.IDGeneratorSnowflake && !ok {
+		logrus.Fatal("Failed to create id generator: id.node is not set")
+	}
+
+	idGenerator, err := utils.NewIDGenerator(kind, node)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"generator": kind, "node": node}).Fatal("Failed to create id generator: ", err)
+	}
+	utils.SetIDGenerator(idGenerator)
+}
+
+// loadTokenKeys reads the keys verifying bearer tokens from the config and
+// the files it points to
+func loadTokenKeys() (model.TokenKeys, error) {
+	keys := model.TokenKeys{
+		HMACSecret: []byte(config.AuthJWTSecret()),
+		RSAKeys:    map[string]*rsa.PublicKey{},
+		Issuer:     config.AuthJWTIssuer(),
+		Audience:   config.AuthJWTAudience(),
+	}
+
+	if path := config.AuthJWTJWKSFile(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return keys, err
+		}
+
+		keys.RSAKeys, err = utils.ParseJWKS(data)
+		if err != nil {
+			return keys, err
+		}
+	}
+
+	if path := config.AuthJWTPublicKeyFile(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return keys, err
+		}
+
+		keys.RSAKeys[""], err = utils.ParseRSAPublicKeyPEM(data)
+		if err != nil {
+			return keys, err
+		}
+	}
+
+	return keys, nil
+}
+
+// run initLogger() and initIDGenerator() before running main()
 func init() {
 	config.GetConf()
 	initLogger()
+	initIDGenerator()
 }
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	e := echo.New()
+	e.Validator = _taskHTTPHndlr.NewRequestValidator(config.PageSizeDefault(), config.PageSizeMax())
 
-	db.InitializePostgresConn()
+	db.InitializePostgresConn(config.AppDatabaseDSN())
 	db.InitializeRedisConn()
 
+	authMiddlewares := []echo.MiddlewareFunc{}
+	if config.AuthEnabled() {
+		keys, err := loadTokenKeys()
+		if err != nil {
+			logrus.Fatal("Failed to load token keys: ", err)
+		}
+
+		apiKeyRepo := _repo.NewAPIKeyRepository(db.PostgresDB)
+		authUsecase := _taskUscase.NewAuthUsecase(apiKeyRepo, keys)
+		authMiddlewares = append(authMiddlewares, _taskHTTPHndlr.NewAuthMiddleware(authUsecase))
+
+		userRepo := _repo.NewUserRepository(db.PostgresDB)
+
+		// tokens are only issued on login when they can be signed
+		if len(keys.HMACSecret) > 0 {
+			userUsecase := _taskUscase.NewUserUsecase(userRepo, keys, config.AuthJWTTTL())
+			_taskHTTPHndlr.NewUserHTTPHandler(e, userUsecase)
+		}
+
+		// workspaces need a principal to check the membership of
+		workspaceRepo := _repo.NewWorkspaceRepository(db.PostgresDB)
+		workspaceUsecase := _taskUscase.NewWorkspaceUsecase(workspaceRepo, userRepo)
+		authMiddlewares = append(authMiddlewares, _taskHTTPHndlr.NewWorkspaceMiddleware(
+			workspaceUsecase,
+			config.WorkspaceBaseDomain(),
+			config.WorkspaceClaim(),
+		))
+		_taskHTTPHndlr.NewWorkspaceHTTPHandler(e, workspaceUsecase, authMiddlewares...)
+	}
+
 	cacheRepo := _repo.NewCacheRepository(db.RedisClient)
 	taskRepo := _repo.NewTaskRepository(db.PostgresDB, cacheRepo)
-	taskUsecase := _taskUscase.NewTaskUsecase(taskRepo)
-	_taskHTTPHndlr.NewTaskHTTPHandler(e, taskUsecase)
+	taskUsecase := _taskUscase.NewTaskUsecase(taskRepo, model.TaskScoreWeights{
+		Priority: config.NextWeightPriority(),
+		Due:      config.NextWeightDue(),
+		Age:      config.NextWeightAge(),
+	}, model.NewTaskWorkflow(config.StatusTransitions()))
+	idempotencyUsecase := _taskUscase.NewIdempotencyUsecase(cacheRepo, config.IdempotencyKeyTTL(), config.IdempotencyPendingTTL())
+	_taskHTTPHndlr.NewTaskHTTPHandler(e, taskUsecase, idempotencyUsecase, authMiddlewares...)
+	_taskHTTPHndlr.NewBoardHTTPHandler(e, taskUsecase, authMiddlewares...)
+
+	projectRepo := _repo.NewProjectRepository(db.PostgresDB, cacheRepo)
+	projectUsecase := _taskUscase.NewProjectUsecase(projectRepo)
+	_taskHTTPHndlr.NewProjectHTTPHandler(e, projectUsecase, taskUsecase, authMiddlewares...)
+
+	tagRepo := _repo.NewTagRepository(db.PostgresDB, cacheRepo)
+	tagUsecase := _taskUscase.NewTagUsecase(tagRepo, taskRepo)
+	_taskHTTPHndlr.NewTagHTTPHandler(e, tagUsecase, authMiddlewares...)
+
+	// the workers go through the tasks of every tenant, which the role of
+	// the API is not allowed to
+	if config.ReminderEnabled() || config.PositionRebalanceEnabled() {
+		db.InitializeWorkerPostgresConn(config.WorkerDatabaseDSN())
+	}
+
+	if config.ReminderEnabled() {
+		reminderUsecase := _taskUscase.NewReminderUsecase(
+			_repo.NewTaskRepository(db.WorkerPostgresDB, cacheRepo),
+			config.ReminderBatchSize(),
+			notifier.NewNotifiersFromConfig()...,
+		)
+		go worker.NewReminderWorker(reminderUsecase, config.ReminderInterval()).Start(ctx)
+	}
+
+	if config.PositionRebalanceEnabled() {
+		positionUsecase := _taskUscase.NewPositionUsecase(
+			_repo.NewTaskRepository(db.WorkerPostgresDB, cacheRepo),
+			config.PositionRebalanceMaxLength(),
+		)
+		go worker.NewPositionWorker(positionUsecase, config.PositionRebalanceInterval()).Start(ctx)
+	}
 
 	s := &http.Server{
 		Addr:         ":" + config.ServerPort(),
@@ -59,5 +192,17 @@ func main() {
 		WriteTimeout: 2 * time.Minute,
 	}
 
-	logrus.Fatal(e.StartServer(s))
+	go func() {
+		if err := e.StartServer(s); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatal(err)
+		}
+	}()
+
+	// the workers stop with ctx, the server finishes the requests in flight
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logrus.Error(err)
+	}
 }
@@ -45,7 +45,7 @@ func main() {
 	step := flag.Int("step", 0, "migration step")
 
 	flag.Parse()
-	db.InitializePostgresConn()
+	db.InitializePostgresConn(config.DatabaseDSN())
 
 	sqlDB, err := db.PostgresDB.DB()
 	if err != nil {
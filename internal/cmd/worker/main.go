@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"todo-app/internal/config"
+	"todo-app/internal/db"
+	"todo-app/internal/notifier"
+	_repo "todo-app/internal/repository"
+	_usecase "todo-app/internal/usecase"
+	"todo-app/internal/utils"
+	"todo-app/internal/worker"
+
+	"github.com/sirupsen/logrus"
+)
+
+// initialize logger configurations
+func initLogger() {
+	logLevel := logrus.ErrorLevel
+	switch config.Env() {
+	case "dev", "development":
+		logLevel = logrus.InfoLevel
+	}
+
+	logrus.SetFormatter(&logrus.TextFormatter{
+		ForceColors:     true,
+		DisableSorting:  true,
+		DisableColors:   false,
+		FullTimestamp:   true,
+		TimestampFormat: "15:04:05 02-01-2006",
+	})
+
+	logrus.SetOutput(os.Stdout)
+	logrus.SetReportCaller(true)
+	logrus.SetLevel(logLevel)
+}
+
+// initialize the generator of new IDs
+func initIDGenerator() {
+	kind := config.IDGenerator()
+	node, ok := config.IDNode()
+	if kind == utils.IDGeneratorSnowflake && !ok {
+		logrus.Fatal("Failed to create id generator: id.node is not set")
+	}
+
+	idGenerator, err := utils.NewIDGenerator(kind, node)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"generator": kind, "node": node}).Fatal("Failed to create id generator: ", err)
+	}
+	utils.SetIDGenerator(idGenerator)
+}
+
+func init() {
+	config.GetConf()
+	initLogger()
+	initIDGenerator()
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db.InitializeWorkerPostgresConn(config.WorkerDatabaseDSN())
+	db.InitializeRedisConn()
+
+	cacheRepo := _repo.NewCacheRepository(db.RedisClient)
+	taskRepo := _repo.NewTaskRepository(db.WorkerPostgresDB, cacheRepo)
+	reminderUsecase := _usecase.NewReminderUsecase(
+		taskRepo,
+		config.ReminderBatchSize(),
+		notifier.NewNotifiersFromConfig()...,
+	)
+
+	positionUsecase := _usecase.NewPositionUsecase(taskRepo, config.PositionRebalanceMaxLength())
+	go worker.NewPositionWorker(positionUsecase, config.PositionRebalanceInterval()).Start(ctx)
+
+	logrus.Info("Reminder worker started...")
+	worker.NewReminderWorker(reminderUsecase, config.ReminderInterval()).Start(ctx)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -80,6 +81,58 @@ func DatabaseDSN() string {
 	// "postgres://postgres@db:5432/to-do-db?sslmode=disable"
 }
 
+// PostgresAppUsername is the role the API connects as, which the row level
+// security policies apply to
+func PostgresAppUsername() string {
+	if viper.IsSet("postgres.app_username") {
+		return viper.GetString("postgres.app_username")
+	}
+	return DefaultPostgresAppUsername
+}
+
+// PostgresAppPassword :nodoc:
+func PostgresAppPassword() string {
+	return viper.GetString("postgres.app_password")
+}
+
+// PostgresWorkerUsername is the role the workers connect as, which bypasses
+// the row level security policies to reach the tasks of every tenant
+func PostgresWorkerUsername() string {
+	if viper.IsSet("postgres.worker_username") {
+		return viper.GetString("postgres.worker_username")
+	}
+	return DefaultPostgresWorkerUsername
+}
+
+// PostgresWorkerPassword :nodoc:
+func PostgresWorkerPassword() string {
+	return viper.GetString("postgres.worker_password")
+}
+
+// AppDatabaseDSN is the DSN of the API, DatabaseDSN is left to the
+// migrations which run as the owner of the tables
+func AppDatabaseDSN() string {
+	return roleDatabaseDSN(PostgresAppUsername(), PostgresAppPassword())
+}
+
+// WorkerDatabaseDSN :nodoc:
+func WorkerDatabaseDSN() string {
+	return roleDatabaseDSN(PostgresWorkerUsername(), PostgresWorkerPassword())
+}
+
+func roleDatabaseDSN(username, password string) string {
+	user := url.User(username)
+	if password != "" {
+		user = url.UserPassword(username, password)
+	}
+
+	return fmt.Sprintf("postgres://%s@%s/%s?sslmode=%s",
+		user.String(),
+		PostgresHost(),
+		PostgresDatabase(),
+		PostgresSSLMode())
+}
+
 // PostgresMaxIdleConns :nodoc:
 func PostgresMaxIdleConns() int {
 	if viper.GetInt("postgres.max_idle_conns") <= 0 {
@@ -133,3 +186,261 @@ func RedisPassword() string {
 func RedisDB() int {
 	return viper.GetInt("redis.db")
 }
+
+// NextWeightPriority weights the priority level when ranking the next tasks
+func NextWeightPriority() float64 {
+	if !viper.IsSet("next.weight.priority") {
+		return DefaultNextWeightPriority
+	}
+
+	return viper.GetFloat64("next.weight.priority")
+}
+
+// NextWeightDue weights the due date urgency when ranking the next tasks
+func NextWeightDue() float64 {
+	if !viper.IsSet("next.weight.due") {
+		return DefaultNextWeightDue
+	}
+
+	return viper.GetFloat64("next.weight.due")
+}
+
+// NextWeightAge weights the age in days when ranking the next tasks
+func NextWeightAge() float64 {
+	if !viper.IsSet("next.weight.age") {
+		return DefaultNextWeightAge
+	}
+
+	return viper.GetFloat64("next.weight.age")
+}
+
+// StatusTransitions maps each task status to the statuses a task may move to
+// from it, done must be part of the workflow
+func StatusTransitions() map[string][]string {
+	if !viper.IsSet("status.transitions") {
+		return DefaultStatusTransitions
+	}
+
+	return viper.GetStringMapStringSlice("status.transitions")
+}
+
+// PageSizeDefault is the size of a listing page when none is requested
+func PageSizeDefault() int64 {
+	if viper.GetInt64("page.default_size") <= 0 {
+		return DefaultPageSizeDefault
+	}
+
+	return viper.GetInt64("page.default_size")
+}
+
+// PageSizeMax is the largest page size a listing may be requested with
+func PageSizeMax() int64 {
+	if viper.GetInt64("page.max_size") <= 0 {
+		return DefaultPageSizeMax
+	}
+
+	return viper.GetInt64("page.max_size")
+}
+
+// BulkMaxOperations limits the operations of a single bulk request
+func BulkMaxOperations() int {
+	if viper.GetInt("bulk.max_operations") <= 0 {
+		return DefaultBulkMaxOperations
+	}
+
+	return viper.GetInt("bulk.max_operations")
+}
+
+// IDGenerator is one of snowflake, ulid or uuidv7, snowflake by default.
+// Only snowflake IDs sort after the IDs minted before and never collide
+// between replicas.
+func IDGenerator() string {
+	if viper.GetString("id.generator") == "" {
+		return DefaultIDGenerator
+	}
+
+	return viper.GetString("id.generator")
+}
+
+// IDNode is the snowflake node, between 0 and 1023. It must differ between
+// replicas, so it has no default and ok is false when it is not set.
+func IDNode() (node int64, ok bool) {
+	return viper.GetInt64("id.node"), viper.IsSet("id.node")
+}
+
+// AuthEnabled requires a bearer token or an API key on every /v1 route, it
+// is on unless turned off explicitly
+func AuthEnabled() bool {
+	if !viper.IsSet("auth.enabled") {
+		return true
+	}
+	return viper.GetBool("auth.enabled")
+}
+
+// AuthJWTSecret verifies HS256 tokens and signs the tokens issued on login,
+// both are disabled when it is empty
+func AuthJWTSecret() string {
+	return viper.GetString("auth.jwt.secret")
+}
+
+// AuthJWTPublicKeyFile is a PEM RSA public key verifying RS256 tokens
+// without a kid listed in the JWKS file
+func AuthJWTPublicKeyFile() string {
+	return viper.GetString("auth.jwt.public_key_file")
+}
+
+// AuthJWTJWKSFile is a local JSON Web Key Set verifying RS256 tokens by kid
+func AuthJWTJWKSFile() string {
+	return viper.GetString("auth.jwt.jwks_file")
+}
+
+// AuthJWTIssuer :nodoc:
+func AuthJWTIssuer() string {
+	return viper.GetString("auth.jwt.issuer")
+}
+
+// AuthJWTAudience :nodoc:
+func AuthJWTAudience() string {
+	return viper.GetString("auth.jwt.audience")
+}
+
+// AuthJWTTTL is how long the tokens issued on login are valid
+func AuthJWTTTL() time.Duration {
+	cfg := viper.GetString("auth.jwt.ttl")
+	return utils.ParseDuration(cfg, DefaultAuthJWTTTL)
+}
+
+// WorkspaceBaseDomain resolves the workspace of a request from the
+// subdomain of its host below this domain, e.g. acme.todo.example.com for
+// todo.example.com. Subdomains are ignored when it is empty.
+func WorkspaceBaseDomain() string {
+	return viper.GetString("workspace.base_domain")
+}
+
+// WorkspaceClaim is the bearer token claim holding the workspace of the
+// request
+func WorkspaceClaim() string {
+	if !viper.IsSet("workspace.claim") {
+		return DefaultWorkspaceClaim
+	}
+
+	return viper.GetString("workspace.claim")
+}
+
+// IdempotencyKeyTTL is how long the response of a request made with an
+// Idempotency-Key is kept for replay
+func IdempotencyKeyTTL() time.Duration {
+	cfg := viper.GetString("idempotency.key_ttl")
+	return utils.ParseDuration(cfg, DefaultIdempotencyKeyTTL)
+}
+
+// IdempotencyPendingTTL is how long a request made with an Idempotency-Key
+// holds the key while it is in progress, it has to outlast the request. A
+// key left behind by a crashed replica is freed after it.
+func IdempotencyPendingTTL() time.Duration {
+	cfg := viper.GetString("idempotency.pending_ttl")
+	return utils.ParseDuration(cfg, DefaultIdempotencyPendingTTL)
+}
+
+// PositionRebalanceEnabled runs the position rebalancer alongside the http
+// server
+func PositionRebalanceEnabled() bool {
+	return viper.GetBool("position.rebalance.enabled")
+}
+
+// PositionRebalanceInterval :nodoc:
+func PositionRebalanceInterval() time.Duration {
+	cfg := viper.GetString("position.rebalance.interval")
+	return utils.ParseDuration(cfg, DefaultPositionRebalanceInterval)
+}
+
+// PositionRebalanceMaxLength is the position length above which every
+// position is renumbered
+func PositionRebalanceMaxLength() int {
+	if viper.GetInt("position.rebalance.max_length") <= 0 {
+		return DefaultPositionRebalanceMaxLength
+	}
+
+	return viper.GetInt("position.rebalance.max_length")
+}
+
+// ReminderEnabled runs the reminder worker alongside the http server
+func ReminderEnabled() bool {
+	return viper.GetBool("reminder.enabled")
+}
+
+// ReminderInterval :nodoc:
+func ReminderInterval() time.Duration {
+	cfg := viper.GetString("reminder.interval")
+	return utils.ParseDuration(cfg, DefaultReminderInterval)
+}
+
+// ReminderBatchSize :nodoc:
+func ReminderBatchSize() int {
+	if viper.GetInt("reminder.batch_size") <= 0 {
+		return DefaultReminderBatchSize
+	}
+
+	return viper.GetInt("reminder.batch_size")
+}
+
+// NotifierSinks :nodoc:
+func NotifierSinks() []string {
+	if !viper.IsSet("notifier.sinks") {
+		return []string{"log"}
+	}
+
+	return viper.GetStringSlice("notifier.sinks")
+}
+
+// NotifierWebhookURL :nodoc:
+func NotifierWebhookURL() string {
+	return viper.GetString("notifier.webhook.url")
+}
+
+// NotifierWebhookTimeout :nodoc:
+func NotifierWebhookTimeout() time.Duration {
+	cfg := viper.GetString("notifier.webhook.timeout")
+	return utils.ParseDuration(cfg, DefaultNotifierWebhookTimeout)
+}
+
+// NotifierSMTPHost :nodoc:
+func NotifierSMTPHost() string {
+	return viper.GetString("notifier.smtp.host")
+}
+
+// NotifierSMTPPort :nodoc:
+func NotifierSMTPPort() string {
+	if viper.GetString("notifier.smtp.port") == "" {
+		return DefaultNotifierSMTPPort
+	}
+
+	return viper.GetString("notifier.smtp.port")
+}
+
+// NotifierSMTPUsername :nodoc:
+func NotifierSMTPUsername() string {
+	return viper.GetString("notifier.smtp.username")
+}
+
+// NotifierSMTPPassword :nodoc:
+func NotifierSMTPPassword() string {
+	return viper.GetString("notifier.smtp.password")
+}
+
+// NotifierSMTPFrom :nodoc:
+func NotifierSMTPFrom() string {
+	return viper.GetString("notifier.smtp.from")
+}
+
+// NotifierSMTPTo :nodoc:
+func NotifierSMTPTo() []string {
+	return viper.GetStringSlice("notifier.smtp.to")
+}
+
+// NotifierSMTPTimeout bounds sending a single mail, so that a stuck server
+// does not hold up the reminder worker
+func NotifierSMTPTimeout() time.Duration {
+	cfg := viper.GetString("notifier.smtp.timeout")
+	return utils.ParseDuration(cfg, DefaultNotifierSMTPTimeout)
+}
@@ -8,4 +8,46 @@ const (
 	DefaultPostgresConnMaxLifetime = 1 * time.Hour
 	DefaultPostgresPingInterval    = 1 * time.Second
 	DefaultPostgresRetryAttempts   = 3
+	DefaultPostgresAppUsername     = "todo_app"
+	DefaultPostgresWorkerUsername  = "todo_worker"
+
+	DefaultDueWithin = 24 * time.Hour
+
+	DefaultBoardColumnSize = 50
+
+	DefaultPageSizeDefault = 10
+	DefaultPageSizeMax     = 100
+
+	DefaultBulkMaxOperations = 100
+
+	DefaultIDGenerator = "snowflake"
+
+	DefaultAuthJWTTTL = 1 * time.Hour
+
+	DefaultWorkspaceClaim = "workspace_id"
+
+	DefaultIdempotencyKeyTTL     = 24 * time.Hour
+	DefaultIdempotencyPendingTTL = 2 * time.Minute
+
+	DefaultPositionRebalanceInterval  = 10 * time.Minute
+	DefaultPositionRebalanceMaxLength = 32
+
+	DefaultNextWeightPriority = 1.0
+	DefaultNextWeightDue      = 4.0
+	DefaultNextWeightAge      = 0.1
+
+	DefaultReminderInterval       = 30 * time.Second
+	DefaultReminderBatchSize      = 100
+	DefaultNotifierWebhookTimeout = 10 * time.Second
+	DefaultNotifierSMTPPort       = "1025"
+	DefaultNotifierSMTPTimeout    = 10 * time.Second
 )
+
+// DefaultStatusTransitions is the task workflow used when none is configured
+var DefaultStatusTransitions = map[string][]string{
+	"todo":        {"in_progress", "blocked", "done"},
+	"in_progress": {"todo", "blocked", "in_review", "done"},
+	"blocked":     {"todo", "in_progress"},
+	"in_review":   {"in_progress", "done"},
+	"done":        {"todo"},
+}
@@ -17,33 +17,49 @@ import (
 )
 
 var (
-	PostgresDB     *gorm.DB
-	StopTickerChan chan bool
-	sqlRegexp      = regexp.MustCompile(`(\$\d+)|\?`)
+	PostgresDB *gorm.DB
+	// WorkerPostgresDB is the connection of the workers, made as a role
+	// which bypasses the row level security policies
+	WorkerPostgresDB *gorm.DB
+	StopTickerChan   chan bool
+	sqlRegexp        = regexp.MustCompile(`(\$\d+)|\?`)
+	postgresDSN      string
 )
 
-func InitializePostgresConn() {
-	conn, err := openPostgresConn(config.DatabaseDSN())
-	if err != nil {
-		logrus.WithField("databaseDSN", config.DatabaseDSN()).
-			Fatal("failed to connect cockroach database: ", err)
-	}
-
-	PostgresDB = conn
+// InitializePostgresConn connects PostgresDB as the role of dsn
+func InitializePostgresConn(dsn string) {
+	PostgresDB = connectPostgres(dsn)
+	postgresDSN = dsn
 	StopTickerChan = make(chan bool)
 
 	go checkConnection(time.NewTicker(config.PostgresPingInterval()))
+}
+
+// InitializeWorkerPostgresConn connects WorkerPostgresDB as the role of dsn
+func InitializeWorkerPostgresConn(dsn string) {
+	WorkerPostgresDB = connectPostgres(dsn)
+}
+
+func connectPostgres(dsn string) *gorm.DB {
+	conn, err := openPostgresConn(dsn)
+	if err != nil {
+		// the DSN is not logged, it may hold a password
+		logrus.WithField("host", config.PostgresHost()).
+			Fatal("failed to connect cockroach database: ", err)
+	}
 
 	switch config.LogLevel() {
 	case "error":
-		PostgresDB.Logger = PostgresDB.Logger.LogMode(gormLogger.Error)
+		conn.Logger = conn.Logger.LogMode(gormLogger.Error)
 	case "warn":
-		PostgresDB.Logger = PostgresDB.Logger.LogMode(gormLogger.Warn)
+		conn.Logger = conn.Logger.LogMode(gormLogger.Warn)
 	default:
-		PostgresDB.Logger = PostgresDB.Logger.LogMode(gormLogger.Info)
+		conn.Logger = conn.Logger.LogMode(gormLogger.Info)
 
 	}
 	logrus.Info("Connection to Cockroach Server success...")
+
+	return conn
 }
 
 func openPostgresConn(dsn string) (*gorm.DB, error) {
@@ -89,9 +105,9 @@ func reconnectPostgresConn() {
 
 	postgresRetryAttempts := float64(config.PostgresRetryAttempts())
 	for b.Attempt() < postgresRetryAttempts {
-		conn, err := openPostgresConn(config.DatabaseDSN())
+		conn, err := openPostgresConn(postgresDSN)
 		if err != nil {
-			logrus.WithField("databaseDSN", config.DatabaseDSN()).
+			logrus.WithField("host", config.PostgresHost()).
 				Fatal("failed to connect cockroach database: ", err)
 		}
 
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+const (
+	headerAPIKey = "X-API-Key"
+	bearerScheme = "Bearer "
+)
+
+// NewAuthMiddleware authenticates every request by its bearer token or its
+// X-API-Key header and puts the principal into the request context
+func NewAuthMiddleware(au model.AuthUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := authenticate(c, au)
+			if err != nil {
+				// bad credentials are the client's mistake, only the
+				// failures to check them are errors of the service
+				if errors.Is(err, errs.ErrUnauthorized) {
+					logrus.Debug(err)
+				} else {
+					logrus.Error(err)
+				}
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, strings.TrimSpace(bearerScheme))
+				return problem(c, err)
+			}
+
+			ctx := model.ContextWithPrincipal(c.Request().Context(), principal)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+func authenticate(c echo.Context, au model.AuthUsecase) (*model.Principal, error) {
+	ctx := c.Request().Context()
+
+	authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(authorization) > len(bearerScheme) && strings.EqualFold(authorization[:len(bearerScheme)], bearerScheme) {
+		return au.AuthenticateToken(ctx, authorization[len(bearerScheme):])
+	}
+
+	if key := c.Request().Header.Get(headerAPIKey); key != "" {
+		return au.AuthenticateAPIKey(ctx, key)
+	}
+
+	return nil, model.ErrMissingCredentials
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	"todo-app/internal/model"
+)
+
+type fakeAuthUsecase struct {
+	err error
+}
+
+func (u *fakeAuthUsecase) AuthenticateToken(ctx context.Context, token string) (*model.Principal, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	if token != "good-token" {
+		return nil, model.ErrInvalidToken
+	}
+	return &model.Principal{Kind: model.PrincipalToken, Subject: "42", UserID: 42}, nil
+}
+
+func (u *fakeAuthUsecase) AuthenticateAPIKey(ctx context.Context, secret string) (*model.Principal, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	if secret != "tdk_good" {
+		return nil, model.ErrInvalidAPIKey
+	}
+	return &model.Principal{Kind: model.PrincipalAPIKey, Subject: "api_key:1", UserID: 42}, nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.DebugLevel)
+	t.Cleanup(func() { logrus.SetLevel(level) })
+
+	tests := []struct {
+		name          string
+		header        string
+		value         string
+		err           error
+		wantStatus    int
+		wantKind      model.PrincipalKind
+		wantLogLevel  logrus.Level
+		wantChallenge bool
+	}{
+		{name: "bearer token", header: echo.HeaderAuthorization, value: "Bearer good-token", wantStatus: http.StatusOK, wantKind: model.PrincipalToken},
+		{name: "bearer scheme is case insensitive", header: echo.HeaderAuthorization, value: "bearer good-token", wantStatus: http.StatusOK, wantKind: model.PrincipalToken},
+		{name: "api key", header: headerAPIKey, value: "tdk_good", wantStatus: http.StatusOK, wantKind: model.PrincipalAPIKey},
+		{name: "no credentials", wantStatus: http.StatusUnauthorized, wantLogLevel: logrus.DebugLevel, wantChallenge: true},
+		{name: "bad token", header: echo.HeaderAuthorization, value: "Bearer bad-token", wantStatus: http.StatusUnauthorized, wantLogLevel: logrus.DebugLevel, wantChallenge: true},
+		{name: "other scheme", header: echo.HeaderAuthorization, value: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized, wantLogLevel: logrus.DebugLevel, wantChallenge: true},
+		{name: "bad api key", header: headerAPIKey, value: "tdk_bad", wantStatus: http.StatusUnauthorized, wantLogLevel: logrus.DebugLevel, wantChallenge: true},
+		{name: "failure to check the credentials", header: headerAPIKey, value: "tdk_good", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError, wantLogLevel: logrus.ErrorLevel, wantChallenge: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := logrustest.NewGlobal()
+			defer hook.Reset()
+
+			e := echo.New()
+			e.GET("/v1/tasks", func(c echo.Context) error {
+				principal := model.PrincipalFromContext(c.Request().Context())
+				if principal == nil || principal.Kind != tt.wantKind || principal.UserID != 42 {
+					t.Errorf("principal = %+v, want a %s of user 42", principal, tt.wantKind)
+				}
+				return c.NoContent(http.StatusOK)
+			}, NewAuthMiddleware(&fakeAuthUsecase{err: tt.err}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/tasks", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if challenge := rec.Header().Get(echo.HeaderWWWAuthenticate) != ""; challenge != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate set = %t, want %t", challenge, tt.wantChallenge)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+			entry := hook.LastEntry()
+			if entry == nil || entry.Level != tt.wantLogLevel {
+				t.Errorf("logged %+v, want a %s entry", entry, tt.wantLogLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/config"
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+type BoardHTTPHandler struct {
+	TaskUsecase model.TaskUsecase
+}
+
+func NewBoardHTTPHandler(e *echo.Echo, tu model.TaskUsecase, mws ...echo.MiddlewareFunc) {
+	handler := BoardHTTPHandler{TaskUsecase: tu}
+
+	g := e.Group("/v1", mws...)
+	g.GET("/board", handler.FetchBoard)
+	g.POST("/board/move", handler.MoveTask)
+}
+
+func (bh *BoardHTTPHandler) FetchBoard(c echo.Context) error {
+	queryParams := new(model.GetBoardQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if queryParams.Size <= 0 {
+		queryParams.Size = config.DefaultBoardColumnSize
+	}
+
+	board, err := bh.TaskUsecase.FindBoard(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, board)
+}
+
+func (bh *BoardHTTPHandler) MoveTask(c echo.Context) error {
+	input := new(model.MoveBoardTaskInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	task, err := bh.TaskUsecase.Move(c.Request().Context(), input.ToModel())
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/config"
+	"todo-app/internal/model"
+)
+
+// fakeBoardUsecase moves task 1 only, task 9 is the only known neighbour.
+// It backs the position endpoint of the tasks as well.
+type fakeBoardUsecase struct {
+	model.TaskUsecase
+
+	// query is the query of the last board
+	query model.GetBoardQueryParams
+}
+
+func (u *fakeBoardUsecase) FindBoard(ctx context.Context, query model.GetBoardQueryParams) (*model.Board, error) {
+	u.query = query
+	return &model.Board{
+		ProjectID: query.ProjectID,
+		Columns: map[model.TaskStatus]*model.BoardColumn{
+			model.TaskStatusTodo: {Status: model.TaskStatusTodo, Count: 1, Tasks: []*model.Task{{ID: 1}}},
+		},
+	}, nil
+}
+
+func (u *fakeBoardUsecase) Move(ctx context.Context, move model.TaskMove) (*model.Task, error) {
+	if move.ID != 1 {
+		return nil, model.ErrTaskNotFound
+	}
+	for _, neighbourID := range []*int64{move.BeforeID, move.AfterID} {
+		if neighbourID != nil && *neighbourID != 9 {
+			return nil, model.ErrTaskNeighbourNotFound
+		}
+	}
+	if move.Status == model.TaskStatusDone {
+		return nil, model.ErrTaskStatusTransition
+	}
+	return &model.Task{ID: move.ID, Status: move.Status}, nil
+}
+
+func newTestBoardServer(tu model.TaskUsecase) *echo.Echo {
+	e := echo.New()
+	e.Validator = NewRequestValidator(10, 100)
+	NewBoardHTTPHandler(e, tu)
+	return e
+}
+
+func TestBoardHTTPHandlerFetchBoard(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        string
+		wantStatus    int
+		wantSize      int64
+		wantProjectID int64
+	}{
+		{name: "default size", target: "/v1/board", wantStatus: http.StatusOK, wantSize: config.DefaultBoardColumnSize},
+		{name: "size and project", target: "/v1/board?size=5&project_id=7", wantStatus: http.StatusOK, wantSize: 5, wantProjectID: 7},
+		{name: "negative size", target: "/v1/board?size=-1", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &fakeBoardUsecase{}
+			rec := httptest.NewRecorder()
+			newTestBoardServer(tu).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tu.query.Size != tt.wantSize {
+				t.Errorf("size = %d, want %d", tu.query.Size, tt.wantSize)
+			}
+			projectID := int64(0)
+			if tu.query.ProjectID != nil {
+				projectID = *tu.query.ProjectID
+			}
+			if projectID != tt.wantProjectID {
+				t.Errorf("project ID = %d, want %d", projectID, tt.wantProjectID)
+			}
+		})
+	}
+}
+
+func TestBoardHTTPHandlerMoveTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "status", body: `{"task_id":1,"status":"in_progress"}`, wantStatus: http.StatusOK},
+		{name: "position", body: `{"task_id":1,"after_id":9}`, wantStatus: http.StatusOK},
+		{name: "status and position", body: `{"task_id":1,"status":"in_progress","before_id":9}`, wantStatus: http.StatusOK},
+		{name: "without task", body: `{"status":"in_progress"}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "without destination", body: `{"task_id":1}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "missing task", body: `{"task_id":2,"status":"in_progress"}`, wantStatus: http.StatusNotFound, wantCode: "task_not_found"},
+		{name: "missing neighbour", body: `{"task_id":1,"before_id":3}`, wantStatus: http.StatusNotFound, wantCode: "task_neighbour_not_found"},
+		{name: "forbidden transition", body: `{"task_id":1,"status":"done"}`, wantStatus: http.StatusConflict, wantCode: "task_status_transition"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/board/move", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			newTestBoardServer(&fakeBoardUsecase{}).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			response := struct {
+				Code string `json:"code"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+		})
+	}
+}
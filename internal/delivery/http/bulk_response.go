@@ -0,0 +1,73 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"todo-app/internal/model"
+)
+
+// BulkTaskResult is the outcome of a single operation of a batch, a failed
+// operation carries its problem instead of the task
+type BulkTaskResult struct {
+	Index   int                     `json:"index"`
+	Op      model.TaskOperationKind `json:"op"`
+	ID      int64                   `json:"id,omitempty"`
+	Status  int                     `json:"status"`
+	Task    *model.Task             `json:"task,omitempty"`
+	Problem *Problem                `json:"problem,omitempty"`
+}
+
+type BulkTaskResponse struct {
+	Mode      string            `json:"mode"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []*BulkTaskResult `json:"results"`
+}
+
+// bulkProblem is the problem of a batch which failed as a whole, extended
+// with the results of its operations
+type bulkProblem struct {
+	Problem
+	BulkTaskResponse
+}
+
+// newBulkTaskResponse reports the operations of the batch posted to path.
+// The problem of a failed operation points at it in the request body.
+func newBulkTaskResponse(path, mode string, operations []*model.TaskOperation) BulkTaskResponse {
+	response := BulkTaskResponse{Mode: mode, Results: []*BulkTaskResult{}}
+	for index, operation := range operations {
+		result := &BulkTaskResult{
+			Index: index,
+			Op:    operation.Kind,
+			ID:    operation.ID,
+			Task:  operation.Task,
+		}
+
+		switch {
+		case operation.Err != nil:
+			p := newProblem(fmt.Sprintf("%s#/operations/%d", path, index), operation.Err)
+			result.Status = p.Status
+			result.Problem = &p
+			result.Task = nil
+			// operations rolled back because of another one did not fail
+			// themselves
+			if p.Status != http.StatusFailedDependency {
+				response.Failed++
+			}
+		case operation.Kind == model.TaskOperationCreate:
+			result.Status = http.StatusCreated
+			response.Succeeded++
+		case operation.Kind == model.TaskOperationDelete:
+			result.Status = http.StatusNoContent
+			result.Task = nil
+			response.Succeeded++
+		default:
+			result.Status = http.StatusOK
+			response.Succeeded++
+		}
+
+		response.Results = append(response.Results, result)
+	}
+	return response
+}
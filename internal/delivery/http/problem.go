@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/utils"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code is the stable code of
+// the domain error, clients should branch on it rather than on Detail.
+// Errors lists the offending fields of a validation error.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Errors   []errs.FieldError `json:"errors,omitempty"`
+}
+
+// problem renders err as problem+json, the detail of internal errors is not
+// exposed
+func problem(c echo.Context, err error) error {
+	p := newProblem(c.Request().URL.Path, err)
+	c.Response().Header().Set(echo.HeaderContentType, problemContentType)
+	return c.JSON(p.Status, p)
+}
+
+// newProblem describes err, which occurred at instance
+func newProblem(instance string, err error) Problem {
+	domainErr := errs.From(err)
+	status := utils.ParseHTTPErrorStatusCode(domainErr)
+
+	detail := domainErr.Error()
+	if domainErr.Kind == errs.KindInternal {
+		detail = errs.ErrInternal.Message
+	}
+
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+		Code:     domainErr.Code,
+		Errors:   domainErr.Fields,
+	}
+}
+
+// invalidParam is the error of a path or query param which cannot be parsed
+func invalidParam(name string) error {
+	return errs.New(errs.KindValidation, "invalid_param", name+" param is invalid")
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+func TestProblem(t *testing.T) {
+	titleRequired := errs.FieldError{Field: "title", Rule: "required", Message: "title is required"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+		wantFields []errs.FieldError
+	}{
+		{
+			name:       "not found",
+			err:        model.ErrTaskNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "task_not_found",
+			wantDetail: "task not found",
+		},
+		{
+			name:       "wrapped",
+			err:        fmt.Errorf("moving: %w", model.ErrTaskStatusTransition),
+			wantStatus: http.StatusConflict,
+			wantCode:   "task_status_transition",
+			wantDetail: "task status transition is not allowed",
+		},
+		{
+			name:       "precondition failed",
+			err:        model.ErrTaskVersionMismatch,
+			wantStatus: http.StatusPreconditionFailed,
+			wantCode:   "task_version_mismatch",
+			wantDetail: model.ErrTaskVersionMismatch.Message,
+		},
+		{
+			name:       "validation with fields",
+			err:        errs.ErrValidation.WithFields(titleRequired),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation",
+			wantDetail: "request is invalid: title is required",
+			wantFields: []errs.FieldError{titleRequired},
+		},
+		{
+			name:       "internal detail is hidden",
+			err:        errors.New("pq: password authentication failed"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal",
+			wantDetail: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.GET("/v1/tasks/1", func(c echo.Context) error {
+				return problem(c, tt.err)
+			})
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/1?x=1", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if contentType := rec.Header().Get(echo.HeaderContentType); contentType != problemContentType {
+				t.Errorf("content type = %q, want %q", contentType, problemContentType)
+			}
+
+			got := Problem{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			want := Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(tt.wantStatus),
+				Status:   tt.wantStatus,
+				Detail:   tt.wantDetail,
+				Instance: "/v1/tasks/1",
+				Code:     tt.wantCode,
+				Errors:   tt.wantFields,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("problem = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+type ProjectHTTPHandler struct {
+	ProjectUsecase model.ProjectUsecase
+	TaskUsecase    model.TaskUsecase
+}
+
+func NewProjectHTTPHandler(e *echo.Echo, pu model.ProjectUsecase, tu model.TaskUsecase, mws ...echo.MiddlewareFunc) {
+	handler := ProjectHTTPHandler{ProjectUsecase: pu, TaskUsecase: tu}
+
+	g := e.Group("/v1", mws...)
+	g.POST("/projects", handler.CreateProject)
+	g.GET("/projects", handler.FetchProjects)
+	g.GET("/projects/:ID", handler.FetchProjectByID)
+	g.PUT("/projects/:ID", handler.UpdateProject)
+	g.DELETE("/projects/:ID", handler.DeleteProjectByID)
+	g.GET("/projects/:ID/tasks", handler.FetchProjectTasks)
+}
+
+func (ph *ProjectHTTPHandler) CreateProject(c echo.Context) error {
+	input := new(model.CreateProjectInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	project, err := ph.ProjectUsecase.Create(c.Request().Context(), input.ToModel())
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, project)
+}
+
+func (ph *ProjectHTTPHandler) DeleteProjectByID(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	err = ph.ProjectUsecase.DeleteByID(c.Request().Context(), ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ph *ProjectHTTPHandler) FetchProjects(c echo.Context) error {
+	queryParams := new(model.GetProjectsQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	projects, count, err := ph.ProjectUsecase.FindAll(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		projects,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (ph *ProjectHTTPHandler) FetchProjectByID(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	project, err := ph.ProjectUsecase.FindByID(c.Request().Context(), ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, project)
+}
+
+func (ph *ProjectHTTPHandler) UpdateProject(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	input := new(model.UpdateProjectInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	project, err := ph.ProjectUsecase.Update(c.Request().Context(), input.ToModel(ID))
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, project)
+}
+
+func (ph *ProjectHTTPHandler) FetchProjectTasks(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	queryParams := new(model.GetTasksQueryParams)
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if _, err := ph.ProjectUsecase.FindByID(c.Request().Context(), ID); err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	queryParams.ProjectID = &ID
+	tasks, count, err := ph.TaskUsecase.FindAll(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		tasks,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
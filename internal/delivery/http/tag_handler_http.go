@@ -0,0 +1,134 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+type TagHTTPHandler struct {
+	TagUsecase model.TagUsecase
+}
+
+func NewTagHTTPHandler(e *echo.Echo, tu model.TagUsecase, mws ...echo.MiddlewareFunc) {
+	handler := TagHTTPHandler{TagUsecase: tu}
+
+	g := e.Group("/v1", mws...)
+	g.POST("/tags", handler.CreateTag)
+	g.GET("/tags", handler.FetchTags)
+	g.GET("/tags/:ID", handler.FetchTagByID)
+	g.PUT("/tags/:ID", handler.UpdateTag)
+	g.DELETE("/tags/:ID", handler.DeleteTagByID)
+}
+
+func (th *TagHTTPHandler) CreateTag(c echo.Context) error {
+	input := new(model.CreateTagInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	tag, err := th.TagUsecase.Create(c.Request().Context(), input.ToModel())
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, tag)
+}
+
+func (th *TagHTTPHandler) DeleteTagByID(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	err = th.TagUsecase.DeleteByID(c.Request().Context(), ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (th *TagHTTPHandler) FetchTags(c echo.Context) error {
+	queryParams := new(model.GetTagsQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	tags, count, err := th.TagUsecase.FindAll(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		tags,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (th *TagHTTPHandler) FetchTagByID(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	tag, err := th.TagUsecase.FindByID(c.Request().Context(), ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tag)
+}
+
+func (th *TagHTTPHandler) UpdateTag(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	input := new(model.UpdateTagInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	tag, err := th.TagUsecase.Update(c.Request().Context(), input.ToModel(ID))
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tag)
+}
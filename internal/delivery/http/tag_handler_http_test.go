@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/model"
+)
+
+// fakeTagUsecase holds the tags urgent and work, work being the only name
+// taken when renaming
+type fakeTagUsecase struct {
+	model.TagUsecase
+
+	// query is the query of the last listing
+	query model.GetTagsQueryParams
+}
+
+func (u *fakeTagUsecase) Create(ctx context.Context, input *model.Tag) (*model.Tag, error) {
+	if input.Name == "work" {
+		return nil, model.ErrTagNameTaken
+	}
+	return input, nil
+}
+
+func (u *fakeTagUsecase) FindByID(ctx context.Context, ID int64) (*model.Tag, error) {
+	if ID != 1 {
+		return nil, model.ErrTagNotFound
+	}
+	return &model.Tag{ID: 1, Name: "urgent"}, nil
+}
+
+func (u *fakeTagUsecase) FindAll(ctx context.Context, query model.GetTagsQueryParams) ([]*model.Tag, int64, error) {
+	u.query = query
+	return []*model.Tag{{ID: 1, Name: "urgent"}, {ID: 2, Name: "work"}}, 2, nil
+}
+
+func (u *fakeTagUsecase) Update(ctx context.Context, input *model.Tag) (*model.Tag, error) {
+	if input.ID != 1 {
+		return nil, model.ErrTagNotFound
+	}
+	if input.Name == "work" {
+		return nil, model.ErrTagNameTaken
+	}
+	return input, nil
+}
+
+func (u *fakeTagUsecase) DeleteByID(ctx context.Context, ID int64) error {
+	if ID != 1 {
+		return model.ErrTagNotFound
+	}
+	return nil
+}
+
+func TestTagHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantName   string
+	}{
+		{name: "create", method: http.MethodPost, target: "/v1/tags", body: `{"name":" Errands "}`, wantStatus: http.StatusCreated, wantName: "errands"},
+		{name: "create blank", method: http.MethodPost, target: "/v1/tags", body: `{"name":"  "}`, wantStatus: http.StatusBadRequest, wantCode: "tag_name_required"},
+		{name: "create taken", method: http.MethodPost, target: "/v1/tags", body: `{"name":"Work"}`, wantStatus: http.StatusConflict, wantCode: "tag_name_taken"},
+		{name: "get", method: http.MethodGet, target: "/v1/tags/1", wantStatus: http.StatusOK, wantName: "urgent"},
+		{name: "get missing", method: http.MethodGet, target: "/v1/tags/2", wantStatus: http.StatusNotFound, wantCode: "tag_not_found"},
+		{name: "get invalid ID", method: http.MethodGet, target: "/v1/tags/x", wantStatus: http.StatusBadRequest, wantCode: "invalid_param"},
+		{name: "rename", method: http.MethodPut, target: "/v1/tags/1", body: `{"name":"Later"}`, wantStatus: http.StatusOK, wantName: "later"},
+		{name: "rename to a taken name", method: http.MethodPut, target: "/v1/tags/1", body: `{"name":"work"}`, wantStatus: http.StatusConflict, wantCode: "tag_name_taken"},
+		{name: "delete", method: http.MethodDelete, target: "/v1/tags/1", wantStatus: http.StatusNoContent},
+		{name: "delete missing", method: http.MethodDelete, target: "/v1/tags/2", wantStatus: http.StatusNotFound, wantCode: "tag_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = NewRequestValidator(10, 100)
+			NewTagHTTPHandler(e, &fakeTagUsecase{})
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code == http.StatusNoContent {
+				return
+			}
+
+			response := struct {
+				Name string `json:"name"`
+				Code string `json:"code"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+			if response.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", response.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestTagHTTPHandlerFetchTags(t *testing.T) {
+	tu := &fakeTagUsecase{}
+	e := echo.New()
+	e.Validator = NewRequestValidator(10, 100)
+	NewTagHTTPHandler(e, tu)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tags", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if tu.query.Page != 1 || tu.query.Size != 10 {
+		t.Errorf("page = %d, size = %d, want the first page of 10", tu.query.Page, tu.query.Size)
+	}
+
+	response := model.PaginationResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.TotalPages != 1 {
+		t.Errorf("total pages = %d, want 1", response.TotalPages)
+	}
+}
@@ -1,58 +1,136 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 
+	"todo-app/internal/config"
+	"todo-app/internal/errs"
 	"todo-app/internal/model"
 	"todo-app/internal/utils"
 )
 
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+
+	headerIdempotencyKey       = "Idempotency-Key"
+	headerIdempotentReplayed   = "Idempotent-Replayed"
+	idempotencyKeyMaxLength    = 255
+	idempotencyScopeTaskCreate = "task:create"
+)
+
+var errInvalidIdempotencyKey = errs.New(errs.KindValidation, "invalid_idempotency_key", "Idempotency-Key header is too long")
+
 type TaskHTTPHandler struct {
-	TaskUsecase model.TaskUsecase
+	TaskUsecase        model.TaskUsecase
+	IdempotencyUsecase model.IdempotencyUsecase
 }
 
-func NewTaskHTTPHandler(e *echo.Echo, tu model.TaskUsecase) {
-	handler := TaskHTTPHandler{TaskUsecase: tu}
+func NewTaskHTTPHandler(e *echo.Echo, tu model.TaskUsecase, iu model.IdempotencyUsecase, mws ...echo.MiddlewareFunc) {
+	handler := TaskHTTPHandler{TaskUsecase: tu, IdempotencyUsecase: iu}
 
-	g := e.Group("/v1")
+	g := e.Group("/v1", mws...)
 	g.POST("/tasks", handler.CreateTask)
+	g.POST("/tasks/bulk", handler.BulkTasks)
 	g.GET("/tasks", handler.FetchTasks)
 	g.GET("/tasks/:ID", handler.FetchTaskByID)
-	g.PUT("/tasks", handler.UpdateTask)
+	g.PUT("/tasks/:ID", handler.UpdateTask)
+	g.PATCH("/tasks/:ID", handler.PatchTask)
 	g.DELETE("/tasks/:ID", handler.DeleteTaskByID)
+	g.GET("/tasks/trash", handler.FetchTrashedTasks)
+	g.POST("/tasks/:ID/restore", handler.RestoreTaskByID)
+	g.DELETE("/tasks/:ID/purge", handler.PurgeTaskByID)
+	g.GET("/tasks/overdue", handler.FetchOverdueTasks)
+	g.GET("/tasks/due", handler.FetchDueTasks)
+	g.GET("/tasks/next", handler.FetchNextTasks)
+	g.GET("/tasks/:ID/series", handler.FetchTaskSeries)
+	g.PUT("/tasks/:ID/series", handler.UpdateTaskSeries)
+	g.DELETE("/tasks/:ID/series", handler.StopTaskSeries)
+	g.GET("/tasks/:ID/subtasks", handler.FetchSubtasks)
+	g.POST("/tasks/:ID/subtasks", handler.CreateSubtask)
+	g.POST("/tasks/:ID/complete", handler.CompleteTaskByID)
+	g.PATCH("/tasks/:ID/position", handler.UpdateTaskPosition)
+	g.GET("/tasks/:ID/history", handler.FetchTaskHistory)
 }
 
 func (th *TaskHTTPHandler) CreateTask(c echo.Context) error {
 	input := new(model.CreateTaskInput)
 	if err := c.Bind(input); err != nil {
 		logrus.Error(err)
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	key := c.Request().Header.Get(headerIdempotencyKey)
+	if key == "" {
+		task, err := th.TaskUsecase.Create(c.Request().Context(), input.ToModel())
+		if err != nil {
+			logrus.Error(err)
+			return problem(c, err)
+		}
+
+		return c.JSON(http.StatusCreated, task)
+	}
+
+	if len(key) > idempotencyKeyMaxLength {
+		return problem(c, errInvalidIdempotencyKey)
+	}
+
+	requestHash, err := model.HashRequest(input)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
 	}
 
-	task, err := th.TaskUsecase.Create(c.Request().Context(), input.ToModel())
+	// the ID is minted inside so that a replay does not mint another one
+	response, replayed, err := th.IdempotencyUsecase.Do(c.Request().Context(), idempotencyScopeTaskCreate, key, requestHash,
+		func(ctx context.Context) (int, interface{}, error) {
+			task, err := th.TaskUsecase.Create(ctx, input.ToModel())
+			return http.StatusCreated, task, err
+		})
 	if err != nil {
 		logrus.Error(err)
-		return c.JSON(utils.ParseHTTPErrorStatusCode(err), err.Error())
+		return problem(c, err)
 	}
 
-	return c.JSON(http.StatusCreated, task)
+	if replayed {
+		c.Response().Header().Set(headerIdempotentReplayed, "true")
+	}
+
+	return c.JSONBlob(response.Status, response.Body)
 }
 
 func (th *TaskHTTPHandler) DeleteTaskByID(c echo.Context) error {
 	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
 	if err != nil {
 		logrus.Error(err)
-		return c.JSON(http.StatusBadRequest, "ID param is invalid")
+		return problem(c, invalidParam("ID"))
+	}
+
+	version, err := th.ifMatchVersion(c, ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
 	}
 
-	err = th.TaskUsecase.DeleteByID(c.Request().Context(), ID)
+	err = th.TaskUsecase.DeleteByID(c.Request().Context(), ID, version)
 	if err != nil {
 		logrus.Error(err)
-		return c.JSON(utils.ParseHTTPErrorStatusCode(err), err.Error())
+		return problem(c, err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -63,13 +141,24 @@ func (th *TaskHTTPHandler) FetchTasks(c echo.Context) error {
 
 	if err := c.Bind(queryParams); err != nil {
 		logrus.Error(err)
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	// the presence of the cursor param, even empty for the first page,
+	// switches the listing to keyset pagination
+	if c.QueryParams().Has("cursor") {
+		return th.fetchTasksByCursor(c, *queryParams)
 	}
 
 	tasks, count, err := th.TaskUsecase.FindAll(c.Request().Context(), *queryParams)
 	if err != nil {
 		logrus.Error(err)
-		return c.JSON(utils.ParseHTTPErrorStatusCode(err), err.Error())
+		return problem(c, err)
 	}
 
 	return c.JSON(http.StatusOK, model.NewPaginationResponse(
@@ -80,34 +169,531 @@ func (th *TaskHTTPHandler) FetchTasks(c echo.Context) error {
 	))
 }
 
+func (th *TaskHTTPHandler) fetchTasksByCursor(c echo.Context, queryParams model.GetTasksQueryParams) error {
+	tasks, nextCursor, prevCursor, err := th.TaskUsecase.FindAllByCursor(c.Request().Context(), queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewCursorPaginationResponse(
+		tasks,
+		queryParams.Size,
+		nextCursor,
+		prevCursor,
+	))
+}
+
 func (th *TaskHTTPHandler) FetchTaskByID(c echo.Context) error {
 	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
 	if err != nil {
 		logrus.Error(err)
-		return c.JSON(http.StatusBadRequest, "ID param is invalid")
+		return problem(c, invalidParam("ID"))
 	}
 
 	task, err := th.TaskUsecase.FindByID(c.Request().Context(), ID)
 	if err != nil {
 		logrus.Error(err)
-		return c.JSON(utils.ParseHTTPErrorStatusCode(err), err.Error())
+		return problem(c, err)
+	}
+
+	c.Response().Header().Set(headerETag, task.ETag())
+	if ifNoneMatch := c.Request().Header.Get(headerIfNoneMatch); ifNoneMatch != "" && utils.MatchETag(ifNoneMatch, task.ETag(), true) {
+		return c.NoContent(http.StatusNotModified)
 	}
 
 	return c.JSON(http.StatusOK, task)
 }
 
 func (th *TaskHTTPHandler) UpdateTask(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
 	input := new(model.UpdateTaskInput)
 	if err := c.Bind(input); err != nil {
 		logrus.Error(err)
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	version, err := th.ifMatchVersion(c, ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	task := input.ToModel(ID)
+	task.Version = version
+	task, err = th.TaskUsecase.Update(c.Request().Context(), task)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	c.Response().Header().Set(headerETag, task.ETag())
+	return c.JSON(http.StatusOK, task)
+}
+
+// ifMatchVersion returns the version of the task satisfying the If-Match
+// header, or 0 when there is no such header
+func (th *TaskHTTPHandler) ifMatchVersion(c echo.Context, ID int64) (int64, error) {
+	ifMatch := c.Request().Header.Get(headerIfMatch)
+	if ifMatch == "" {
+		return 0, nil
+	}
+
+	task, err := th.TaskUsecase.FindByID(c.Request().Context(), ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !utils.MatchETag(ifMatch, task.ETag(), false) {
+		return 0, model.ErrTaskVersionMismatch
+	}
+
+	return task.Version, nil
+}
+
+// PatchTask applies a JSON Merge Patch, or a JSON Patch when sent as
+// application/json-patch+json, to the task
+func (th *TaskHTTPHandler) PatchTask(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	contentType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch contentType {
+	case model.MergePatchContentType, model.JSONPatchContentType, echo.MIMEApplicationJSON:
+	default:
+		return problem(c, errs.ErrUnsupportedMediaType.Wrap(errors.New("content type must be application/merge-patch+json or application/json-patch+json")))
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	version, err := th.ifMatchVersion(c, ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	task, err := th.TaskUsecase.Patch(c.Request().Context(), ID, model.TaskPatch{
+		ContentType: contentType,
+		Body:        body,
+		Version:     version,
+	})
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
 	}
 
-	task, err := th.TaskUsecase.Update(c.Request().Context(), input.ToModel())
+	c.Response().Header().Set(headerETag, task.ETag())
+	return c.JSON(http.StatusOK, task)
+}
+
+func (th *TaskHTTPHandler) FetchTrashedTasks(c echo.Context) error {
+	queryParams := new(model.GetTrashedTasksQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	tasks, count, err := th.TaskUsecase.FindAllTrashed(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		tasks,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (th *TaskHTTPHandler) RestoreTaskByID(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	task, err := th.TaskUsecase.RestoreByID(c.Request().Context(), ID)
 	if err != nil {
 		logrus.Error(err)
-		return c.JSON(utils.ParseHTTPErrorStatusCode(err), err.Error())
+		return problem(c, err)
 	}
 
 	return c.JSON(http.StatusOK, task)
 }
+
+func (th *TaskHTTPHandler) PurgeTaskByID(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	err = th.TaskUsecase.PurgeByID(c.Request().Context(), ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (th *TaskHTTPHandler) FetchOverdueTasks(c echo.Context) error {
+	queryParams := new(model.GetTasksQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	tasks, count, err := th.TaskUsecase.FindOverdue(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		tasks,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+// FetchNextTasks ranks the open tasks by priority, due date and age, see
+// model.TaskScoreWeights for the scoring function
+func (th *TaskHTTPHandler) FetchNextTasks(c echo.Context) error {
+	queryParams := new(model.GetTasksQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	tasks, count, err := th.TaskUsecase.FindNext(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		tasks,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (th *TaskHTTPHandler) FetchDueTasks(c echo.Context) error {
+	queryParams := new(model.GetDueTasksQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	within := config.DefaultDueWithin
+	if queryParams.Within != "" {
+		var err error
+		within, err = time.ParseDuration(queryParams.Within)
+		if err != nil || within <= 0 {
+			logrus.Error(err)
+			return problem(c, invalidParam("within"))
+		}
+	}
+
+	tasks, count, err := th.TaskUsecase.FindDueWithin(
+		c.Request().Context(),
+		within,
+		model.GetTasksQueryParams{Pagination: queryParams.Pagination},
+	)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		tasks,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (th *TaskHTTPHandler) FetchTaskSeries(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	series, err := th.TaskUsecase.FindSeriesByTaskID(c.Request().Context(), ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, series)
+}
+
+func (th *TaskHTTPHandler) UpdateTaskSeries(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	input := new(model.UpdateTaskSeriesInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	series, err := th.TaskUsecase.UpdateSeries(c.Request().Context(), ID, input.Rule)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, series)
+}
+
+func (th *TaskHTTPHandler) StopTaskSeries(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	series, err := th.TaskUsecase.StopSeries(c.Request().Context(), ID)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, series)
+}
+
+func (th *TaskHTTPHandler) FetchSubtasks(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	queryParams := new(model.GetTasksQueryParams)
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	tasks, count, err := th.TaskUsecase.FindSubtasks(c.Request().Context(), ID, *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		tasks,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (th *TaskHTTPHandler) FetchTaskHistory(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	queryParams := new(model.GetTaskHistoryQueryParams)
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	events, count, err := th.TaskUsecase.FindHistory(c.Request().Context(), ID, *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		events,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (th *TaskHTTPHandler) CreateSubtask(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	input := new(model.CreateTaskInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	task, err := th.TaskUsecase.CreateSubtask(c.Request().Context(), ID, input.ToModel())
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, task)
+}
+
+// CompleteTaskByID completes the task, ?cascade=true completes its subtasks
+// as well
+func (th *TaskHTTPHandler) CompleteTaskByID(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	cascade := false
+	if c.QueryParam("cascade") != "" {
+		cascade, err = strconv.ParseBool(c.QueryParam("cascade"))
+		if err != nil {
+			logrus.Error(err)
+			return problem(c, invalidParam("cascade"))
+		}
+	}
+
+	task, err := th.TaskUsecase.CompleteByID(c.Request().Context(), ID, cascade)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, task)
+}
+
+func (th *TaskHTTPHandler) UpdateTaskPosition(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	input := new(model.UpdateTaskPositionInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	task, err := th.TaskUsecase.Move(c.Request().Context(), input.ToModel(ID))
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, task)
+}
+
+// BulkTasks applies create, update, delete and complete operations in a
+// single transaction, either all or nothing or reporting each operation
+func (th *TaskHTTPHandler) BulkTasks(c echo.Context) error {
+	input := new(model.BulkTaskInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := input.Validate(config.BulkMaxOperations()); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	mode := model.BulkModePartial
+	if input.Atomic() {
+		mode = model.BulkModeAtomic
+	}
+
+	path := c.Request().URL.Path
+	operations := input.ToModel()
+	err := th.TaskUsecase.Bulk(c.Request().Context(), operations, input.Atomic())
+	if err != nil {
+		logrus.Error(err)
+		p := newProblem(path, err)
+		c.Response().Header().Set(echo.HeaderContentType, problemContentType)
+		return c.JSON(p.Status, bulkProblem{
+			Problem:          p,
+			BulkTaskResponse: newBulkTaskResponse(path, mode, operations),
+		})
+	}
+
+	return c.JSON(http.StatusOK, newBulkTaskResponse(path, mode, operations))
+}
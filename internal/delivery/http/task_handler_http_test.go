@@ -0,0 +1,626 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/config"
+	"todo-app/internal/model"
+)
+
+// fakeTaskUsecase serves a single task, methods the tests do not reach
+// panic through the nil embedded interface
+type fakeTaskUsecase struct {
+	model.TaskUsecase
+
+	task *model.Task
+	// version is the version the last write was guarded with
+	version int64
+	bulk    func(operations []*model.TaskOperation, atomic bool) error
+	// parentID and cascade are the arguments of the last subtask call
+	parentID int64
+	cascade  bool
+}
+
+func (u *fakeTaskUsecase) FindByID(ctx context.Context, ID int64) (*model.Task, error) {
+	if ID != u.task.ID {
+		return nil, model.ErrTaskNotFound
+	}
+	task := *u.task
+	return &task, nil
+}
+
+func (u *fakeTaskUsecase) Update(ctx context.Context, input *model.Task) (*model.Task, error) {
+	u.version = input.Version
+	task := *input
+	task.Version = u.task.Version + 1
+	return &task, nil
+}
+
+func (u *fakeTaskUsecase) DeleteByID(ctx context.Context, ID int64, version int64) error {
+	u.version = version
+	return nil
+}
+
+func (u *fakeTaskUsecase) Patch(ctx context.Context, ID int64, patch model.TaskPatch) (*model.Task, error) {
+	u.version = patch.Version
+	input, err := model.NewUpdateTaskInput(u.task).Apply(patch)
+	if err != nil {
+		return nil, err
+	}
+	task := input.ToModel(ID)
+	task.Version = u.task.Version + 1
+	return task, nil
+}
+
+func (u *fakeTaskUsecase) FindHistory(ctx context.Context, ID int64, query model.GetTaskHistoryQueryParams) ([]*model.TaskEvent, int64, error) {
+	if ID != u.task.ID {
+		return nil, 0, model.ErrTaskNotFound
+	}
+	return []*model.TaskEvent{{
+		ID:      2,
+		TaskID:  ID,
+		Kind:    model.TaskEventCreated,
+		Changes: model.TaskChanges{"title": {To: "write report"}},
+	}}, 1, nil
+}
+
+func (u *fakeTaskUsecase) Bulk(ctx context.Context, operations []*model.TaskOperation, atomic bool) error {
+	return u.bulk(operations, atomic)
+}
+
+func (u *fakeTaskUsecase) CreateSubtask(ctx context.Context, parentID int64, input *model.Task) (*model.Task, error) {
+	if parentID != u.task.ID {
+		return nil, model.ErrTaskNotFound
+	}
+	u.parentID = parentID
+	task := *input
+	task.ID = 2
+	task.ParentID = &parentID
+	return &task, nil
+}
+
+func (u *fakeTaskUsecase) FindSubtasks(ctx context.Context, parentID int64, query model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	u.parentID = parentID
+	return []*model.Task{{ID: 2, ParentID: &parentID}}, 1, nil
+}
+
+func (u *fakeTaskUsecase) CompleteByID(ctx context.Context, ID int64, cascade bool) (*model.Task, error) {
+	u.cascade = cascade
+	task := *u.task
+	task.Status = model.TaskStatusDone
+	return &task, nil
+}
+
+func (u *fakeTaskUsecase) FindAll(ctx context.Context, query model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	return []*model.Task{u.task}, 1, nil
+}
+
+func newTestTaskServer(tu model.TaskUsecase) *echo.Echo {
+	e := echo.New()
+	e.Validator = NewRequestValidator(10, 100)
+	NewTaskHTTPHandler(e, tu, nil)
+	return e
+}
+
+func TestTaskHTTPHandlerConditionalRequests(t *testing.T) {
+	task := &model.Task{ID: 1, Title: "write report", Status: model.TaskStatusTodo, Version: 3}
+	current := task.ETag()
+
+	stale := *task
+	stale.Progress = model.NewTaskProgress(1, 0)
+	staleETag := stale.ETag()
+
+	tests := []struct {
+		name        string
+		method      string
+		header      string
+		value       string
+		body        string
+		wantStatus  int
+		wantVersion int64
+	}{
+		{name: "get without condition", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "get not modified", method: http.MethodGet, header: headerIfNoneMatch, value: current, wantStatus: http.StatusNotModified},
+		{name: "get not modified by weak tag", method: http.MethodGet, header: headerIfNoneMatch, value: "W/" + current, wantStatus: http.StatusNotModified},
+		{name: "get modified", method: http.MethodGet, header: headerIfNoneMatch, value: staleETag, wantStatus: http.StatusOK},
+		{name: "put without condition", method: http.MethodPut, body: `{"title":"edited"}`, wantStatus: http.StatusOK},
+		{name: "put matching", method: http.MethodPut, header: headerIfMatch, value: current, body: `{"title":"edited"}`, wantStatus: http.StatusOK, wantVersion: 3},
+		{name: "put on a changed representation", method: http.MethodPut, header: headerIfMatch, value: staleETag, body: `{"title":"edited"}`, wantStatus: http.StatusPreconditionFailed},
+		{name: "put with a weak tag", method: http.MethodPut, header: headerIfMatch, value: "W/" + current, body: `{"title":"edited"}`, wantStatus: http.StatusPreconditionFailed},
+		{name: "delete matching", method: http.MethodDelete, header: headerIfMatch, value: current, wantStatus: http.StatusNoContent, wantVersion: 3},
+		{name: "delete mismatching", method: http.MethodDelete, header: headerIfMatch, value: staleETag, wantStatus: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &fakeTaskUsecase{task: task}
+			e := newTestTaskServer(tu)
+
+			req := httptest.NewRequest(tt.method, "/v1/tasks/1", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("%s status = %d, want %d: %s", tt.method, rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tu.version != tt.wantVersion {
+				t.Errorf("write guarded with version %d, want %d", tu.version, tt.wantVersion)
+			}
+			if tt.method == http.MethodGet && rec.Header().Get(headerETag) != current {
+				t.Errorf("ETag = %q, want %q", rec.Header().Get(headerETag), current)
+			}
+		})
+	}
+}
+
+func TestTaskHTTPHandlerBulkTasks(t *testing.T) {
+	const body = `{"mode":"%s","operations":[
+		{"op":"complete","id":1},
+		{"op":"complete","id":2},
+		{"op":"delete","id":3}
+	]}`
+
+	// the second operation fails, an atomic batch rolls the others back
+	bulk := func(operations []*model.TaskOperation, atomic bool) error {
+		operations[1].Err = model.ErrTaskNotFound
+		if atomic {
+			operations[0].Err = model.ErrOperationNotApplied
+			operations[2].Err = model.ErrOperationNotApplied
+			return model.ErrTaskNotFound
+		}
+		operations[0].Task = &model.Task{ID: 1}
+		operations[2].Err = errors.New("connection reset")
+		return nil
+	}
+
+	tests := []struct {
+		name            string
+		mode            string
+		wantStatus      int
+		wantContentType string
+		wantCode        string
+		wantResults     []int
+		wantCodes       []string
+	}{
+		{
+			name:            "atomic",
+			mode:            model.BulkModeAtomic,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: problemContentType,
+			wantCode:        "task_not_found",
+			wantResults:     []int{http.StatusFailedDependency, http.StatusNotFound, http.StatusFailedDependency},
+			wantCodes:       []string{"operation_not_applied", "task_not_found", "operation_not_applied"},
+		},
+		{
+			name:            "partial",
+			mode:            model.BulkModePartial,
+			wantStatus:      http.StatusOK,
+			wantContentType: echo.MIMEApplicationJSON,
+			wantResults:     []int{http.StatusOK, http.StatusNotFound, http.StatusInternalServerError},
+			wantCodes:       []string{"", "task_not_found", "internal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestTaskServer(&fakeTaskUsecase{bulk: bulk})
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/tasks/bulk", strings.NewReader(fmt.Sprintf(body, tt.mode)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if contentType := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(contentType, tt.wantContentType) {
+				t.Errorf("content type = %q, want %q", contentType, tt.wantContentType)
+			}
+
+			response := bulkProblem{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+			if len(response.Results) != len(tt.wantResults) {
+				t.Fatalf("got %d results, want %d", len(response.Results), len(tt.wantResults))
+			}
+
+			for i, result := range response.Results {
+				if result.Status != tt.wantResults[i] {
+					t.Errorf("results[%d].status = %d, want %d", i, result.Status, tt.wantResults[i])
+				}
+
+				code := ""
+				if result.Problem != nil {
+					code = result.Problem.Code
+					if want := fmt.Sprintf("/v1/tasks/bulk#/operations/%d", i); result.Problem.Instance != want {
+						t.Errorf("results[%d].problem.instance = %q, want %q", i, result.Problem.Instance, want)
+					}
+					if strings.Contains(result.Problem.Detail, "connection reset") {
+						t.Errorf("results[%d].problem.detail = %q exposes an internal error", i, result.Problem.Detail)
+					}
+				}
+				if code != tt.wantCodes[i] {
+					t.Errorf("results[%d].problem.code = %q, want %q", i, code, tt.wantCodes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTaskHTTPHandlerSubtasks(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		wantStatus   int
+		wantParentID int64
+		wantCascade  bool
+	}{
+		{name: "create", method: http.MethodPost, target: "/v1/tasks/1/subtasks", body: `{"title":"draft outline"}`, wantStatus: http.StatusCreated, wantParentID: 1},
+		{name: "create without title", method: http.MethodPost, target: "/v1/tasks/1/subtasks", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "create under a missing task", method: http.MethodPost, target: "/v1/tasks/9/subtasks", body: `{"title":"draft outline"}`, wantStatus: http.StatusNotFound},
+		{name: "create under an invalid ID", method: http.MethodPost, target: "/v1/tasks/x/subtasks", body: `{"title":"draft outline"}`, wantStatus: http.StatusBadRequest},
+		{name: "list", method: http.MethodGet, target: "/v1/tasks/1/subtasks", wantStatus: http.StatusOK, wantParentID: 1},
+		{name: "complete", method: http.MethodPost, target: "/v1/tasks/1/complete", wantStatus: http.StatusOK},
+		{name: "complete with cascade", method: http.MethodPost, target: "/v1/tasks/1/complete?cascade=true", wantStatus: http.StatusOK, wantCascade: true},
+		{name: "complete with an invalid cascade", method: http.MethodPost, target: "/v1/tasks/1/complete?cascade=maybe", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &fakeTaskUsecase{task: &model.Task{ID: 1, Title: "write report", Status: model.TaskStatusTodo}}
+			e := newTestTaskServer(tu)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tu.parentID != tt.wantParentID {
+				t.Errorf("parent ID = %d, want %d", tu.parentID, tt.wantParentID)
+			}
+			if tu.cascade != tt.wantCascade {
+				t.Errorf("cascade = %t, want %t", tu.cascade, tt.wantCascade)
+			}
+		})
+	}
+}
+
+func TestTaskHTTPHandlerUpdateTaskPosition(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{name: "after a task", target: "/v1/tasks/1/position", body: `{"after_id":9}`, wantStatus: http.StatusOK},
+		{name: "before a task", target: "/v1/tasks/1/position", body: `{"before_id":9}`, wantStatus: http.StatusOK},
+		{name: "without neighbours", target: "/v1/tasks/1/position", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "missing neighbour", target: "/v1/tasks/1/position", body: `{"after_id":3}`, wantStatus: http.StatusNotFound},
+		{name: "missing task", target: "/v1/tasks/2/position", body: `{"after_id":9}`, wantStatus: http.StatusNotFound},
+		{name: "invalid ID", target: "/v1/tasks/x/position", body: `{"after_id":9}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			newTestTaskServer(&fakeBoardUsecase{}).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHTTPHandlerPatchTask(t *testing.T) {
+	task := &model.Task{ID: 1, Title: "write report", Status: model.TaskStatusTodo, Version: 3}
+
+	tests := []struct {
+		name        string
+		contentType string
+		ifMatch     string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantTitle   string
+		wantVersion int64
+	}{
+		{name: "merge patch", contentType: model.MergePatchContentType, body: `{"title":"edited"}`, wantStatus: http.StatusOK, wantTitle: "edited"},
+		{name: "merge patch as plain json", contentType: echo.MIMEApplicationJSON, body: `{"title":"edited"}`, wantStatus: http.StatusOK, wantTitle: "edited"},
+		{name: "json patch", contentType: model.JSONPatchContentType, body: `[{"op":"replace","path":"/title","value":"edited"}]`, wantStatus: http.StatusOK, wantTitle: "edited"},
+		{name: "content type with params", contentType: model.MergePatchContentType + "; charset=utf-8", body: `{"title":"edited"}`, wantStatus: http.StatusOK, wantTitle: "edited"},
+		{name: "matching version", contentType: model.MergePatchContentType, ifMatch: task.ETag(), body: `{"title":"edited"}`, wantStatus: http.StatusOK, wantTitle: "edited", wantVersion: 3},
+		{name: "stale version", contentType: model.MergePatchContentType, ifMatch: `"2-x"`, body: `{"title":"edited"}`, wantStatus: http.StatusPreconditionFailed, wantCode: "task_version_mismatch"},
+		{name: "unsupported content type", contentType: echo.MIMETextPlain, body: `title=edited`, wantStatus: http.StatusUnsupportedMediaType, wantCode: "unsupported_media_type"},
+		{name: "invalid patch", contentType: model.JSONPatchContentType, body: `[{"op":"replace","path":"/owner","value":1}]`, wantStatus: http.StatusBadRequest, wantCode: "invalid_patch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &fakeTaskUsecase{task: task}
+
+			req := httptest.NewRequest(http.MethodPatch, "/v1/tasks/1", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set(headerIfMatch, tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			newTestTaskServer(tu).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tu.version != tt.wantVersion {
+				t.Errorf("patch guarded with version %d, want %d", tu.version, tt.wantVersion)
+			}
+
+			response := struct {
+				Title string `json:"title"`
+				Code  string `json:"code"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if response.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", response.Title, tt.wantTitle)
+			}
+			if rec.Header().Get(headerETag) == "" {
+				t.Error("ETag header is missing")
+			}
+		})
+	}
+}
+
+func TestTaskHTTPHandlerFetchTaskHistory(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		wantStatus     int
+		wantTotalPages int64
+	}{
+		{name: "first page", target: "/v1/tasks/1/history", wantStatus: http.StatusOK, wantTotalPages: 1},
+		{name: "size past the limit", target: "/v1/tasks/1/history?size=101", wantStatus: http.StatusBadRequest},
+		{name: "missing task", target: "/v1/tasks/2/history", wantStatus: http.StatusNotFound},
+		{name: "invalid ID", target: "/v1/tasks/x/history", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &fakeTaskUsecase{task: &model.Task{ID: 1}}
+			rec := httptest.NewRecorder()
+			newTestTaskServer(tu).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			response := struct {
+				Data       []*model.TaskEvent `json:"data"`
+				Size       int64              `json:"size"`
+				TotalPages int64              `json:"total_pages"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Data) != 1 || response.Data[0].Changes["title"].To != "write report" {
+				t.Errorf("data = %+v, want the created event", response.Data)
+			}
+			if response.Size != 10 || response.TotalPages != tt.wantTotalPages {
+				t.Errorf("size = %d, total pages = %d, want 10 and %d", response.Size, response.TotalPages, tt.wantTotalPages)
+			}
+		})
+	}
+}
+
+func TestTaskHTTPHandlerFetchTasksSort(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantFields []string
+	}{
+		{name: "default", target: "/v1/tasks", wantStatus: http.StatusOK},
+		{name: "whitelisted", target: "/v1/tasks?sort=due_date&order=asc&tags=work&tags_mode=all", wantStatus: http.StatusOK},
+		{name: "unknown sort", target: "/v1/tasks?sort=owner_id", wantStatus: http.StatusBadRequest, wantFields: []string{"sort"}},
+		{name: "unknown order and tags mode", target: "/v1/tasks?order=up&tags_mode=some", wantStatus: http.StatusBadRequest, wantFields: []string{"order", "tags_mode"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newTestTaskServer(&fakeTaskUsecase{task: &model.Task{ID: 1}}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantFields == nil {
+				return
+			}
+
+			got := Problem{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			fields := []string{}
+			for _, field := range got.Errors {
+				fields = append(fields, field.Field)
+			}
+			if got.Code != "validation" || strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("problem = %s %v, want validation %v", got.Code, fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+// fakeTrashUsecase holds task 1 in the trash and task 2 outside of it
+type fakeTrashUsecase struct {
+	model.TaskUsecase
+
+	// query is the query of the last listing
+	query model.GetTrashedTasksQueryParams
+}
+
+func (u *fakeTrashUsecase) FindAllTrashed(ctx context.Context, query model.GetTrashedTasksQueryParams) ([]*model.Task, int64, error) {
+	u.query = query
+	return []*model.Task{{ID: 1}}, 1, nil
+}
+
+func (u *fakeTrashUsecase) RestoreByID(ctx context.Context, ID int64) (*model.Task, error) {
+	if ID != 1 {
+		return nil, model.ErrTaskNotFound
+	}
+	return &model.Task{ID: 1, Version: 2}, nil
+}
+
+func (u *fakeTrashUsecase) PurgeByID(ctx context.Context, ID int64) error {
+	if ID != 1 {
+		return model.ErrTaskNotFound
+	}
+	return nil
+}
+
+func TestTaskHTTPHandlerTrash(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantCode   string
+		wantPage   model.Pagination
+	}{
+		{name: "list", method: http.MethodGet, target: "/v1/tasks/trash", wantStatus: http.StatusOK, wantPage: model.Pagination{Page: 1, Size: 10}},
+		{name: "list a page", method: http.MethodGet, target: "/v1/tasks/trash?page=2&size=5&sort=title", wantStatus: http.StatusOK, wantPage: model.Pagination{Page: 2, Size: 5}},
+		{name: "list past the max size", method: http.MethodGet, target: "/v1/tasks/trash?size=101", wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "restore", method: http.MethodPost, target: "/v1/tasks/1/restore", wantStatus: http.StatusOK},
+		{name: "restore a task outside of the trash", method: http.MethodPost, target: "/v1/tasks/2/restore", wantStatus: http.StatusNotFound, wantCode: "task_not_found"},
+		{name: "restore an invalid ID", method: http.MethodPost, target: "/v1/tasks/x/restore", wantStatus: http.StatusBadRequest, wantCode: "invalid_param"},
+		{name: "purge", method: http.MethodDelete, target: "/v1/tasks/1/purge", wantStatus: http.StatusNoContent},
+		{name: "purge a task outside of the trash", method: http.MethodDelete, target: "/v1/tasks/2/purge", wantStatus: http.StatusNotFound, wantCode: "task_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &fakeTrashUsecase{}
+			rec := httptest.NewRecorder()
+			newTestTaskServer(tu).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tu.query.Pagination != tt.wantPage {
+				t.Errorf("pagination = %+v, want %+v", tu.query.Pagination, tt.wantPage)
+			}
+			if rec.Code == http.StatusNoContent {
+				return
+			}
+
+			response := struct {
+				Code string `json:"code"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// fakeDueUsecase records the window of the last due listing
+type fakeDueUsecase struct {
+	model.TaskUsecase
+
+	overdue bool
+	within  time.Duration
+}
+
+func (u *fakeDueUsecase) FindOverdue(ctx context.Context, query model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	u.overdue = true
+	return []*model.Task{}, 0, nil
+}
+
+func (u *fakeDueUsecase) FindDueWithin(ctx context.Context, within time.Duration, query model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	u.within = within
+	return []*model.Task{}, 0, nil
+}
+
+func TestTaskHTTPHandlerDueTasks(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		wantStatus  int
+		wantCode    string
+		wantOverdue bool
+		wantWithin  time.Duration
+	}{
+		{name: "overdue", target: "/v1/tasks/overdue", wantStatus: http.StatusOK, wantOverdue: true},
+		{name: "due within the default window", target: "/v1/tasks/due", wantStatus: http.StatusOK, wantWithin: config.DefaultDueWithin},
+		{name: "due within a window", target: "/v1/tasks/due?within=90m&page=2&size=5", wantStatus: http.StatusOK, wantWithin: 90 * time.Minute},
+		{name: "due within a window in days", target: "/v1/tasks/due?within=7d", wantStatus: http.StatusBadRequest, wantCode: "invalid_param"},
+		{name: "due within an empty window", target: "/v1/tasks/due?within=0s", wantStatus: http.StatusBadRequest, wantCode: "invalid_param"},
+		{name: "due within a negative window", target: "/v1/tasks/due?within=-1h", wantStatus: http.StatusBadRequest, wantCode: "invalid_param"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &fakeDueUsecase{}
+			rec := httptest.NewRecorder()
+			newTestTaskServer(tu).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tu.overdue != tt.wantOverdue || tu.within != tt.wantWithin {
+				t.Errorf("overdue %t within %s, want overdue %t within %s", tu.overdue, tu.within, tt.wantOverdue, tt.wantWithin)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+
+			response := struct {
+				Code string `json:"code"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+type UserHTTPHandler struct {
+	UserUsecase model.UserUsecase
+}
+
+// NewUserHTTPHandler registers the routes creating credentials, they are
+// reachable without any
+func NewUserHTTPHandler(e *echo.Echo, uu model.UserUsecase) {
+	handler := UserHTTPHandler{UserUsecase: uu}
+
+	g := e.Group("/v1/auth")
+	g.POST("/register", handler.Register)
+	g.POST("/login", handler.Login)
+}
+
+func (uh *UserHTTPHandler) Register(c echo.Context) error {
+	input := new(model.RegisterInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	user, err := uh.UserUsecase.Register(c.Request().Context(), *input)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, user)
+}
+
+func (uh *UserHTTPHandler) Login(c echo.Context) error {
+	input := new(model.LoginInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	token, err := uh.UserUsecase.Login(c.Request().Context(), *input)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.JSON(http.StatusOK, token)
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/model"
+)
+
+// fakeUserUsecase knows alice@example.com with the password "correct horse"
+type fakeUserUsecase struct{}
+
+func (fakeUserUsecase) Register(ctx context.Context, input model.RegisterInput) (*model.User, error) {
+	user := input.ToModel()
+	if user.Email == "alice@example.com" {
+		return nil, model.ErrUserEmailTaken
+	}
+	user.PasswordHash = "hash"
+	return user, nil
+}
+
+func (fakeUserUsecase) Login(ctx context.Context, input model.LoginInput) (*model.AccessToken, error) {
+	if input.Email != "alice@example.com" || input.Password != "correct horse" {
+		return nil, model.ErrInvalidCredentials
+	}
+	return &model.AccessToken{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 3600}, nil
+}
+
+func TestUserHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "register", target: "/v1/auth/register", body: `{"email":"Bob@example.com","password":"correct horse"}`, wantStatus: http.StatusCreated},
+		{name: "register a taken email", target: "/v1/auth/register", body: `{"email":"alice@example.com","password":"correct horse"}`, wantStatus: http.StatusConflict, wantCode: "user_email_taken"},
+		{name: "register an invalid email", target: "/v1/auth/register", body: `{"email":"bob","password":"correct horse"}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "register a short password", target: "/v1/auth/register", body: `{"email":"bob@example.com","password":"short"}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "register a password past bcrypt's limit", target: "/v1/auth/register", body: `{"email":"bob@example.com","password":"` + strings.Repeat("x", 73) + `"}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "login", target: "/v1/auth/login", body: `{"email":"alice@example.com","password":"correct horse"}`, wantStatus: http.StatusOK},
+		{name: "login with a wrong password", target: "/v1/auth/login", body: `{"email":"alice@example.com","password":"wrong horse"}`, wantStatus: http.StatusUnauthorized, wantCode: "invalid_credentials"},
+		{name: "login without password", target: "/v1/auth/login", body: `{"email":"alice@example.com"}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = NewRequestValidator(10, 100)
+			NewUserHTTPHandler(e, fakeUserUsecase{})
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "hash") || strings.Contains(rec.Body.String(), "correct horse") {
+				t.Errorf("response %s exposes the password", rec.Body.String())
+			}
+
+			response := struct {
+				Code string `json:"code"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+			if tt.target == "/v1/auth/login" && rec.Code == http.StatusOK && rec.Header().Get(echo.HeaderCacheControl) != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", rec.Header().Get(echo.HeaderCacheControl))
+			}
+		})
+	}
+}
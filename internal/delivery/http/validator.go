@@ -0,0 +1,41 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/validation"
+)
+
+// RequestValidator is the echo.Validator of the API. Inputs with a Validate
+// method validate themselves, others are checked against their validate
+// tags. Listings also get their missing page and size filled in.
+type RequestValidator struct {
+	DefaultPageSize int64
+	MaxPageSize     int64
+}
+
+func NewRequestValidator(defaultPageSize, maxPageSize int64) echo.Validator {
+	return &RequestValidator{
+		DefaultPageSize: defaultPageSize,
+		MaxPageSize:     maxPageSize,
+	}
+}
+
+func (v *RequestValidator) Validate(i interface{}) error {
+	var err error
+	if validatable, ok := i.(interface{ Validate() error }); ok {
+		err = validatable.Validate()
+	} else {
+		err = validation.Struct(i)
+	}
+	if err != nil {
+		return err
+	}
+
+	if paginated, ok := i.(interface {
+		Paginate(defaultSize, maxSize int64) error
+	}); ok {
+		return paginated.Paginate(v.DefaultPageSize, v.MaxPageSize)
+	}
+	return nil
+}
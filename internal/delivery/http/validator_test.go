@@ -0,0 +1,110 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+type taggedInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type selfValidatingInput struct {
+	Name string `json:"name" validate:"required"`
+	err  error
+}
+
+func (i selfValidatingInput) Validate() error {
+	return i.err
+}
+
+func TestRequestValidator(t *testing.T) {
+	errOwn := errors.New("name is taken")
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		wantErr  error
+		wantPage *model.Pagination
+	}{
+		{name: "valid tags", input: &taggedInput{Name: "a"}},
+		{name: "invalid tags", input: &taggedInput{}, wantErr: errs.ErrValidation},
+		{name: "own validation replaces the tags", input: &selfValidatingInput{}},
+		{name: "own validation error", input: &selfValidatingInput{Name: "a", err: errOwn}, wantErr: errOwn},
+		{name: "pagination defaults", input: &model.GetTagsQueryParams{}, wantPage: &model.Pagination{Page: 1, Size: 10}},
+		{name: "pagination bounds", input: &model.GetTagsQueryParams{Pagination: model.Pagination{Size: 101}}, wantErr: errs.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewRequestValidator(10, 100).Validate(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantPage == nil {
+				return
+			}
+			page := reflect.ValueOf(tt.input).Elem().FieldByName("Pagination").Interface().(model.Pagination)
+			if page != *tt.wantPage {
+				t.Errorf("pagination = %+v, want %+v", page, *tt.wantPage)
+			}
+		})
+	}
+}
+
+func TestTaskHTTPHandlerCreateTaskValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []errs.FieldError
+	}{
+		{
+			name: "validate tags",
+			body: `{"title":"","tags":["` + strings.Repeat("x", 65) + `"],"project_id":0}`,
+			want: []errs.FieldError{
+				{Field: "title", Rule: "required", Message: "title is required"},
+				{Field: "project_id", Rule: "gt", Message: "project_id must be greater than 0"},
+				{Field: "tags[0]", Rule: "max", Message: "tags[0] must be at most 64 characters"},
+			},
+		},
+		{
+			name: "priority",
+			body: `{"title":"write report","priority":"critical"}`,
+			want: []errs.FieldError{
+				{Field: "priority", Rule: "oneof", Message: "priority must be one of none low medium high urgent"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/tasks", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			newTestTaskServer(&fakeTaskUsecase{}).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+
+			got := Problem{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Code != "validation" || !reflect.DeepEqual(got.Errors, tt.want) {
+				t.Errorf("problem = %s %+v, want validation %+v", got.Code, got.Errors, tt.want)
+			}
+		})
+	}
+}
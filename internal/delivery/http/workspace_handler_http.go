@@ -0,0 +1,122 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+type WorkspaceHTTPHandler struct {
+	WorkspaceUsecase model.WorkspaceUsecase
+}
+
+func NewWorkspaceHTTPHandler(e *echo.Echo, wu model.WorkspaceUsecase, mws ...echo.MiddlewareFunc) {
+	handler := WorkspaceHTTPHandler{WorkspaceUsecase: wu}
+
+	g := e.Group("/v1", mws...)
+	g.POST("/workspaces", handler.CreateWorkspace)
+	g.GET("/workspaces", handler.FetchWorkspaces)
+	g.POST("/workspaces/:ID/members", handler.AddWorkspaceMember)
+	g.DELETE("/workspaces/:ID/members/:UserID", handler.RemoveWorkspaceMember)
+}
+
+func (wh *WorkspaceHTTPHandler) CreateWorkspace(c echo.Context) error {
+	input := new(model.CreateWorkspaceInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	workspace, err := wh.WorkspaceUsecase.Create(c.Request().Context(), input.ToModel())
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, workspace)
+}
+
+func (wh *WorkspaceHTTPHandler) FetchWorkspaces(c echo.Context) error {
+	queryParams := new(model.GetWorkspacesQueryParams)
+
+	if err := c.Bind(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(queryParams); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	workspaces, count, err := wh.WorkspaceUsecase.FindAll(c.Request().Context(), *queryParams)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.NewPaginationResponse(
+		workspaces,
+		queryParams.Page,
+		queryParams.Size,
+		count,
+	))
+}
+
+func (wh *WorkspaceHTTPHandler) AddWorkspaceMember(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	input := new(model.AddWorkspaceMemberInput)
+	if err := c.Bind(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		logrus.Error(err)
+		return problem(c, errs.Validation(err))
+	}
+
+	member, err := wh.WorkspaceUsecase.AddMember(c.Request().Context(), ID, *input)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, member)
+}
+
+func (wh *WorkspaceHTTPHandler) RemoveWorkspaceMember(c echo.Context) error {
+	ID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("ID"))
+	}
+
+	userID, err := strconv.ParseInt(c.Param("UserID"), 10, 64)
+	if err != nil {
+		logrus.Error(err)
+		return problem(c, invalidParam("UserID"))
+	}
+
+	if err := wh.WorkspaceUsecase.RemoveMember(c.Request().Context(), ID, userID); err != nil {
+		logrus.Error(err)
+		return problem(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/model"
+)
+
+// fakeWorkspaceAdminUsecase manages workspace 1, acme, where bob is already
+// a member and carol can be added. Member 1 is its last owner.
+type fakeWorkspaceAdminUsecase struct {
+	model.WorkspaceUsecase
+}
+
+func (fakeWorkspaceAdminUsecase) Create(ctx context.Context, input *model.Workspace) (*model.Workspace, error) {
+	if input.Slug == "acme" {
+		return nil, model.ErrWorkspaceSlugTaken
+	}
+	return input, nil
+}
+
+func (fakeWorkspaceAdminUsecase) FindAll(ctx context.Context, query model.GetWorkspacesQueryParams) ([]*model.Workspace, int64, error) {
+	return []*model.Workspace{{ID: 1, Name: "Acme", Slug: "acme"}}, 1, nil
+}
+
+func (fakeWorkspaceAdminUsecase) AddMember(ctx context.Context, workspaceID int64, input model.AddWorkspaceMemberInput) (*model.WorkspaceMember, error) {
+	switch {
+	case workspaceID != 1:
+		return nil, model.ErrWorkspaceNotFound
+	case input.Email == "bob@example.com":
+		return nil, model.ErrWorkspaceMemberExists
+	case input.Email != "carol@example.com":
+		return nil, model.ErrUserNotFound
+	}
+	return &model.WorkspaceMember{WorkspaceID: workspaceID, UserID: 3, Role: model.WorkspaceRoleMember}, nil
+}
+
+func (fakeWorkspaceAdminUsecase) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	switch {
+	case workspaceID != 1:
+		return model.ErrWorkspaceNotFound
+	case userID == 1:
+		return model.ErrLastWorkspaceOwner
+	}
+	return nil
+}
+
+func TestWorkspaceHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "create", method: http.MethodPost, target: "/v1/workspaces", body: `{"name":"Globex","slug":"globex"}`, wantStatus: http.StatusCreated},
+		{name: "create with a taken slug", method: http.MethodPost, target: "/v1/workspaces", body: `{"name":"Acme","slug":"acme"}`, wantStatus: http.StatusConflict, wantCode: "workspace_slug_taken"},
+		{name: "create with a numeric slug", method: http.MethodPost, target: "/v1/workspaces", body: `{"name":"Globex","slug":"42"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_workspace_slug"},
+		{name: "create with a slug which is no DNS label", method: http.MethodPost, target: "/v1/workspaces", body: `{"name":"Globex","slug":"Globex Corp"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_workspace_slug"},
+		{name: "create without name", method: http.MethodPost, target: "/v1/workspaces", body: `{"slug":"globex"}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "list", method: http.MethodGet, target: "/v1/workspaces", wantStatus: http.StatusOK},
+		{name: "add member", method: http.MethodPost, target: "/v1/workspaces/1/members", body: `{"email":"carol@example.com"}`, wantStatus: http.StatusCreated},
+		{name: "add member twice", method: http.MethodPost, target: "/v1/workspaces/1/members", body: `{"email":"bob@example.com"}`, wantStatus: http.StatusConflict, wantCode: "workspace_member_exists"},
+		{name: "add member with an unknown role", method: http.MethodPost, target: "/v1/workspaces/1/members", body: `{"email":"carol@example.com","role":"admin"}`, wantStatus: http.StatusBadRequest, wantCode: "validation"},
+		{name: "add member to another workspace", method: http.MethodPost, target: "/v1/workspaces/2/members", body: `{"email":"carol@example.com"}`, wantStatus: http.StatusNotFound, wantCode: "workspace_not_found"},
+		{name: "remove member", method: http.MethodDelete, target: "/v1/workspaces/1/members/2", wantStatus: http.StatusNoContent},
+		{name: "remove the last owner", method: http.MethodDelete, target: "/v1/workspaces/1/members/1", wantStatus: http.StatusConflict, wantCode: "last_workspace_owner"},
+		{name: "remove an invalid user ID", method: http.MethodDelete, target: "/v1/workspaces/1/members/x", wantStatus: http.StatusBadRequest, wantCode: "invalid_param"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = NewRequestValidator(10, 100)
+			NewWorkspaceHTTPHandler(e, fakeWorkspaceAdminUsecase{})
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code == http.StatusNoContent {
+				return
+			}
+
+			response := struct {
+				Code string `json:"code"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", response.Code, tt.wantCode)
+			}
+		})
+	}
+}
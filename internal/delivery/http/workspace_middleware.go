@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+)
+
+const headerWorkspace = "X-Workspace"
+
+// NewWorkspaceMiddleware scopes the request to the workspace named, by ID or
+// slug, in the X-Workspace header, the subdomain of the host below
+// baseDomain or the claim of the bearer token, in this order. Requests
+// naming none keep to the personal tasks of the principal. It has to run
+// after the auth middleware.
+func NewWorkspaceMiddleware(wu model.WorkspaceUsecase, baseDomain, claim string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ref := workspaceRef(c, baseDomain, claim)
+			if ref == "" {
+				return next(c)
+			}
+
+			workspace, err := wu.Resolve(c.Request().Context(), ref)
+			if err != nil {
+				logrus.Error(err)
+				return problem(c, err)
+			}
+
+			ctx := model.ContextWithWorkspace(c.Request().Context(), workspace.ID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+func workspaceRef(c echo.Context, baseDomain, claim string) string {
+	if ref := c.Request().Header.Get(headerWorkspace); ref != "" {
+		return ref
+	}
+
+	if baseDomain != "" {
+		host := c.Request().Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+
+		suffix := "." + strings.ToLower(baseDomain)
+		host = strings.ToLower(host)
+		if subdomain := strings.TrimSuffix(host, suffix); subdomain != host && subdomain != "" && !strings.Contains(subdomain, ".") {
+			return subdomain
+		}
+	}
+
+	principal := model.PrincipalFromContext(c.Request().Context())
+	if principal == nil {
+		return ""
+	}
+
+	// numeric claims are parsed as json.Number, so that large IDs keep
+	// their precision
+	switch ref := principal.Claims[claim].(type) {
+	case string:
+		return ref
+	case json.Number:
+		return ref.String()
+	default:
+		return ""
+	}
+}
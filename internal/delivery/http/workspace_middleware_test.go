@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-app/internal/model"
+)
+
+// fakeWorkspaceUsecase resolves workspace 1, acme, for every principal
+type fakeWorkspaceUsecase struct {
+	model.WorkspaceUsecase
+}
+
+func (fakeWorkspaceUsecase) Resolve(ctx context.Context, ref string) (*model.Workspace, error) {
+	if ref != "1" && ref != "acme" {
+		return nil, model.ErrWorkspaceNotFound
+	}
+	return &model.Workspace{ID: 1, Slug: "acme"}, nil
+}
+
+func TestWorkspaceMiddleware(t *testing.T) {
+	tests := []struct {
+		name            string
+		host            string
+		header          string
+		claims          map[string]interface{}
+		wantStatus      int
+		wantWorkspaceID int64
+	}{
+		{name: "none", wantStatus: http.StatusOK},
+		{name: "header by slug", header: "acme", wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "header by ID", header: "1", wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "header before subdomain", host: "globex.todo.test", header: "acme", wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "subdomain", host: "acme.todo.test", wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "subdomain with port and in upper case", host: "ACME.Todo.Test:8080", wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "subdomain before claim", host: "acme.todo.test", claims: map[string]interface{}{"workspace": "globex"}, wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "nested subdomain", host: "www.acme.todo.test", wantStatus: http.StatusOK},
+		{name: "base domain", host: "todo.test", wantStatus: http.StatusOK},
+		{name: "other domain", host: "acme.example.com", wantStatus: http.StatusOK},
+		{name: "string claim", claims: map[string]interface{}{"workspace": "acme"}, wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "numeric claim", claims: map[string]interface{}{"workspace": json.Number("1")}, wantStatus: http.StatusOK, wantWorkspaceID: 1},
+		{name: "claim of another type", claims: map[string]interface{}{"workspace": true}, wantStatus: http.StatusOK},
+		{name: "unknown workspace", header: "globex", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticate := func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					ctx := model.ContextWithPrincipal(c.Request().Context(), &model.Principal{
+						Kind:   model.PrincipalToken,
+						UserID: 42,
+						Claims: tt.claims,
+					})
+					c.SetRequest(c.Request().WithContext(ctx))
+					return next(c)
+				}
+			}
+
+			e := echo.New()
+			e.GET("/v1/tasks", func(c echo.Context) error {
+				workspaceID, ok := model.WorkspaceIDFromContext(c.Request().Context())
+				if ok != (tt.wantWorkspaceID != 0) || workspaceID != tt.wantWorkspaceID {
+					t.Errorf("workspace = %d, %t, want %d", workspaceID, ok, tt.wantWorkspaceID)
+				}
+				return c.NoContent(http.StatusOK)
+			}, authenticate, NewWorkspaceMiddleware(fakeWorkspaceUsecase{}, "todo.test", "workspace"))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/tasks", nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.header != "" {
+				req.Header.Set(headerWorkspace, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
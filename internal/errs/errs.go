@@ -0,0 +1,124 @@
+// Package errs holds the domain errors. Every error returned to a client is
+// classified by a Kind and identified by a Code which stays stable, so that
+// clients can branch on it instead of on the message.
+package errs
+
+import (
+	"errors"
+	"strings"
+)
+
+type Kind string
+
+const (
+	KindNotFound             Kind = "not_found"
+	KindValidation           Kind = "validation"
+	KindConflict             Kind = "conflict"
+	KindPreconditionFailed   Kind = "precondition_failed"
+	KindUnprocessable        Kind = "unprocessable"
+	KindUnsupportedMediaType Kind = "unsupported_media_type"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindRateLimited          Kind = "rate_limited"
+	KindFailedDependency     Kind = "failed_dependency"
+	KindInternal             Kind = "internal"
+)
+
+// The generic errors of each kind, errors.Is matches every error of the kind
+// against them
+var (
+	ErrNotFound             = New(KindNotFound, string(KindNotFound), "resource not found")
+	ErrValidation           = New(KindValidation, string(KindValidation), "request is invalid")
+	ErrConflict             = New(KindConflict, string(KindConflict), "resource is in conflict")
+	ErrPreconditionFailed   = New(KindPreconditionFailed, string(KindPreconditionFailed), "precondition failed")
+	ErrUnprocessable        = New(KindUnprocessable, string(KindUnprocessable), "request cannot be processed")
+	ErrUnsupportedMediaType = New(KindUnsupportedMediaType, string(KindUnsupportedMediaType), "media type is not supported")
+	ErrUnauthorized         = New(KindUnauthorized, string(KindUnauthorized), "authentication is required")
+	ErrForbidden            = New(KindForbidden, string(KindForbidden), "access is denied")
+	ErrRateLimited          = New(KindRateLimited, string(KindRateLimited), "too many requests")
+	ErrFailedDependency     = New(KindFailedDependency, string(KindFailedDependency), "a dependency failed")
+	ErrInternal             = New(KindInternal, string(KindInternal), "internal error")
+)
+
+// Error is a domain error, Err is the optional cause. Validation errors list
+// the offending input fields in Fields.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError points at an input field which failed validation, Rule names
+// the rule it broke
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same code, and the generic error of a kind
+// against every error of that kind
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Kind == t.Kind && (e.Code == t.Code || t.Code == string(t.Kind))
+}
+
+// Wrap returns a copy of e caused by err
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithFields returns a copy of e listing fields, their messages become the
+// cause
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	messages := []string{}
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+
+	wrapped := e.Wrap(errors.New(strings.Join(messages, "; ")))
+	wrapped.Fields = fields
+	return wrapped
+}
+
+// From returns the domain error in the chain of err, errors which are not
+// classified are internal
+func From(err error) *Error {
+	e := &Error{}
+	if errors.As(err, &e) {
+		return e
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// Validation classifies err as a validation error, unless it already is a
+// domain error
+func Validation(err error) error {
+	e := &Error{}
+	if errors.As(err, &e) {
+		return err
+	}
+	return ErrValidation.Wrap(err)
+}
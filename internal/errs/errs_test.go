@@ -0,0 +1,105 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var errTaskNotFound = New(KindNotFound, "task_not_found", "task not found")
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{name: "same error", err: errTaskNotFound, target: errTaskNotFound, want: true},
+		{name: "same code", err: New(KindNotFound, "task_not_found", "gone"), target: errTaskNotFound, want: true},
+		{name: "generic error of the kind", err: errTaskNotFound, target: ErrNotFound, want: true},
+		{name: "generic error of another kind", err: errTaskNotFound, target: ErrConflict},
+		{name: "specific error against the generic one", err: ErrNotFound, target: errTaskNotFound},
+		{name: "other code", err: New(KindNotFound, "tag_not_found", "tag not found"), target: errTaskNotFound},
+		{name: "wrapped by fmt", err: fmt.Errorf("finding: %w", errTaskNotFound), target: errTaskNotFound, want: true},
+		{name: "wrapping a cause", err: errTaskNotFound.Wrap(errors.New("no rows")), target: errTaskNotFound, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %t, want %t", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorWrap(t *testing.T) {
+	cause := errors.New("no rows")
+	wrapped := errTaskNotFound.Wrap(cause)
+
+	if wrapped.Error() != "task not found: no rows" {
+		t.Errorf("Error() = %q, want %q", wrapped.Error(), "task not found: no rows")
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Error("the wrapped error does not unwrap to its cause")
+	}
+	if errTaskNotFound.Err != nil {
+		t.Error("Wrap() modified the wrapped error")
+	}
+}
+
+func TestErrorWithFields(t *testing.T) {
+	err := ErrValidation.WithFields(
+		FieldError{Field: "title", Rule: "required", Message: "title is required"},
+		FieldError{Field: "size", Rule: "lte", Message: "size must be at most 100"},
+	)
+
+	if want := "request is invalid: title is required; size must be at most 100"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if len(err.Fields) != 2 || len(ErrValidation.Fields) != 0 {
+		t.Errorf("fields = %v, generic fields = %v, want 2 and none", err.Fields, ErrValidation.Fields)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind Kind
+		wantCode string
+	}{
+		{name: "domain error", err: errTaskNotFound, wantKind: KindNotFound, wantCode: "task_not_found"},
+		{name: "wrapped domain error", err: fmt.Errorf("finding: %w", errTaskNotFound), wantKind: KindNotFound, wantCode: "task_not_found"},
+		{name: "unclassified error", err: errors.New("connection reset"), wantKind: KindInternal, wantCode: "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Kind != tt.wantKind || got.Code != tt.wantCode {
+				t.Errorf("From(%v) = %s/%s, want %s/%s", tt.err, got.Kind, got.Code, tt.wantKind, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{name: "unclassified error", err: errors.New("title is required"), wantCode: "validation"},
+		{name: "domain error", err: errTaskNotFound, wantCode: "task_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := From(Validation(tt.err)); got.Code != tt.wantCode {
+				t.Errorf("Validation(%v) code = %s, want %s", tt.err, got.Code, tt.wantCode)
+			}
+		})
+	}
+}
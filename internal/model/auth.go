@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+	"todo-app/internal/errs"
+	"todo-app/internal/utils"
+)
+
+var (
+	ErrMissingCredentials = errs.New(errs.KindUnauthorized, "missing_credentials", "bearer token or API key is required")
+	ErrInvalidToken       = errs.New(errs.KindUnauthorized, "invalid_token", "bearer token is invalid")
+	ErrInvalidAPIKey      = errs.New(errs.KindUnauthorized, "invalid_api_key", "API key is invalid")
+	ErrAPIKeyNotFound     = errs.New(errs.KindNotFound, "api_key_not_found", "API key not found")
+)
+
+type PrincipalKind string
+
+const (
+	PrincipalToken  PrincipalKind = "token"
+	PrincipalAPIKey PrincipalKind = "api_key"
+)
+
+// Principal is the authenticated caller of a request. Subject is the sub
+// claim of a token or api_key:<id> for an API key, Claims are the claims of
+// a token. UserID is the user the caller acts for.
+type Principal struct {
+	Kind    PrincipalKind
+	Subject string
+	UserID  int64
+	Claims  map[string]interface{}
+}
+
+type principalContextKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns nil for unauthenticated contexts, e.g. the
+// ones of the workers
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+// UserIDFromContext returns the user of the principal of ctx, false for
+// unauthenticated contexts
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return 0, false
+	}
+	return principal.UserID, true
+}
+
+// ActorFromContext names the principal of ctx in the task history, empty for
+// changes made by the system
+func ActorFromContext(ctx context.Context) string {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ""
+	}
+	return principal.Subject
+}
+
+// TokenKeys verify the signatures of bearer tokens. HMACSecret verifies
+// HS256 tokens, RSAKeys verify RS256 tokens by their kid, the key under the
+// empty kid is used for tokens without a known kid.
+type TokenKeys struct {
+	HMACSecret []byte
+	RSAKeys    map[string]*rsa.PublicKey
+	Issuer     string
+	Audience   string
+}
+
+// apiKeyPrefix marks API keys so that they are easy to spot, e.g. by secret
+// scanners
+const apiKeyPrefix = "tdk_"
+
+// APIKey authenticates a machine client acting for UserID, only the SHA-256
+// of the key is stored. Prefix keeps the start of the key so that it can be
+// recognized. Keys minted before users existed have no UserID.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     *int64     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// NewAPIKey mints a key named name for the user, the returned secret is the
+// only copy of the key
+func NewAPIKey(name string, userID int64) (*APIKey, string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+
+	secret := apiKeyPrefix + hex.EncodeToString(random)
+	return &APIKey{
+		ID:        utils.GenerateID(),
+		UserID:    &userID,
+		Name:      name,
+		Prefix:    secret[:len(apiKeyPrefix)+8],
+		KeyHash:   HashAPIKey(secret),
+		CreatedAt: time.Now(),
+	}, secret, nil
+}
+
+// HashAPIKey returns the stored form of secret, keys are random enough for a
+// plain SHA-256
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) (err error)
+	FindByHash(ctx context.Context, hash string) (key *APIKey, err error)
+	TouchByID(ctx context.Context, ID int64, usedAt time.Time) (err error)
+}
+
+type AuthUsecase interface {
+	AuthenticateToken(ctx context.Context, token string) (principal *Principal, err error)
+	AuthenticateAPIKey(ctx context.Context, secret string) (principal *Principal, err error)
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"errors"
+	"time"
+	"todo-app/internal/errs"
+)
+
+var (
+	ErrTaskNeighbourNotFound = errs.New(errs.KindNotFound, "task_neighbour_not_found", "neighbour task not found")
+	ErrInvalidTaskNeighbours = errs.New(errs.KindValidation, "invalid_task_neighbours", "task neighbours are invalid")
+	ErrTaskMovedConcurrently = errs.New(errs.KindConflict, "task_moved_concurrently", "task status changed concurrently")
+)
+
+// Board lists the tasks in one column per status of the workflow, each
+// column is ordered by position
+type Board struct {
+	ProjectID *int64                      `json:"project_id"`
+	Columns   map[TaskStatus]*BoardColumn `json:"columns"`
+}
+
+// BoardColumn holds up to size tasks of a status, Count counts all of them
+type BoardColumn struct {
+	Status TaskStatus `json:"status"`
+	Count  int64      `json:"count"`
+	Tasks  []*Task    `json:"tasks"`
+}
+
+type GetBoardQueryParams struct {
+	ProjectID *int64 `query:"project_id"`
+	// Size limits the tasks of each column
+	Size int64 `query:"size" validate:"gte=0"`
+}
+
+type MoveBoardTaskInput struct {
+	TaskID int64      `json:"task_id"`
+	Status TaskStatus `json:"status"`
+	// BeforeID is the task the moved task is placed before, AfterID the task
+	// it is placed after, either one is enough
+	BeforeID *int64 `json:"before_id"`
+	AfterID  *int64 `json:"after_id"`
+}
+
+func (i MoveBoardTaskInput) Validate() error {
+	if i.TaskID == 0 {
+		return errors.New("task_id is required")
+	}
+	if i.Status == "" && i.BeforeID == nil && i.AfterID == nil {
+		return errors.New("status, before_id or after_id is required")
+	}
+	return nil
+}
+
+func (i MoveBoardTaskInput) ToModel() TaskMove {
+	return TaskMove{
+		ID:       i.TaskID,
+		Status:   i.Status,
+		BeforeID: i.BeforeID,
+		AfterID:  i.AfterID,
+	}
+}
+
+// TaskMove changes the status and the position of a task at once. An empty
+// Status keeps the status, leaving both neighbours empty keeps the position.
+type TaskMove struct {
+	ID       int64
+	Status   TaskStatus
+	BeforeID *int64
+	AfterID  *int64
+
+	// FromStatus, StartedAt and CompletedAt are filled by the usecase, the
+	// status only changes while the task is still in FromStatus
+	FromStatus  TaskStatus
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"todo-app/internal/errs"
+)
+
+type TaskOperationKind string
+
+const (
+	TaskOperationCreate   TaskOperationKind = "create"
+	TaskOperationUpdate   TaskOperationKind = "update"
+	TaskOperationDelete   TaskOperationKind = "delete"
+	TaskOperationComplete TaskOperationKind = "complete"
+)
+
+const (
+	// BulkModeAtomic applies every operation or none of them, BulkModePartial
+	// applies the operations which succeed and reports the others
+	BulkModeAtomic  = "atomic"
+	BulkModePartial = "partial"
+)
+
+var (
+	ErrInvalidOperation    = errs.New(errs.KindValidation, "invalid_operation", "operation is invalid")
+	ErrOperationNotApplied = errs.New(errs.KindFailedDependency, "operation_not_applied", "operation not applied, another operation failed")
+)
+
+// TaskOperation is a single write of a batch. Err is set when the operation
+// failed, Task holds the task as written once the batch is committed.
+type TaskOperation struct {
+	Kind TaskOperationKind
+	ID   int64
+	// Task is the created task or the replacement of the updated one
+	Task *Task
+	// Version is the version a deleted task is expected at, 0 for any
+	Version int64
+	Cascade bool
+	Err     error
+}
+
+type BulkTaskInput struct {
+	Mode       string                    `json:"mode"`
+	Operations []*BulkTaskOperationInput `json:"operations"`
+}
+
+func (i BulkTaskInput) Validate(maxOperations int) error {
+	switch i.Mode {
+	case "", BulkModeAtomic, BulkModePartial:
+	default:
+		return fmt.Errorf("mode %q is invalid", i.Mode)
+	}
+
+	if len(i.Operations) == 0 {
+		return errors.New("operations are required")
+	}
+	if len(i.Operations) > maxOperations {
+		return fmt.Errorf("at most %d operations are allowed", maxOperations)
+	}
+	return nil
+}
+
+// Atomic reports whether the batch is all or nothing, which is the default
+func (i BulkTaskInput) Atomic() bool {
+	return i.Mode != BulkModePartial
+}
+
+// ToModel converts every operation, an invalid operation carries its error
+// instead of failing the whole batch
+func (i BulkTaskInput) ToModel() []*TaskOperation {
+	operations := []*TaskOperation{}
+	for _, input := range i.Operations {
+		operation, err := input.ToModel()
+		if err != nil {
+			operation = &TaskOperation{Kind: input.Op, ID: input.ID, Err: err}
+		}
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+type BulkTaskOperationInput struct {
+	Op TaskOperationKind `json:"op"`
+	ID int64             `json:"id"`
+	// Version is the version an updated or deleted task is expected at
+	Version int64 `json:"version"`
+	Cascade bool  `json:"cascade"`
+	// Task is a CreateTaskInput for create and an UpdateTaskInput for update
+	Task json.RawMessage `json:"task"`
+}
+
+func (i BulkTaskOperationInput) ToModel() (*TaskOperation, error) {
+	operation := &TaskOperation{Kind: i.Op, ID: i.ID, Version: i.Version, Cascade: i.Cascade}
+
+	if i.Op != TaskOperationCreate && i.ID == 0 {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalidOperation)
+	}
+
+	switch i.Op {
+	case TaskOperationCreate:
+		input := CreateTaskInput{}
+		if err := json.Unmarshal(i.Task, &input); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOperation, err)
+		}
+		if err := input.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOperation, err)
+		}
+		operation.Task = input.ToModel()
+		operation.ID = operation.Task.ID
+	case TaskOperationUpdate:
+		input := UpdateTaskInput{}
+		if err := json.Unmarshal(i.Task, &input); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOperation, err)
+		}
+		if err := input.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOperation, err)
+		}
+		operation.Task = input.ToModel(i.ID)
+		operation.Task.Version = i.Version
+	case TaskOperationDelete, TaskOperationComplete:
+	default:
+		return nil, fmt.Errorf("%w: op %q is unknown", ErrInvalidOperation, i.Op)
+	}
+
+	return operation, nil
+}
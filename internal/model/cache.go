@@ -1,10 +1,18 @@
 package model
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type CacheRepository interface {
 	Get(ctx context.Context, key string) (reply string, err error)
 	Set(ctx context.Context, key, val string) (err error)
+	// SetEX sets the key expiring after ttl
+	SetEX(ctx context.Context, key, val string, ttl time.Duration) (err error)
+	// SetNX sets the key expiring after ttl unless it exists, ok tells
+	// whether it was set
+	SetNX(ctx context.Context, key, val string, ttl time.Duration) (ok bool, err error)
 	Delete(ctx context.Context, keys ...string) (err error)
 	HashGet(ctx context.Context, hash, key string) (reply string, err error)
 	HashSet(ctx context.Context, hash, key, val string) (err error)
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+	"todo-app/internal/utils"
+)
+
+type TaskEventKind string
+
+const (
+	TaskEventCreated   TaskEventKind = "created"
+	TaskEventUpdated   TaskEventKind = "updated"
+	TaskEventCompleted TaskEventKind = "completed"
+	TaskEventMoved     TaskEventKind = "moved"
+	TaskEventDeleted   TaskEventKind = "deleted"
+	TaskEventRestored  TaskEventKind = "restored"
+)
+
+// TaskFieldChange holds the value of a field before and after an event, From
+// is null for created tasks
+type TaskFieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// TaskChanges maps the json name of each changed field to its change
+type TaskChanges map[string]TaskFieldChange
+
+// TaskEvent is an entry of the history of a task, it is written in the same
+// transaction as the change it records
+type TaskEvent struct {
+	ID        int64         `json:"id"`
+	TaskID    int64         `json:"task_id"`
+	Kind      TaskEventKind `json:"kind"`
+	Actor     string        `json:"actor"`
+	Changes   TaskChanges   `json:"changes" gorm:"serializer:json"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// NewTaskEvent records a change of the task made by the actor of ctx
+func NewTaskEvent(ctx context.Context, taskID int64, kind TaskEventKind, changes TaskChanges) *TaskEvent {
+	if changes == nil {
+		changes = TaskChanges{}
+	}
+
+	return &TaskEvent{
+		ID:        utils.GenerateID(),
+		TaskID:    taskID,
+		Kind:      kind,
+		Actor:     ActorFromContext(ctx),
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+}
+
+type GetTaskHistoryQueryParams struct {
+	Pagination
+}
+
+// DiffTasks returns the audited fields which differ between before and
+// after. Without before every field set on after counts as changed.
+func DiffTasks(before, after *Task) TaskChanges {
+	changes := TaskChanges{}
+
+	to := after.auditedFields()
+	if before == nil {
+		for field, value := range to {
+			if !isZeroValue(value) {
+				changes[field] = TaskFieldChange{To: value}
+			}
+		}
+		return changes
+	}
+
+	from := before.auditedFields()
+	for field, value := range to {
+		if !reflect.DeepEqual(from[field], value) {
+			changes[field] = TaskFieldChange{From: from[field], To: value}
+		}
+	}
+	return changes
+}
+
+// auditedFields returns the fields the history keeps track of, times are
+// normalized to what the database stores so that they compare equal
+func (t *Task) auditedFields() map[string]interface{} {
+	tags := []string{}
+	for _, tag := range t.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(tags)
+
+	return map[string]interface{}{
+		"title":      t.Title,
+		"todo":       t.Todo,
+		"status":     t.Status,
+		"priority":   t.Priority,
+		"position":   t.Position,
+		"due_at":     auditedTime(t.DueAt),
+		"remind_at":  auditedTime(t.RemindAt),
+		"project_id": t.ProjectID,
+		"parent_id":  t.ParentID,
+		"tags":       tags,
+	}
+}
+
+func auditedTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	normalized := t.UTC().Truncate(time.Microsecond)
+	return &normalized
+}
+
+func isZeroValue(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffTasks(t *testing.T) {
+	dueAt := time.Date(2025, 3, 1, 17, 0, 0, 0, time.UTC)
+	projectID := int64(7)
+	newTask := func() *Task {
+		return &Task{
+			ID:        1,
+			Title:     "write report",
+			Status:    TaskStatusTodo,
+			Priority:  TaskPriorityHigh,
+			Position:  "V",
+			DueAt:     &dueAt,
+			ProjectID: &projectID,
+			Tags:      []*Tag{{ID: 10, Name: "work"}, {ID: 11, Name: "urgent"}},
+			Version:   3,
+			CreatedAt: dueAt,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(task *Task)
+		want   TaskChanges
+	}{
+		{
+			name:   "unchanged",
+			change: func(task *Task) {},
+			want:   TaskChanges{},
+		},
+		{
+			name:   "untracked fields",
+			change: func(task *Task) { task.Version, task.UpdatedAt = 4, time.Now() },
+			want:   TaskChanges{},
+		},
+		{
+			name:   "title",
+			change: func(task *Task) { task.Title = "edit report" },
+			want:   TaskChanges{"title": {From: "write report", To: "edit report"}},
+		},
+		{
+			name:   "status and position",
+			change: func(task *Task) { task.Status, task.Position = TaskStatusInProgress, "k" },
+			want: TaskChanges{
+				"status":   {From: TaskStatusTodo, To: TaskStatusInProgress},
+				"position": {From: "V", To: "k"},
+			},
+		},
+		{
+			name: "same instant in another zone",
+			change: func(task *Task) {
+				dueAt := dueAt.In(time.FixedZone("CET", 3600))
+				task.DueAt = &dueAt
+			},
+			want: TaskChanges{},
+		},
+		{
+			name: "below the precision of the database",
+			change: func(task *Task) {
+				dueAt := dueAt.Add(time.Nanosecond)
+				task.DueAt = &dueAt
+			},
+			want: TaskChanges{},
+		},
+		{
+			name:   "cleared project",
+			change: func(task *Task) { task.ProjectID = nil },
+			want:   TaskChanges{"project_id": {From: &projectID, To: (*int64)(nil)}},
+		},
+		{
+			name:   "tags in another order",
+			change: func(task *Task) { task.Tags[0], task.Tags[1] = task.Tags[1], task.Tags[0] },
+			want:   TaskChanges{},
+		},
+		{
+			name:   "tag added",
+			change: func(task *Task) { task.Tags = append(task.Tags, &Tag{Name: "home"}) },
+			want: TaskChanges{"tags": {
+				From: []string{"urgent", "work"},
+				To:   []string{"home", "urgent", "work"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := newTask()
+			tt.change(after)
+
+			if got := DiffTasks(newTask(), after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffTasks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffTasksCreated(t *testing.T) {
+	task := &Task{ID: 1, Title: "write report", Status: TaskStatusTodo, Position: "V"}
+
+	want := TaskChanges{
+		"title":    {To: "write report"},
+		"status":   {To: TaskStatusTodo},
+		"position": {To: "V"},
+	}
+	if got := DiffTasks(nil, task); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffTasks(nil) = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewTaskEventActor(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantActor string
+	}{
+		{name: "system", ctx: context.Background()},
+		{
+			name:      "principal",
+			ctx:       ContextWithPrincipal(context.Background(), &Principal{Kind: PrincipalToken, Subject: "alice"}),
+			wantActor: "alice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := NewTaskEvent(tt.ctx, 1, TaskEventUpdated, nil)
+			if event.Actor != tt.wantActor {
+				t.Errorf("actor = %q, want %q", event.Actor, tt.wantActor)
+			}
+			if event.Changes == nil {
+				t.Error("changes are nil, want an empty object")
+			}
+		})
+	}
+}
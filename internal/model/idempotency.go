@@ -0,0 +1,47 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"todo-app/internal/errs"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errs.New(errs.KindUnprocessable, "idempotency_key_reused", "idempotency key was used for another request")
+	ErrIdempotencyKeyInProgress = errs.New(errs.KindConflict, "idempotency_key_in_progress", "a request with this idempotency key is in progress")
+)
+
+// IdempotentResponse is the stored outcome of a request made with an
+// idempotency key, Body is empty while the request is in progress
+type IdempotentResponse struct {
+	RequestHash string          `json:"request_hash"`
+	Status      int             `json:"status"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// Done reports whether the request finished, an in progress request only
+// holds the key
+func (r *IdempotentResponse) Done() bool {
+	return r.Status != 0
+}
+
+// HashRequest hashes the bound request input, so that requests differing
+// only in formatting or key order share a hash
+func HashRequest(input interface{}) (string, error) {
+	bytes, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type IdempotencyUsecase interface {
+	// Do runs fn once per scope and key. A repeated request with the same
+	// hash gets the stored response with replayed set, one with another hash
+	// fails with ErrIdempotencyKeyReused. Failures of fn are not stored.
+	Do(ctx context.Context, scope, key, requestHash string, fn func(ctx context.Context) (status int, body interface{}, err error)) (response *IdempotentResponse, replayed bool, err error)
+}
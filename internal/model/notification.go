@@ -0,0 +1,30 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type NotificationKind string
+
+const (
+	// NotificationKindReminder fires once a task crosses its remind_at
+	NotificationKindReminder NotificationKind = "reminder"
+	// NotificationKindDue fires once a task crosses its due_at
+	NotificationKindDue NotificationKind = "due"
+)
+
+type Notification struct {
+	Kind    NotificationKind `json:"kind"`
+	Task    *Task            `json:"task"`
+	FiredAt time.Time        `json:"fired_at"`
+}
+
+// Notifier is a sink that delivers notifications, e.g. log, webhook or smtp
+type Notifier interface {
+	Notify(ctx context.Context, notification *Notification) (err error)
+}
+
+type ReminderUsecase interface {
+	DispatchDue(ctx context.Context) (dispatched int, err error)
+}
@@ -1,7 +1,11 @@
 package model
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"math"
+	"todo-app/internal/errs"
 )
 
 type PaginationResponse struct {
@@ -12,7 +16,10 @@ type PaginationResponse struct {
 }
 
 func NewPaginationResponse(data interface{}, page, size, dataCount int64) PaginationResponse {
-	totalPages := math.Ceil(float64(dataCount) / float64(size))
+	totalPages := float64(0)
+	if size > 0 {
+		totalPages = math.Ceil(float64(dataCount) / float64(size))
+	}
 	return PaginationResponse{
 		Data:       data,
 		Page:       page,
@@ -21,6 +28,32 @@ func NewPaginationResponse(data interface{}, page, size, dataCount int64) Pagina
 	}
 }
 
+// Pagination holds the page and size query params of the listings
+type Pagination struct {
+	Page int64 `query:"page" validate:"gte=0"`
+	Size int64 `query:"size" validate:"gte=0"`
+}
+
+// Paginate starts a missing page at the first one and defaults a missing
+// size to defaultSize, sizes above maxSize are invalid
+func (p *Pagination) Paginate(defaultSize, maxSize int64) error {
+	if p.Page == 0 {
+		p.Page = 1
+	}
+	if p.Size == 0 {
+		p.Size = defaultSize
+	}
+
+	if p.Size > maxSize {
+		return errs.ErrValidation.WithFields(errs.FieldError{
+			Field:   "size",
+			Rule:    "lte",
+			Message: fmt.Sprintf("size must be at most %d", maxSize),
+		})
+	}
+	return nil
+}
+
 func Offset(page, size int64) int64 {
 	offset := (page - 1) * size
 	if offset < 0 {
@@ -28,3 +61,54 @@ func Offset(page, size int64) int64 {
 	}
 	return offset
 }
+
+type CursorPaginationResponse struct {
+	Data       interface{} `json:"data"`
+	Size       int64       `json:"size"`
+	NextCursor string      `json:"next_cursor"`
+	PrevCursor string      `json:"prev_cursor"`
+}
+
+func NewCursorPaginationResponse(data interface{}, size int64, nextCursor, prevCursor string) CursorPaginationResponse {
+	return CursorPaginationResponse{
+		Data:       data,
+		Size:       size,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}
+}
+
+// Cursor is the keyset position of a row, Value holds the row's sort key
+// and ID breaks ties. Backward cursors page towards the start of the list.
+type Cursor struct {
+	Sort     string `json:"s"`
+	Value    string `json:"v"`
+	ID       int64  `json:"id"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+var ErrInvalidCursor = errs.New(errs.KindValidation, "invalid_cursor", "cursor is invalid")
+
+// EncodeCursor encodes c into an opaque url safe string
+func EncodeCursor(c Cursor) string {
+	bt, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(bt)
+}
+
+// DecodeCursor decodes a string produced by EncodeCursor
+func DecodeCursor(in string) (*Cursor, error) {
+	bt, err := base64.RawURLEncoding.DecodeString(in)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(bt, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
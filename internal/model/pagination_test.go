@@ -0,0 +1,137 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"todo-app/internal/errs"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	dueAt := time.Date(2025, 3, 1, 17, 30, 0, 123456789, time.UTC)
+	task := &Task{
+		ID:        42,
+		Title:     "write, report",
+		CreatedAt: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+		DueAt:     &dueAt,
+		Priority:  TaskPriorityHigh,
+		Position:  "0000002aV",
+	}
+
+	tests := []struct {
+		name      string
+		sort      string
+		backward  bool
+		wantSort  string
+		wantValue string
+	}{
+		{name: "default sort", wantSort: "id", wantValue: "42"},
+		{name: "unknown sort falls back to id", sort: "owner", wantSort: "id", wantValue: "42"},
+		{name: "title", sort: "title", wantSort: "title", wantValue: "write, report"},
+		{name: "created_at", sort: "created_at", wantSort: "created_at", wantValue: "2025-01-06T09:00:00Z"},
+		{name: "due_date", sort: "due_date", wantSort: "due_date", wantValue: "2025-03-01T17:30:00.123456789Z"},
+		{name: "position", sort: "position", wantSort: "position", wantValue: "0000002aV"},
+		{name: "backward", sort: "title", backward: true, wantSort: "title", wantValue: "write, report"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := GetTasksQueryParams{Sort: tt.sort}
+			query.Cursor = query.CursorOf(task, tt.backward)
+
+			cursor, err := query.DecodeCursor()
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+
+			want := Cursor{Sort: tt.wantSort, Value: tt.wantValue, ID: task.ID, Backward: tt.backward}
+			if *cursor != want {
+				t.Errorf("DecodeCursor() = %+v, want %+v", *cursor, want)
+			}
+		})
+	}
+}
+
+func TestTaskSortValueWithoutDueDate(t *testing.T) {
+	if got := (&Task{}).SortValue("due_date"); got != "infinity" {
+		t.Errorf("SortValue(due_date) = %q, want infinity", got)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{name: "not base64", cursor: "%%%"},
+		{name: "not json", cursor: "bm90IGpzb24"},
+		{name: "other sort field", cursor: EncodeCursor(Cursor{Sort: "title", Value: "a", ID: 1}), sort: "created_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := GetTasksQueryParams{Sort: tt.sort, Cursor: tt.cursor}.DecodeCursor()
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor() = %+v, %v, want %v", cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorEmpty(t *testing.T) {
+	cursor, err := GetTasksQueryParams{}.DecodeCursor()
+	if cursor != nil || err != nil {
+		t.Errorf("DecodeCursor() = %+v, %v, want nil, nil", cursor, err)
+	}
+}
+
+func TestPaginationPaginate(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    Pagination
+		want    Pagination
+		wantErr bool
+	}{
+		{name: "defaults", want: Pagination{Page: 1, Size: 20}},
+		{name: "given", page: Pagination{Page: 3, Size: 5}, want: Pagination{Page: 3, Size: 5}},
+		{name: "at the max size", page: Pagination{Page: 1, Size: 100}, want: Pagination{Page: 1, Size: 100}},
+		{name: "past the max size", page: Pagination{Page: 1, Size: 101}, want: Pagination{Page: 1, Size: 101}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := tt.page
+			err := page.Paginate(20, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Paginate() error = %v, want error %t", err, tt.wantErr)
+			}
+			if page != tt.want {
+				t.Errorf("Paginate() = %+v, want %+v", page, tt.want)
+			}
+			if tt.wantErr {
+				fields := errs.From(err).Fields
+				if len(fields) != 1 || fields[0].Field != "size" || fields[0].Rule != "lte" {
+					t.Errorf("Paginate() fields = %+v, want size/lte", fields)
+				}
+			}
+		})
+	}
+}
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		page, size, want int64
+	}{
+		{page: 1, size: 10, want: 0},
+		{page: 3, size: 10, want: 20},
+		{page: 0, size: 10, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := Offset(tt.page, tt.size); got != tt.want {
+			t.Errorf("Offset(%d, %d) = %d, want %d", tt.page, tt.size, got, tt.want)
+		}
+	}
+}
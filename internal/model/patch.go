@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"todo-app/internal/errs"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var ErrInvalidPatch = errs.New(errs.KindValidation, "invalid_patch", "patch is invalid")
+
+// TaskPatch is either a JSON Merge Patch (RFC 7396) or a JSON Patch
+// (RFC 6902) document, as told by its content type
+type TaskPatch struct {
+	ContentType string
+	Body        []byte
+	// Version is the version the patch expects the task at, 0 for any
+	Version int64
+}
+
+// Apply applies the patch to the representation i. Explicit nulls clear a
+// field and false is applied like any other value.
+func (i UpdateTaskInput) Apply(patch TaskPatch) (UpdateTaskInput, error) {
+	doc, err := json.Marshal(i)
+	if err != nil {
+		return i, err
+	}
+
+	switch patch.ContentType {
+	case JSONPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch.Body)
+		if err != nil {
+			return i, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+		doc, err = operations.Apply(doc)
+		if err != nil {
+			return i, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+	default:
+		doc, err = jsonpatch.MergePatch(doc, patch.Body)
+		if err != nil {
+			return i, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+	}
+
+	patched := UpdateTaskInput{}
+	if err := json.Unmarshal(doc, &patched); err != nil {
+		return i, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+
+	// patching completed alone moves the status like older clients expect
+	if patched.Completed != i.Completed && patched.Status == i.Status {
+		patched.Status = ""
+	}
+
+	return patched, nil
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUpdateTaskInputApply(t *testing.T) {
+	dueAt := time.Date(2025, 3, 1, 17, 0, 0, 0, time.UTC)
+	projectID := int64(7)
+	newInput := func() UpdateTaskInput {
+		return UpdateTaskInput{
+			Title:     "write report",
+			Todo:      "draft first",
+			Completed: true,
+			Status:    TaskStatusDone,
+			Priority:  TaskPriorityHigh,
+			DueAt:     &dueAt,
+			ProjectID: &projectID,
+			Tags:      []string{"work"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		change      func(i *UpdateTaskInput)
+		wantErr     error
+	}{
+		{
+			name:   "empty merge patch",
+			body:   `{}`,
+			change: func(i *UpdateTaskInput) {},
+		},
+		{
+			name:   "merge patch of a field",
+			body:   `{"title":"edit report"}`,
+			change: func(i *UpdateTaskInput) { i.Title = "edit report" },
+		},
+		{
+			name:   "merge patch null clears",
+			body:   `{"due_at":null,"project_id":null,"tags":null}`,
+			change: func(i *UpdateTaskInput) { i.DueAt, i.ProjectID, i.Tags = nil, nil, nil },
+		},
+		{
+			name:   "merge patch false reopens",
+			body:   `{"completed":false}`,
+			change: func(i *UpdateTaskInput) { i.Completed, i.Status = false, "" },
+		},
+		{
+			name:   "merge patch of completed and status",
+			body:   `{"completed":false,"status":"in_review"}`,
+			change: func(i *UpdateTaskInput) { i.Completed, i.Status = false, TaskStatusInReview },
+		},
+		{
+			name:        "json patch replace",
+			contentType: JSONPatchContentType,
+			body:        `[{"op":"replace","path":"/priority","value":"low"}]`,
+			change:      func(i *UpdateTaskInput) { i.Priority = TaskPriorityLow },
+		},
+		{
+			name:        "json patch add to a list",
+			contentType: JSONPatchContentType,
+			body:        `[{"op":"add","path":"/tags/-","value":"urgent"}]`,
+			change:      func(i *UpdateTaskInput) { i.Tags = []string{"work", "urgent"} },
+		},
+		{
+			name:        "json patch remove",
+			contentType: JSONPatchContentType,
+			body:        `[{"op":"remove","path":"/todo"}]`,
+			change:      func(i *UpdateTaskInput) { i.Todo = "" },
+		},
+		{
+			name:        "json patch failed test",
+			contentType: JSONPatchContentType,
+			body:        `[{"op":"test","path":"/title","value":"other"},{"op":"replace","path":"/title","value":"x"}]`,
+			wantErr:     ErrInvalidPatch,
+		},
+		{
+			name:        "json patch of a missing path",
+			contentType: JSONPatchContentType,
+			body:        `[{"op":"replace","path":"/owner","value":1}]`,
+			wantErr:     ErrInvalidPatch,
+		},
+		{
+			name:        "malformed json patch",
+			contentType: JSONPatchContentType,
+			body:        `{"op":"replace"}`,
+			wantErr:     ErrInvalidPatch,
+		},
+		{
+			name:    "malformed merge patch",
+			body:    `{"title":`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "merge patch of the wrong type",
+			body:    `{"completed":"yes"}`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = MergePatchContentType
+			}
+
+			got, err := newInput().Apply(TaskPatch{ContentType: contentType, Body: []byte(tt.body)})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			want := newInput()
+			tt.change(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"context"
+	"errors"
+)
+
+type UpdateTaskPositionInput struct {
+	// BeforeID is the task the moved task is placed before, AfterID the task
+	// it is placed after, either one is enough
+	BeforeID *int64 `json:"before_id"`
+	AfterID  *int64 `json:"after_id"`
+}
+
+func (i UpdateTaskPositionInput) Validate() error {
+	if i.BeforeID == nil && i.AfterID == nil {
+		return errors.New("before_id or after_id is required")
+	}
+	return nil
+}
+
+func (i UpdateTaskPositionInput) ToModel(ID int64) TaskMove {
+	return TaskMove{
+		ID:       ID,
+		BeforeID: i.BeforeID,
+		AfterID:  i.AfterID,
+	}
+}
+
+type PositionUsecase interface {
+	// Rebalance renormalizes the positions once the longest one exceeds the
+	// configured length, rebalanced is the number of repositioned tasks
+	Rebalance(ctx context.Context) (rebalanced int64, err error)
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"todo-app/internal/errs"
+)
+
+type TaskPriority string
+
+const (
+	TaskPriorityNone   TaskPriority = "none"
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityMedium TaskPriority = "medium"
+	TaskPriorityHigh   TaskPriority = "high"
+	TaskPriorityUrgent TaskPriority = "urgent"
+)
+
+var taskPriorityLevels = map[TaskPriority]int{
+	TaskPriorityNone:   0,
+	TaskPriorityLow:    1,
+	TaskPriorityMedium: 2,
+	TaskPriorityHigh:   3,
+	TaskPriorityUrgent: 4,
+}
+
+// TaskPriorityLevelColumn maps the priority column to its level so that
+// Postgres can sort and score by it
+const TaskPriorityLevelColumn = "(CASE priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 ELSE 0 END)"
+
+// Validate accepts the known priorities, an empty priority is left to the
+// caller to default or ignore
+func (p TaskPriority) Validate() error {
+	if p == "" {
+		return nil
+	}
+
+	if _, ok := taskPriorityLevels[p]; !ok {
+		return errs.ErrValidation.WithFields(oneOfField("priority", []string{
+			string(TaskPriorityNone),
+			string(TaskPriorityLow),
+			string(TaskPriorityMedium),
+			string(TaskPriorityHigh),
+			string(TaskPriorityUrgent),
+		}))
+	}
+	return nil
+}
+
+// Level returns the numeric level of the priority, from 0 for none to 4 for
+// urgent
+func (p TaskPriority) Level() int {
+	return taskPriorityLevels[p]
+}
+
+// TaskScoreWeights weights the components of the next up score.
+//
+// The score of an open task at now is
+//
+//	Priority * level + Due * urgency + Age * age
+//
+// where level is the priority level from 0 (none) to 4 (urgent), urgency is
+// 1 / (1 + days until due), clamped to 1 once the task is overdue and 0 when
+// it has no due date, and age is the number of days since the task was
+// created. Tasks are ranked by descending score, ties go to the older task.
+type TaskScoreWeights struct {
+	Priority float64
+	Due      float64
+	Age      float64
+}
+
+// Score computes the score of task at now, like ScoreColumn does in SQL
+func (w TaskScoreWeights) Score(task *Task, now time.Time) float64 {
+	urgency := 0.0
+	if task.DueAt != nil {
+		urgency = 1 / (1 + math.Max(task.DueAt.Sub(now).Hours()/24, 0))
+	}
+	age := math.Max(now.Sub(task.CreatedAt).Hours()/24, 0)
+
+	return w.Priority*float64(task.Priority.Level()) + w.Due*urgency + w.Age*age
+}
+
+// ScoreColumn returns the SQL expression of the score, now is bound as the
+// only parameter
+func (w TaskScoreWeights) ScoreColumn() string {
+	return strings.Join([]string{
+		fmt.Sprintf("%f * %s", w.Priority, TaskPriorityLevelColumn),
+		fmt.Sprintf("%f * COALESCE(1 / (1 + GREATEST(EXTRACT(EPOCH FROM (due_at - @now)) / 86400, 0)), 0)", w.Due),
+		fmt.Sprintf("%f * GREATEST(EXTRACT(EPOCH FROM (@now - created_at)) / 86400, 0)", w.Age),
+	}, " + ")
+}
+
+// NextTasksQuery selects the open tasks ranked by their score at Now
+type NextTasksQuery struct {
+	Now     time.Time
+	Weights TaskScoreWeights
+	Page    int64
+	Size    int64
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"todo-app/internal/errs"
+)
+
+// rankTasks orders the tasks like GET /tasks/next does
+func rankTasks(weights TaskScoreWeights, tasks []*Task, now time.Time) []string {
+	ranked := slices.Clone(tasks)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, sj := weights.Score(ranked[i], now), weights.Score(ranked[j], now)
+		if si != sj {
+			return si > sj
+		}
+		return ranked[i].CreatedAt.Before(ranked[j].CreatedAt)
+	})
+
+	titles := []string{}
+	for _, task := range ranked {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func TestTaskScoreWeightsRanking(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tasks := []*Task{
+		{Title: "plain", CreatedAt: now.Add(-10 * 24 * time.Hour)},
+		{Title: "due later", Priority: TaskPriorityLow, DueAt: at(10 * 24 * time.Hour), CreatedAt: now.Add(-time.Hour)},
+		{Title: "due soon", DueAt: at(12 * time.Hour), CreatedAt: now.Add(-2 * time.Hour)},
+		{Title: "urgent", Priority: TaskPriorityUrgent, CreatedAt: now},
+		{Title: "overdue", DueAt: at(-24 * time.Hour), CreatedAt: now.Add(-24 * time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		weights TaskScoreWeights
+		want    []string
+	}{
+		{
+			name:    "default weights",
+			weights: TaskScoreWeights{Priority: 1, Due: 4, Age: 0.1},
+			want:    []string{"overdue", "urgent", "due soon", "due later", "plain"},
+		},
+		{
+			name:    "priority first",
+			weights: TaskScoreWeights{Priority: 4, Due: 1, Age: 0.1},
+			want:    []string{"urgent", "due later", "overdue", "plain", "due soon"},
+		},
+		{
+			name:    "age only",
+			weights: TaskScoreWeights{Age: 1},
+			want:    []string{"plain", "overdue", "due soon", "due later", "urgent"},
+		},
+		{
+			name:    "no weights rank the older task first",
+			weights: TaskScoreWeights{},
+			want:    []string{"plain", "overdue", "due soon", "due later", "urgent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rankTasks(tt.weights, tasks, now); !slices.Equal(got, tt.want) {
+				t.Errorf("ranking = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskScoreWeightsScore(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	weights := TaskScoreWeights{Priority: 1, Due: 4, Age: 0.1}
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name string
+		task *Task
+		want float64
+	}{
+		{name: "nothing", task: &Task{CreatedAt: now}, want: 0},
+		{name: "priority level", task: &Task{Priority: TaskPriorityHigh, CreatedAt: now}, want: 3},
+		{name: "due now", task: &Task{DueAt: at(0), CreatedAt: now}, want: 4},
+		{name: "overdue is clamped", task: &Task{DueAt: at(-72 * time.Hour), CreatedAt: now}, want: 4},
+		{name: "due in a day", task: &Task{DueAt: at(24 * time.Hour), CreatedAt: now}, want: 2},
+		{name: "two days old", task: &Task{CreatedAt: now.Add(-48 * time.Hour)}, want: 0.2},
+		{name: "created in the future", task: &Task{CreatedAt: now.Add(time.Hour)}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weights.Score(tt.task, now); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("Score() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskPriorityValidate(t *testing.T) {
+	tests := []struct {
+		priority TaskPriority
+		wantErr  bool
+	}{
+		{priority: ""},
+		{priority: TaskPriorityNone},
+		{priority: TaskPriorityUrgent},
+		{priority: "critical", wantErr: true},
+		{priority: "Urgent", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.priority), func(t *testing.T) {
+			err := tt.priority.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+
+			fields := errs.From(err).Fields
+			if !errors.Is(err, errs.ErrValidation) || len(fields) != 1 || fields[0].Field != "priority" || fields[0].Rule != "oneof" {
+				t.Errorf("Validate() error = %v with fields %+v, want a priority/oneof field error", err, fields)
+			}
+		})
+	}
+}
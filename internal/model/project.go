@@ -0,0 +1,79 @@
+package model
+
+import (
+	"context"
+	"time"
+	"todo-app/internal/errs"
+	"todo-app/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProjectNotFound    = errs.New(errs.KindNotFound, "project_not_found", "project not found")
+	ErrTaskProjectInvalid = errs.New(errs.KindValidation, "task_project_invalid", "project of the task does not exist")
+)
+
+type Project struct {
+	ID            int64          `json:"id"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	TaskCount     int64          `json:"task_count" gorm:"-"`
+	OpenTaskCount int64          `json:"open_task_count" gorm:"-"`
+	WorkspaceID   *int64         `json:"workspace_id"`
+	OwnerID       *int64         `json:"owner_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at"`
+}
+
+type CreateProjectInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (i CreateProjectInput) ToModel() *Project {
+	return &Project{
+		ID:          utils.GenerateID(),
+		Name:        i.Name,
+		Description: i.Description,
+		CreatedAt:   time.Now(),
+	}
+}
+
+type UpdateProjectInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (i UpdateProjectInput) ToModel(ID int64) *Project {
+	return &Project{
+		ID:          ID,
+		Name:        i.Name,
+		Description: i.Description,
+		UpdatedAt:   time.Now(),
+	}
+}
+
+type GetProjectsQueryParams struct {
+	Pagination
+}
+
+type ProjectRepository interface {
+	Create(ctx context.Context, input *Project) (err error)
+	// DeleteByID keeps the tasks of the project, which no longer belong
+	// to a project afterwards
+	DeleteByID(ctx context.Context, ID int64) (err error)
+	FindByID(ctx context.Context, ID int64) (project *Project, err error)
+	FindAll(ctx context.Context, query GetProjectsQueryParams) (projects []*Project, err error)
+	CountAll(ctx context.Context) (count int64, err error)
+	Update(ctx context.Context, input *Project) (project *Project, err error)
+}
+
+type ProjectUsecase interface {
+	Create(ctx context.Context, input *Project) (project *Project, err error)
+	DeleteByID(ctx context.Context, ID int64) (err error)
+	FindByID(ctx context.Context, ID int64) (project *Project, err error)
+	FindAll(ctx context.Context, query GetProjectsQueryParams) (projects []*Project, count int64, err error)
+	Update(ctx context.Context, input *Project) (project *Project, err error)
+}
@@ -0,0 +1,278 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/errs"
+)
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// maxRecurrenceSteps bounds the search for the next occurrence so that a rule
+// which can never match, e.g. BYMONTHDAY=31 on FREQ=MONTHLY;INTERVAL=12 in a
+// short month, does not loop forever
+const maxRecurrenceSteps = 1000
+
+var ErrInvalidRecurrenceRule = errs.New(errs.KindValidation, "invalid_recurrence_rule", "recurrence rule is invalid")
+
+var recurrenceShorthands = map[string]string{
+	"daily":   "FREQ=DAILY",
+	"weekly":  "FREQ=WEEKLY",
+	"monthly": "FREQ=MONTHLY",
+	"yearly":  "FREQ=YEARLY",
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RecurrenceRule is the supported subset of RFC 5545 RRULE: FREQ, INTERVAL,
+// BYDAY (without ordinals), BYMONTHDAY, COUNT and UNTIL
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// ParseRecurrenceRule parses an RRULE such as FREQ=WEEKLY;BYDAY=MO,WE or one
+// of the daily, weekly, monthly and yearly shorthands
+func ParseRecurrenceRule(in string) (*RecurrenceRule, error) {
+	in = strings.TrimSpace(in)
+	if shorthand, ok := recurrenceShorthands[strings.ToLower(in)]; ok {
+		in = shorthand
+	}
+	in = strings.TrimPrefix(strings.ToUpper(in), "RRULE:")
+
+	rule := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(in, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRecurrenceRule, part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				rule.Freq = value
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrenceRule, value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL %q", ErrInvalidRecurrenceRule, value)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: COUNT %q", ErrInvalidRecurrenceRule, value)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL %q", ErrInvalidRecurrenceRule, value)
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrenceRule, day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY %q", ErrInvalidRecurrenceRule, day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRecurrenceRule, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrenceRule)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are exclusive", ErrInvalidRecurrenceRule)
+	}
+
+	return rule, nil
+}
+
+func parseRRuleTime(in string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, in); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidRecurrenceRule
+}
+
+// String returns the normalized RRULE
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := []string{}
+		for _, weekday := range r.ByDay {
+			for name, day := range rruleWeekdays {
+				if day == weekday {
+					days = append(days, name)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := []string{}
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after the previous one, the
+// time of day of previous is kept. occurrences is the number of occurrences
+// generated so far and is checked against COUNT, false is returned once the
+// series is exhausted.
+func (r *RecurrenceRule) Next(previous time.Time, occurrences int) (time.Time, bool) {
+	if r.Count > 0 && occurrences >= r.Count {
+		return time.Time{}, false
+	}
+
+	next, ok := r.next(previous)
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+func (r *RecurrenceRule) next(previous time.Time) (time.Time, bool) {
+	switch r.Freq {
+	case FreqDaily:
+		for i := 1; i <= maxRecurrenceSteps; i++ {
+			candidate := previous.AddDate(0, 0, i*r.Interval)
+			if r.matchesDay(candidate) {
+				return candidate, true
+			}
+		}
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return previous.AddDate(0, 0, 7*r.Interval), true
+		}
+		weekStart := startOfWeek(previous)
+		for i := 1; i <= maxRecurrenceSteps; i++ {
+			candidate := previous.AddDate(0, 0, i)
+			weeks := int(startOfWeek(candidate).Sub(weekStart).Hours()/24+0.5) / 7
+			if weeks%r.Interval == 0 && r.matchesDay(candidate) {
+				return candidate, true
+			}
+		}
+	case FreqMonthly:
+		for i := 0; i <= maxRecurrenceSteps; i += r.Interval {
+			year, month := previous.Year(), previous.Month()+time.Month(i)
+			for _, candidate := range r.monthCandidates(previous, year, month) {
+				if candidate.After(previous) && r.matchesDay(candidate) {
+					return candidate, true
+				}
+			}
+		}
+	case FreqYearly:
+		for i := r.Interval; i <= maxRecurrenceSteps; i += r.Interval {
+			candidate := time.Date(previous.Year()+i, previous.Month(), previous.Day(),
+				previous.Hour(), previous.Minute(), previous.Second(), previous.Nanosecond(), previous.Location())
+			// Feb 29 only occurs in leap years
+			if candidate.Day() == previous.Day() {
+				return candidate, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// monthCandidates returns the BYMONTHDAY dates, or the day of month of
+// previous, within the month in ascending order. Days that do not exist in
+// the month are skipped as RFC 5545 requires.
+func (r *RecurrenceRule) monthCandidates(previous time.Time, year int, month time.Month) []time.Time {
+	monthDays := r.ByMonthDay
+	if len(monthDays) == 0 {
+		monthDays = []int{previous.Day()}
+	}
+
+	first := time.Date(year, month, 1, previous.Hour(), previous.Minute(), previous.Second(), previous.Nanosecond(), previous.Location())
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+
+	candidates := []time.Time{}
+	for _, monthDay := range monthDays {
+		day := monthDay
+		if day < 0 {
+			day = daysInMonth + day + 1
+		}
+		if day < 1 || day > daysInMonth {
+			continue
+		}
+		candidates = append(candidates, first.AddDate(0, 0, day-1))
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+
+	return candidates
+}
+
+func (r *RecurrenceRule) matchesDay(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if t.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfWeek returns midnight of the monday starting t's week, the RFC 5545
+// default WKST
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
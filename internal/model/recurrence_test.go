@@ -0,0 +1,100 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "shorthand", in: "weekly", want: "FREQ=WEEKLY"},
+		{name: "lower case", in: "freq=daily;interval=2", want: "FREQ=DAILY;INTERVAL=2"},
+		{name: "rrule prefix", in: "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,-1", want: "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+		{name: "by day and count", in: "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=4", want: "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=4"},
+		{name: "until date", in: "FREQ=DAILY;UNTIL=20250131", want: "FREQ=DAILY;UNTIL=20250131T000000Z"},
+		{name: "interval of one is implied", in: "FREQ=YEARLY;INTERVAL=1", want: "FREQ=YEARLY"},
+		{name: "empty", in: "", wantErr: true},
+		{name: "missing freq", in: "INTERVAL=2", wantErr: true},
+		{name: "unsupported freq", in: "FREQ=HOURLY", wantErr: true},
+		{name: "zero interval", in: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "zero count", in: "FREQ=DAILY;COUNT=0", wantErr: true},
+		{name: "count and until", in: "FREQ=DAILY;COUNT=2;UNTIL=20250101", wantErr: true},
+		{name: "by day ordinal", in: "FREQ=MONTHLY;BYDAY=1MO", wantErr: true},
+		{name: "month day out of range", in: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{name: "month day zero", in: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: true},
+		{name: "unsupported part", in: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{name: "part without value", in: "FREQ=DAILY;COUNT=", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRecurrenceRule) {
+					t.Fatalf("ParseRecurrenceRule(%q) error = %v, want %v", tt.in, err, ErrInvalidRecurrenceRule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) error = %v", tt.in, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("ParseRecurrenceRule(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	// 2025-01-06 is a monday
+	tests := []struct {
+		name        string
+		rule        string
+		previous    time.Time
+		occurrences int
+		want        time.Time
+		wantOK      bool
+	}{
+		{name: "daily", rule: "FREQ=DAILY", previous: at(2025, 1, 6), want: at(2025, 1, 7), wantOK: true},
+		{name: "every other day", rule: "FREQ=DAILY;INTERVAL=2", previous: at(2025, 1, 6), want: at(2025, 1, 8), wantOK: true},
+		{name: "daily on weekdays skips the weekend", rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", previous: at(2025, 1, 10), want: at(2025, 1, 13), wantOK: true},
+		{name: "weekly", rule: "FREQ=WEEKLY", previous: at(2025, 1, 6), want: at(2025, 1, 13), wantOK: true},
+		{name: "weekly by day within the week", rule: "FREQ=WEEKLY;BYDAY=MO,WE", previous: at(2025, 1, 6), want: at(2025, 1, 8), wantOK: true},
+		{name: "weekly by day into the next week", rule: "FREQ=WEEKLY;BYDAY=MO,WE", previous: at(2025, 1, 8), want: at(2025, 1, 13), wantOK: true},
+		{name: "every other week by day", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", previous: at(2025, 1, 6), want: at(2025, 1, 20), wantOK: true},
+		{name: "monthly", rule: "FREQ=MONTHLY", previous: at(2025, 1, 15), want: at(2025, 2, 15), wantOK: true},
+		{name: "monthly skips short months", rule: "FREQ=MONTHLY", previous: at(2025, 1, 31), want: at(2025, 3, 31), wantOK: true},
+		{name: "monthly on the last day", rule: "FREQ=MONTHLY;BYMONTHDAY=-1", previous: at(2025, 1, 31), want: at(2025, 2, 28), wantOK: true},
+		{name: "monthly on several days", rule: "FREQ=MONTHLY;BYMONTHDAY=1,15", previous: at(2025, 1, 1), want: at(2025, 1, 15), wantOK: true},
+		{name: "yearly", rule: "FREQ=YEARLY", previous: at(2025, 3, 1), want: at(2026, 3, 1), wantOK: true},
+		{name: "yearly on a leap day", rule: "FREQ=YEARLY", previous: at(2024, 2, 29), want: at(2028, 2, 29), wantOK: true},
+		{name: "count not reached", rule: "FREQ=DAILY;COUNT=3", previous: at(2025, 1, 6), occurrences: 2, want: at(2025, 1, 7), wantOK: true},
+		{name: "count reached", rule: "FREQ=DAILY;COUNT=3", previous: at(2025, 1, 6), occurrences: 3},
+		{name: "until not passed", rule: "FREQ=DAILY;UNTIL=20250107T120000Z", previous: at(2025, 1, 6), want: at(2025, 1, 7), wantOK: true},
+		{name: "until passed", rule: "FREQ=DAILY;UNTIL=20250107", previous: at(2025, 1, 6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) error = %v", tt.rule, err)
+			}
+
+			got, ok := rule.Next(tt.previous, tt.occurrences)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Next(%s, %d) = %s, %t, want %s, %t", tt.previous, tt.occurrences, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
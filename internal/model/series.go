@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"todo-app/internal/errs"
+)
+
+var ErrTaskNotRecurring = errs.New(errs.KindNotFound, "task_not_recurring", "task is not recurring")
+
+// TaskSeries links the occurrences of a recurring task, a new occurrence is
+// created from the last one each time it is completed
+type TaskSeries struct {
+	ID        int64      `json:"id"`
+	Rule      string     `json:"rule"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	StoppedAt *time.Time `json:"stopped_at"`
+}
+
+func (TaskSeries) TableName() string {
+	return "task_series"
+}
+
+// NextOccurrence returns the occurrence following task, false once the
+// series is stopped or exhausted. occurrences is the number of occurrences
+// created so far.
+func (s *TaskSeries) NextOccurrence(task *Task, occurrences int64) (*Task, bool, error) {
+	if s.StoppedAt != nil {
+		return nil, false, nil
+	}
+
+	rule, err := ParseRecurrenceRule(s.Rule)
+	if err != nil {
+		return nil, false, err
+	}
+
+	previous := task.CreatedAt
+	if task.DueAt != nil {
+		previous = *task.DueAt
+	}
+
+	next, ok := rule.Next(previous, int(occurrences))
+	if !ok {
+		return nil, false, nil
+	}
+
+	return task.NextOccurrence(next), true, nil
+}
+
+type UpdateTaskSeriesInput struct {
+	Rule string `json:"rule"`
+}
+
+// Validate checks that the rule can be parsed
+func (i UpdateTaskSeriesInput) Validate() error {
+	_, err := ParseRecurrenceRule(i.Rule)
+	return err
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskSeriesNextOccurrence(t *testing.T) {
+	seriesID := int64(7)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2025, 1, 6, 17, 0, 0, 0, time.UTC)
+	remindAt := dueAt.Add(-time.Hour)
+	stoppedAt := dueAt
+
+	tests := []struct {
+		name        string
+		series      TaskSeries
+		task        Task
+		occurrences int64
+		wantDueAt   time.Time
+		wantRemind  *time.Time
+		wantOK      bool
+		wantErr     bool
+	}{
+		{
+			name:        "due date moves along the rule",
+			series:      TaskSeries{ID: seriesID, Rule: "daily"},
+			task:        Task{SeriesID: &seriesID, DueAt: &dueAt, CreatedAt: createdAt},
+			occurrences: 1,
+			wantDueAt:   dueAt.AddDate(0, 0, 1),
+			wantOK:      true,
+		},
+		{
+			name:        "reminder keeps its offset to the due date",
+			series:      TaskSeries{ID: seriesID, Rule: "weekly"},
+			task:        Task{SeriesID: &seriesID, DueAt: &dueAt, RemindAt: &remindAt, CreatedAt: createdAt},
+			occurrences: 1,
+			wantDueAt:   dueAt.AddDate(0, 0, 7),
+			wantRemind:  ptr(remindAt.AddDate(0, 0, 7)),
+			wantOK:      true,
+		},
+		{
+			name:        "without a due date the creation time is the previous occurrence",
+			series:      TaskSeries{ID: seriesID, Rule: "daily"},
+			task:        Task{SeriesID: &seriesID, CreatedAt: createdAt},
+			occurrences: 1,
+			wantDueAt:   createdAt.AddDate(0, 0, 1),
+			wantOK:      true,
+		},
+		{
+			name:        "stopped series",
+			series:      TaskSeries{ID: seriesID, Rule: "daily", StoppedAt: &stoppedAt},
+			task:        Task{SeriesID: &seriesID, DueAt: &dueAt},
+			occurrences: 1,
+		},
+		{
+			name:        "exhausted series",
+			series:      TaskSeries{ID: seriesID, Rule: "FREQ=DAILY;COUNT=2"},
+			task:        Task{SeriesID: &seriesID, DueAt: &dueAt},
+			occurrences: 2,
+		},
+		{
+			name:    "invalid rule",
+			series:  TaskSeries{ID: seriesID, Rule: "FREQ=HOURLY"},
+			task:    Task{SeriesID: &seriesID, DueAt: &dueAt},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok, err := tt.series.NextOccurrence(&tt.task, tt.occurrences)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NextOccurrence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("NextOccurrence() ok = %t, want %t", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+
+			if next.DueAt == nil || !next.DueAt.Equal(tt.wantDueAt) {
+				t.Errorf("NextOccurrence() due at %v, want %s", next.DueAt, tt.wantDueAt)
+			}
+			if (next.RemindAt == nil) != (tt.wantRemind == nil) || (next.RemindAt != nil && !next.RemindAt.Equal(*tt.wantRemind)) {
+				t.Errorf("NextOccurrence() remind at %v, want %v", next.RemindAt, tt.wantRemind)
+			}
+			if next.SeriesID == nil || *next.SeriesID != seriesID {
+				t.Errorf("NextOccurrence() series %v, want %d", next.SeriesID, seriesID)
+			}
+			if next.Status != TaskStatusTodo {
+				t.Errorf("NextOccurrence() status %q, want %q", next.Status, TaskStatusTodo)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"fmt"
+	"time"
+	"todo-app/internal/errs"
+)
+
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "todo"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusBlocked    TaskStatus = "blocked"
+	TaskStatusInReview   TaskStatus = "in_review"
+	// TaskStatusDone is the only status a task counts as completed in
+	TaskStatusDone TaskStatus = "done"
+)
+
+var (
+	ErrUnknownTaskStatus    = errs.New(errs.KindValidation, "unknown_task_status", "task status is unknown")
+	ErrTaskStatusTransition = errs.New(errs.KindConflict, "task_status_transition", "task status transition is not allowed")
+)
+
+// TaskWorkflow maps each status to the statuses a task may move to from it.
+// Every status of the workflow must appear as a key, even without outgoing
+// transitions.
+type TaskWorkflow map[TaskStatus][]TaskStatus
+
+// NewTaskWorkflow builds the workflow from the configured transitions
+func NewTaskWorkflow(transitions map[string][]string) TaskWorkflow {
+	workflow := TaskWorkflow{}
+	for from, tos := range transitions {
+		statuses := []TaskStatus{}
+		for _, to := range tos {
+			statuses = append(statuses, TaskStatus(to))
+		}
+		workflow[TaskStatus(from)] = statuses
+	}
+	return workflow
+}
+
+// Has reports whether status is part of the workflow
+func (w TaskWorkflow) Has(status TaskStatus) bool {
+	_, ok := w[status]
+	return ok
+}
+
+// Transition checks that a task may move from one status to the other,
+// staying in the same status is always allowed
+func (w TaskWorkflow) Transition(from, to TaskStatus) error {
+	if !w.Has(to) {
+		return fmt.Errorf("%w: %q", ErrUnknownTaskStatus, to)
+	}
+
+	if from == to {
+		return nil
+	}
+
+	for _, status := range w[from] {
+		if status == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: cannot move task from %q to %q", ErrTaskStatusTransition, from, to)
+}
+
+// SetStatus moves the task to status and maintains the timestamps: started_at
+// is set the first time the task is in progress, completed_at is set while
+// the task is done and cleared when it is reopened
+func (t *Task) SetStatus(status TaskStatus, now time.Time) {
+	t.Status = status
+	t.Completed = status == TaskStatusDone
+
+	if status == TaskStatusInProgress && t.StartedAt == nil {
+		t.StartedAt = &now
+	}
+
+	switch {
+	case status != TaskStatusDone:
+		t.CompletedAt = nil
+	case t.CompletedAt == nil:
+		t.CompletedAt = &now
+	}
+}
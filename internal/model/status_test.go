@@ -0,0 +1,87 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskWorkflowTransition(t *testing.T) {
+	workflow := NewTaskWorkflow(map[string][]string{
+		"todo":        {"in_progress", "done"},
+		"in_progress": {"todo", "done"},
+		"done":        {"todo"},
+		"archived":    {},
+	})
+
+	tests := []struct {
+		name     string
+		from, to TaskStatus
+		wantErr  error
+	}{
+		{name: "allowed", from: TaskStatusTodo, to: TaskStatusInProgress},
+		{name: "allowed back", from: TaskStatusInProgress, to: TaskStatusTodo},
+		{name: "reopen", from: TaskStatusDone, to: TaskStatusTodo},
+		{name: "same status", from: TaskStatusDone, to: TaskStatusDone},
+		{name: "same status without outgoing transitions", from: "archived", to: "archived"},
+		{name: "forbidden", from: TaskStatusDone, to: TaskStatusInProgress, wantErr: ErrTaskStatusTransition},
+		{name: "into a status without incoming transitions", from: TaskStatusTodo, to: "archived", wantErr: ErrTaskStatusTransition},
+		{name: "out of a status without outgoing transitions", from: "archived", to: TaskStatusTodo, wantErr: ErrTaskStatusTransition},
+		{name: "out of an unknown status", from: TaskStatusBlocked, to: TaskStatusTodo, wantErr: ErrTaskStatusTransition},
+		{name: "into an unknown status", from: TaskStatusTodo, to: TaskStatusBlocked, wantErr: ErrUnknownTaskStatus},
+		{name: "into an empty status", from: TaskStatusTodo, to: "", wantErr: ErrUnknownTaskStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := workflow.Transition(tt.from, tt.to); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Transition(%q, %q) error = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaskSetStatus(t *testing.T) {
+	earlier := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		task            Task
+		status          TaskStatus
+		wantStartedAt   *time.Time
+		wantCompletedAt *time.Time
+	}{
+		{name: "start", task: Task{Status: TaskStatusTodo}, status: TaskStatusInProgress, wantStartedAt: &now},
+		{name: "start again", task: Task{Status: TaskStatusTodo, StartedAt: &earlier}, status: TaskStatusInProgress, wantStartedAt: &earlier},
+		{name: "complete", task: Task{Status: TaskStatusInProgress, StartedAt: &earlier}, status: TaskStatusDone, wantStartedAt: &earlier, wantCompletedAt: &now},
+		{name: "complete without starting", task: Task{Status: TaskStatusTodo}, status: TaskStatusDone, wantCompletedAt: &now},
+		{name: "stay done", task: Task{Status: TaskStatusDone, Completed: true, CompletedAt: &earlier}, status: TaskStatusDone, wantCompletedAt: &earlier},
+		{name: "reopen", task: Task{Status: TaskStatusDone, Completed: true, StartedAt: &earlier, CompletedAt: &earlier}, status: TaskStatusTodo, wantStartedAt: &earlier},
+		{name: "block", task: Task{Status: TaskStatusTodo}, status: TaskStatusBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := tt.task
+			task.SetStatus(tt.status, now)
+
+			if task.Status != tt.status || task.Completed != (tt.status == TaskStatusDone) {
+				t.Errorf("status = %q completed %t, want %q", task.Status, task.Completed, tt.status)
+			}
+			if !sameTime(task.StartedAt, tt.wantStartedAt) {
+				t.Errorf("started_at = %v, want %v", task.StartedAt, tt.wantStartedAt)
+			}
+			if !sameTime(task.CompletedAt, tt.wantCompletedAt) {
+				t.Errorf("completed_at = %v, want %v", task.CompletedAt, tt.wantCompletedAt)
+			}
+		})
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"todo-app/internal/errs"
+	"todo-app/internal/utils"
+)
+
+var (
+	ErrTagNotFound  = errs.New(errs.KindNotFound, "tag_not_found", "tag not found")
+	ErrTagNameTaken = errs.New(errs.KindConflict, "tag_name_taken", "tag name is already taken")
+)
+
+type Tag struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	WorkspaceID *int64    `json:"workspace_id"`
+	OwnerID     *int64    `json:"owner_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TaskTag is a row of the task_tags join table
+type TaskTag struct {
+	TaskID int64
+	TagID  int64
+}
+
+func (TaskTag) TableName() string {
+	return "task_tags"
+}
+
+// NormalizeTagName trims and lower cases name so that "Urgent " and "urgent"
+// are the same tag
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeTagNames normalizes, deduplicates and sorts names, empty names
+// are dropped
+func NormalizeTagNames(names []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+
+	sort.Strings(normalized)
+	return normalized
+}
+
+// NewTags returns the tags named names, nil names stays nil so that an
+// update without tags leaves the task's tags untouched
+func NewTags(names []string) []*Tag {
+	if names == nil {
+		return nil
+	}
+
+	tags := []*Tag{}
+	for _, name := range NormalizeTagNames(names) {
+		tags = append(tags, &Tag{Name: name})
+	}
+
+	return tags
+}
+
+type CreateTagInput struct {
+	Name string `json:"name"`
+}
+
+var ErrTagNameRequired = errs.New(errs.KindValidation, "tag_name_required", "tag name is required")
+
+// Validate rejects blank names
+func (i CreateTagInput) Validate() error {
+	if NormalizeTagName(i.Name) == "" {
+		return ErrTagNameRequired
+	}
+	return nil
+}
+
+func (i CreateTagInput) ToModel() *Tag {
+	return &Tag{
+		ID:        utils.GenerateID(),
+		Name:      NormalizeTagName(i.Name),
+		CreatedAt: time.Now(),
+	}
+}
+
+type UpdateTagInput struct {
+	Name string `json:"name"`
+}
+
+// Validate rejects blank names
+func (i UpdateTagInput) Validate() error {
+	if NormalizeTagName(i.Name) == "" {
+		return ErrTagNameRequired
+	}
+	return nil
+}
+
+func (i UpdateTagInput) ToModel(ID int64) *Tag {
+	return &Tag{
+		ID:        ID,
+		Name:      NormalizeTagName(i.Name),
+		UpdatedAt: time.Now(),
+	}
+}
+
+type GetTagsQueryParams struct {
+	Pagination
+}
+
+type TagRepository interface {
+	Create(ctx context.Context, input *Tag) (err error)
+	DeleteByID(ctx context.Context, ID int64) (err error)
+	FindByID(ctx context.Context, ID int64) (tag *Tag, err error)
+	FindByName(ctx context.Context, name string) (tag *Tag, err error)
+	FindAll(ctx context.Context, query GetTagsQueryParams) (tags []*Tag, err error)
+	CountAll(ctx context.Context) (count int64, err error)
+	Update(ctx context.Context, input *Tag) (tag *Tag, err error)
+	FindTaskIDsByTagID(ctx context.Context, ID int64) (taskIDs []int64, err error)
+}
+
+type TagUsecase interface {
+	Create(ctx context.Context, input *Tag) (tag *Tag, err error)
+	DeleteByID(ctx context.Context, ID int64) (err error)
+	FindByID(ctx context.Context, ID int64) (tag *Tag, err error)
+	FindAll(ctx context.Context, query GetTagsQueryParams) (tags []*Tag, count int64, err error)
+	Update(ctx context.Context, input *Tag) (tag *Tag, err error)
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTagNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{name: "nil", want: []string{}},
+		{name: "trimmed and lower cased", names: []string{" Urgent ", "WORK"}, want: []string{"urgent", "work"}},
+		{name: "deduplicated after normalizing", names: []string{"urgent", "Urgent", " urgent"}, want: []string{"urgent"}},
+		{name: "blank names dropped", names: []string{"", "  ", "home"}, want: []string{"home"}},
+		{name: "sorted", names: []string{"work", "home", "errands"}, want: []string{"errands", "home", "work"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTagNames(tt.names); !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeTagNames(%q) = %q, want %q", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTags(t *testing.T) {
+	if tags := NewTags(nil); tags != nil {
+		t.Errorf("NewTags(nil) = %v, want nil", tags)
+	}
+
+	tags := NewTags([]string{})
+	if tags == nil || len(tags) != 0 {
+		t.Errorf("NewTags([]) = %v, want an empty slice", tags)
+	}
+}
+
+func TestGetTasksQueryParamsTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        GetTasksQueryParams
+		wantNames    []string
+		wantMatchAll bool
+		wantErr      bool
+	}{
+		{name: "no filter", query: GetTasksQueryParams{}},
+		{name: "any by default", query: GetTasksQueryParams{Tags: "Work, urgent,,work"}, wantNames: []string{"urgent", "work"}},
+		{name: "any", query: GetTasksQueryParams{Tags: "work", TagsMode: "any"}, wantNames: []string{"work"}},
+		{name: "all", query: GetTasksQueryParams{Tags: "work", TagsMode: "ALL"}, wantNames: []string{"work"}, wantMatchAll: true},
+		{name: "unknown mode", query: GetTasksQueryParams{Tags: "work", TagsMode: "some"}, wantNames: []string{"work"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %t", err, tt.wantErr)
+			}
+			if got := tt.query.TagNames(); !slices.Equal(got, tt.wantNames) {
+				t.Errorf("TagNames() = %q, want %q", got, tt.wantNames)
+			}
+			if got := tt.query.MatchAllTags(); got != tt.wantMatchAll {
+				t.Errorf("MatchAllTags() = %t, want %t", got, tt.wantMatchAll)
+			}
+		})
+	}
+}
@@ -2,71 +2,539 @@ package model
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"todo-app/internal/errs"
 	"todo-app/internal/utils"
+	"todo-app/internal/validation"
+
+	"gorm.io/gorm"
 )
 
+// Task.Completed is kept for older clients, it is derived from the status by
+// the database and therefore never written
 type Task struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Todo      string    `json:"todo"`
-	Completed bool      `json:"completed"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	DeletedAt time.Time `json:"deleted_at"`
+	ID          int64          `json:"id"`
+	Title       string         `json:"title"`
+	Todo        string         `json:"todo"`
+	Completed   bool           `json:"completed" gorm:"->"`
+	Status      TaskStatus     `json:"status"`
+	StartedAt   *time.Time     `json:"started_at"`
+	CompletedAt *time.Time     `json:"completed_at"`
+	Priority    TaskPriority   `json:"priority"`
+	Position    string         `json:"position"`
+	Version     int64          `json:"version" gorm:"default:1"`
+	DueAt       *time.Time     `json:"due_at"`
+	RemindAt    *time.Time     `json:"remind_at"`
+	SeriesID    *int64         `json:"series_id"`
+	Series      *TaskSeries    `json:"series,omitempty"`
+	ParentID    *int64         `json:"parent_id"`
+	Progress    *TaskProgress  `json:"progress,omitempty" gorm:"-"`
+	ProjectID   *int64         `json:"project_id"`
+	OwnerID     *int64         `json:"owner_id"`
+	WorkspaceID *int64         `json:"workspace_id"`
+	Tags        []*Tag         `json:"tags" gorm:"many2many:task_tags"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at"`
+
+	// Score is only selected when ranking the next up tasks
+	Score *float64 `json:"score,omitempty" gorm:"->"`
+
+	// RemindedAt and DueNotifiedAt are only maintained by the reminder worker
+	RemindedAt    *time.Time `json:"-"`
+	DueNotifiedAt *time.Time `json:"-"`
+}
+
+var (
+	ErrTaskNotFound        = errs.New(errs.KindNotFound, "task_not_found", "task not found")
+	ErrTaskVersionMismatch = errs.New(errs.KindPreconditionFailed, "task_version_mismatch", "task was modified in the meantime")
+)
+
+// ETag identifies the representation of the task. It hashes the task as
+// rendered rather than only its version, which does not move with the
+// progress of its subtasks or with its tags being renamed or deleted.
+func (t *Task) ETag() string {
+	body, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Sprintf(`"%d"`, t.Version)
+	}
+
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%d-%x"`, t.Version, sum[:12])
+}
+
+// TaskProgress summarizes the direct subtasks of a task
+type TaskProgress struct {
+	Total     int64   `json:"total"`
+	Completed int64   `json:"completed"`
+	Percent   float64 `json:"percent"`
+}
+
+func NewTaskProgress(total, completed int64) *TaskProgress {
+	progress := &TaskProgress{Total: total, Completed: completed}
+	if total > 0 {
+		progress.Percent = math.Round(float64(completed)/float64(total)*10000) / 100
+	}
+	return progress
+}
+
+// SortValue returns the value of the sort field formatted so that Postgres
+// can compare it against the sort column again
+func (t *Task) SortValue(field string) string {
+	switch field {
+	case "created_at":
+		return t.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return t.UpdatedAt.Format(time.RFC3339Nano)
+	case "title":
+		return t.Title
+	case "completed":
+		return strconv.FormatBool(t.Completed)
+	case "priority":
+		return strconv.Itoa(t.Priority.Level())
+	case "position":
+		return t.Position
+	case "due_date":
+		if t.DueAt == nil {
+			return "infinity"
+		}
+		return t.DueAt.Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatInt(t.ID, 10)
+	}
 }
 
 type CreateTaskInput struct {
-	Title     string `json:"title"`
-	Todo      string `json:"todo"`
-	Completed bool   `json:"completed"`
+	Title      string       `json:"title" validate:"required,max=255"`
+	Todo       string       `json:"todo" validate:"max=10000"`
+	Completed  bool         `json:"completed"`
+	Status     TaskStatus   `json:"status"`
+	Priority   TaskPriority `json:"priority"`
+	DueAt      *time.Time   `json:"due_at"`
+	RemindAt   *time.Time   `json:"remind_at"`
+	Recurrence string       `json:"recurrence" validate:"max=255"`
+	ProjectID  *int64       `json:"project_id" validate:"omitempty,gt=0"`
+	Tags       []string     `json:"tags" validate:"max=20,dive,max=64"`
+}
+
+// Validate checks the validate tags, the priority and the recurrence rule
+// when one is given
+func (i CreateTaskInput) Validate() error {
+	if err := validation.Struct(i); err != nil {
+		return err
+	}
+
+	if err := i.Priority.Validate(); err != nil {
+		return err
+	}
+
+	if i.Recurrence == "" {
+		return nil
+	}
+
+	_, err := ParseRecurrenceRule(i.Recurrence)
+	return err
 }
 
 func (i CreateTaskInput) ToModel() *Task {
-	return &Task{
+	task := &Task{
 		ID:        utils.GenerateID(),
 		Title:     i.Title,
 		Todo:      i.Todo,
 		Completed: i.Completed,
+		Status:    i.Status,
+		Priority:  i.Priority,
+		DueAt:     i.DueAt,
+		RemindAt:  i.RemindAt,
+		ProjectID: i.ProjectID,
+		Tags:      NewTags(i.Tags),
 		CreatedAt: time.Now(),
 	}
+
+	if task.Priority == "" {
+		task.Priority = TaskPriorityNone
+	}
+
+	if rule, err := ParseRecurrenceRule(i.Recurrence); err == nil {
+		task.Series = &TaskSeries{
+			ID:        utils.GenerateID(),
+			Rule:      rule.String(),
+			CreatedAt: task.CreatedAt,
+		}
+		task.SeriesID = &task.Series.ID
+	}
+
+	return task
 }
 
+// NextOccurrence returns the task following t in its series which is due at
+// dueAt, the reminder keeps its offset to the due date
+func (t *Task) NextOccurrence(dueAt time.Time) *Task {
+	next := &Task{
+		ID:          utils.GenerateID(),
+		Title:       t.Title,
+		Todo:        t.Todo,
+		Status:      TaskStatusTodo,
+		Priority:    t.Priority,
+		DueAt:       &dueAt,
+		SeriesID:    t.SeriesID,
+		ProjectID:   t.ProjectID,
+		OwnerID:     t.OwnerID,
+		WorkspaceID: t.WorkspaceID,
+		CreatedAt:   time.Now(),
+	}
+
+	if t.DueAt != nil && t.RemindAt != nil {
+		remindAt := dueAt.Add(t.RemindAt.Sub(*t.DueAt))
+		next.RemindAt = &remindAt
+	}
+
+	return next
+}
+
+// UpdateTaskInput is the full representation of a task as replaced by PUT,
+// omitted fields are cleared
 type UpdateTaskInput struct {
-	ID        int64  `json:"id"`
-	Title     string `json:"title"`
-	Todo      string `json:"todo"`
-	Completed bool   `json:"completed"`
+	Title string `json:"title" validate:"required,max=255"`
+	Todo  string `json:"todo" validate:"max=10000"`
+	// Completed moves the task to done, or reopens a done task, when no
+	// status is given
+	Completed bool         `json:"completed"`
+	Status    TaskStatus   `json:"status"`
+	Priority  TaskPriority `json:"priority"`
+	DueAt     *time.Time   `json:"due_at"`
+	RemindAt  *time.Time   `json:"remind_at"`
+	ProjectID *int64       `json:"project_id" validate:"omitempty,gt=0"`
+	Tags      []string     `json:"tags" validate:"max=20,dive,max=64"`
+}
+
+// NewUpdateTaskInput returns the representation of task, which PATCH
+// requests are applied to
+func NewUpdateTaskInput(task *Task) UpdateTaskInput {
+	tags := []string{}
+	for _, tag := range task.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	return UpdateTaskInput{
+		Title:     task.Title,
+		Todo:      task.Todo,
+		Completed: task.Completed,
+		Status:    task.Status,
+		Priority:  task.Priority,
+		DueAt:     task.DueAt,
+		RemindAt:  task.RemindAt,
+		ProjectID: task.ProjectID,
+		Tags:      tags,
+	}
+}
+
+func (i UpdateTaskInput) Validate() error {
+	if err := validation.Struct(i); err != nil {
+		return err
+	}
+
+	return i.Priority.Validate()
+}
+
+// GetTrashedTasksQueryParams pages through the trash, which is listed by
+// deletion, the most recent first, and takes no filters
+type GetTrashedTasksQueryParams struct {
+	Pagination
 }
 
 type GetTasksQueryParams struct {
-	Page int64 `query:"page"`
-	Size int64 `query:"size"`
+	Pagination
+	Completed     *bool      `query:"completed"`
+	Status        string     `query:"status"`
+	Query         string     `query:"q" validate:"max=255"`
+	CreatedAfter  *time.Time `query:"created_after"`
+	CreatedBefore *time.Time `query:"created_before"`
+	UpdatedAfter  *time.Time `query:"updated_after"`
+	Sort          string     `query:"sort"`
+	Order         string     `query:"order"`
+	Cursor        string     `query:"cursor"`
+	ParentID      *int64     `query:"parent_id"`
+	ProjectID     *int64     `query:"project_id"`
+	Tags          string     `query:"tags"`
+	TagsMode      string     `query:"tags_mode"`
+}
+
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+
+	// TagsModeAny matches tasks having any of the tags, TagsModeAll only
+	// tasks having every tag
+	TagsModeAny = "any"
+	TagsModeAll = "all"
+)
+
+// taskSortColumns is the whitelist of sortable fields, keyed by the value
+// accepted in the sort query param. Tasks without a due date sort as if
+// they were due at infinity so keyset comparisons never meet a NULL.
+var taskSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+	"completed":  "completed",
+	"priority":   TaskPriorityLevelColumn,
+	"position":   "position",
+	"due_date":   "COALESCE(due_at, 'infinity')",
 }
 
-func (i UpdateTaskInput) ToModel() *Task {
-	return &Task{
-		ID:        i.ID,
+// Validate rejects sort fields and orders outside of the whitelist
+func (q GetTasksQueryParams) Validate() error {
+	if err := validation.Struct(q); err != nil {
+		return err
+	}
+
+	fields := []errs.FieldError{}
+	if _, ok := taskSortColumns[q.Sort]; q.Sort != "" && !ok {
+		fields = append(fields, oneOfField("sort", slices.Sorted(maps.Keys(taskSortColumns))))
+	}
+
+	switch strings.ToLower(q.Order) {
+	case "", SortOrderAsc, SortOrderDesc:
+	default:
+		fields = append(fields, oneOfField("order", []string{SortOrderAsc, SortOrderDesc}))
+	}
+
+	switch strings.ToLower(q.TagsMode) {
+	case "", TagsModeAny, TagsModeAll:
+	default:
+		fields = append(fields, oneOfField("tags_mode", []string{TagsModeAny, TagsModeAll}))
+	}
+
+	if len(fields) > 0 {
+		return errs.ErrValidation.WithFields(fields...)
+	}
+
+	if _, err := q.DecodeCursor(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// oneOfField reports a field whose value is not one of values
+func oneOfField(field string, values []string) errs.FieldError {
+	return errs.FieldError{
+		Field:   field,
+		Rule:    "oneof",
+		Message: fmt.Sprintf("%s must be one of %s", field, strings.Join(values, " ")),
+	}
+}
+
+// TagNames returns the normalized names of the comma separated tags param
+func (q GetTasksQueryParams) TagNames() []string {
+	if q.Tags == "" {
+		return nil
+	}
+	return NormalizeTagNames(strings.Split(q.Tags, ","))
+}
+
+// MatchAllTags reports whether tasks must carry every tag of the filter
+func (q GetTasksQueryParams) MatchAllTags() bool {
+	return strings.ToLower(q.TagsMode) == TagsModeAll
+}
+
+// DecodeCursor returns the decoded cursor param or nil when it is empty,
+// a cursor issued for another sort field is rejected
+func (q GetTasksQueryParams) DecodeCursor() (*Cursor, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	cursor, err := DecodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.Sort != q.SortField() {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// CursorOf returns the cursor pointing at task for the current sort field
+func (q GetTasksQueryParams) CursorOf(task *Task, backward bool) string {
+	return EncodeCursor(Cursor{
+		Sort:     q.SortField(),
+		Value:    task.SortValue(q.SortField()),
+		ID:       task.ID,
+		Backward: backward,
+	})
+}
+
+// SortField returns the whitelisted sort field, defaulting to id which is
+// time based and therefore lists the newest tasks first
+func (q GetTasksQueryParams) SortField() string {
+	if _, ok := taskSortColumns[q.Sort]; ok {
+		return q.Sort
+	}
+	return "id"
+}
+
+// SortColumn returns the column expression of the sort field
+func (q GetTasksQueryParams) SortColumn() string {
+	if column, ok := taskSortColumns[q.SortField()]; ok {
+		return column
+	}
+	return "id"
+}
+
+// SortOrder returns the normalized sort direction, defaulting to desc
+func (q GetTasksQueryParams) SortOrder() string {
+	if strings.ToLower(q.Order) == SortOrderAsc {
+		return SortOrderAsc
+	}
+	return SortOrderDesc
+}
+
+// OrderClause builds the ORDER BY clause, id is used as a tie breaker so
+// that pages are stable when the sort column has duplicates
+func (q GetTasksQueryParams) OrderClause() string {
+	order := strings.ToUpper(q.SortOrder())
+	column := q.SortColumn()
+	if column == "id" {
+		return fmt.Sprintf("id %s", order)
+	}
+	return fmt.Sprintf("%s %s, id %s", column, order, order)
+}
+
+func (i UpdateTaskInput) ToModel(ID int64) *Task {
+	task := &Task{
+		ID:        ID,
 		Title:     i.Title,
 		Todo:      i.Todo,
 		Completed: i.Completed,
+		Status:    i.Status,
+		Priority:  i.Priority,
+		DueAt:     i.DueAt,
+		RemindAt:  i.RemindAt,
+		ProjectID: i.ProjectID,
+		Tags:      NewTags(i.Tags),
 		UpdatedAt: time.Now(),
 	}
+
+	if task.Priority == "" {
+		task.Priority = TaskPriorityNone
+	}
+	// a replaced task without tags loses its tags
+	if task.Tags == nil {
+		task.Tags = []*Tag{}
+	}
+
+	return task
+}
+
+// DueTasksQuery selects open tasks due before To, and not before From when
+// it is set. Leaving From empty selects overdue tasks.
+type DueTasksQuery struct {
+	From *time.Time
+	To   time.Time
+	Page int64
+	Size int64
+}
+
+type GetDueTasksQueryParams struct {
+	Pagination
+	Within string `query:"within"`
 }
 
 type TaskRepository interface {
 	Create(ctx context.Context, input *Task) (err error)
-	DeleteByID(ctx context.Context, ID int64) (err error)
+	// DeleteByID only deletes the task while it is at version, unless
+	// version is 0
+	DeleteByID(ctx context.Context, ID int64, version int64) (err error)
 	FindByID(ctx context.Context, ID int64) (task *Task, err error)
 	FindAll(ctx context.Context, query GetTasksQueryParams) (tasks []*Task, err error)
-	CountAll(ctx context.Context) (count int64, err error)
+	FindAllByCursor(ctx context.Context, query GetTasksQueryParams) (tasks []*Task, err error)
+	CountAll(ctx context.Context, query GetTasksQueryParams) (count int64, err error)
+	// Update writes the task while it is still at input.Version
 	Update(ctx context.Context, input *Task) (task *Task, err error)
+	FindAllTrashed(ctx context.Context, query GetTrashedTasksQueryParams) (tasks []*Task, err error)
+	CountAllTrashed(ctx context.Context) (count int64, err error)
+	RestoreByID(ctx context.Context, ID int64) (err error)
+	PurgeByID(ctx context.Context, ID int64) (err error)
+	FindAllDue(ctx context.Context, query DueTasksQuery) (tasks []*Task, err error)
+	CountAllDue(ctx context.Context, query DueTasksQuery) (count int64, err error)
+	FindAllNext(ctx context.Context, query NextTasksQuery) (tasks []*Task, err error)
+	CountAllNext(ctx context.Context, query NextTasksQuery) (count int64, err error)
+	// ClaimDueNotifications marks up to limit tasks of the kind which are due
+	// at now and not notified yet as notified, skipping rows locked by other
+	// workers, and commits before returning them. Notifications are sent
+	// afterwards, so that no row stays locked while a sink is slow.
+	ClaimDueNotifications(ctx context.Context, kind NotificationKind, now time.Time, limit int) (tasks []*Task, err error)
+	// ReleaseNotification clears the claim on the notification of the kind,
+	// after it failed to send, so that the next run retries it
+	ReleaseNotification(ctx context.Context, kind NotificationKind, ID int64) (err error)
+	FindSeriesByID(ctx context.Context, ID int64) (series *TaskSeries, err error)
+	// SaveSeries creates or updates the series and links taskID to it
+	SaveSeries(ctx context.Context, taskID int64, series *TaskSeries) (err error)
+	// CompleteByID, like Update, MoveByID and ApplyBatch, creates the next
+	// occurrence of a recurring task it completes in the same transaction
+	CompleteByID(ctx context.Context, ID int64, cascade bool) (err error)
+	// FindAllByStatus returns up to query.Size tasks of each status ordered
+	// by status and position
+	FindAllByStatus(ctx context.Context, query GetBoardQueryParams) (tasks []*Task, err error)
+	CountAllByStatus(ctx context.Context, query GetBoardQueryParams) (counts map[TaskStatus]int64, err error)
+	MoveByID(ctx context.Context, move TaskMove) (err error)
+	MaxPositionLength(ctx context.Context) (length int, err error)
+	FindAllByIDs(ctx context.Context, IDs []int64) (tasks []*Task, err error)
+	// ApplyBatch applies the operations without an Err in one transaction
+	// and invalidates the cache once. Without atomic each operation runs in
+	// its own savepoint, so a failed one sets its Err and the others stay.
+	ApplyBatch(ctx context.Context, operations []*TaskOperation, atomic bool) (err error)
+	// RebalancePositions renumbers the positions of every task, trashed
+	// ones included, keeping their order
+	RebalancePositions(ctx context.Context) (rebalanced int64, err error)
+	// InvalidateCache drops the cached listings and the cached tasks IDs,
+	// e.g. after a tag they embed was renamed
+	InvalidateCache(ctx context.Context, IDs ...int64) (err error)
+	FindEventsByTaskID(ctx context.Context, taskID int64, query GetTaskHistoryQueryParams) (events []*TaskEvent, err error)
+	CountEventsByTaskID(ctx context.Context, taskID int64) (count int64, err error)
 }
 
 type TaskUsecase interface {
 	Create(ctx context.Context, input *Task) (task *Task, err error)
-	DeleteByID(ctx context.Context, ID int64) (err error)
+	// DeleteByID, Update and Patch fail with ErrTaskVersionMismatch when
+	// the task is no longer at the expected version, 0 expects any version
+	DeleteByID(ctx context.Context, ID int64, version int64) (err error)
 	FindByID(ctx context.Context, ID int64) (task *Task, err error)
 	FindAll(ctx context.Context, query GetTasksQueryParams) (tasks []*Task, count int64, err error)
+	FindAllByCursor(ctx context.Context, query GetTasksQueryParams) (tasks []*Task, nextCursor, prevCursor string, err error)
+	// Update replaces every writable field of the task
 	Update(ctx context.Context, input *Task) (task *Task, err error)
+	Patch(ctx context.Context, ID int64, patch TaskPatch) (task *Task, err error)
+	FindAllTrashed(ctx context.Context, query GetTrashedTasksQueryParams) (tasks []*Task, count int64, err error)
+	RestoreByID(ctx context.Context, ID int64) (task *Task, err error)
+	PurgeByID(ctx context.Context, ID int64) (err error)
+	FindOverdue(ctx context.Context, query GetTasksQueryParams) (tasks []*Task, count int64, err error)
+	FindDueWithin(ctx context.Context, within time.Duration, query GetTasksQueryParams) (tasks []*Task, count int64, err error)
+	FindNext(ctx context.Context, query GetTasksQueryParams) (tasks []*Task, count int64, err error)
+	FindSeriesByTaskID(ctx context.Context, taskID int64) (series *TaskSeries, err error)
+	UpdateSeries(ctx context.Context, taskID int64, rule string) (series *TaskSeries, err error)
+	StopSeries(ctx context.Context, taskID int64) (series *TaskSeries, err error)
+	CreateSubtask(ctx context.Context, parentID int64, input *Task) (task *Task, err error)
+	FindSubtasks(ctx context.Context, parentID int64, query GetTasksQueryParams) (tasks []*Task, count int64, err error)
+	CompleteByID(ctx context.Context, ID int64, cascade bool) (task *Task, err error)
+	FindBoard(ctx context.Context, query GetBoardQueryParams) (board *Board, err error)
+	Move(ctx context.Context, move TaskMove) (task *Task, err error)
+	// Bulk applies the operations in a single transaction and sets their
+	// Task or Err. With atomic the first failure rolls back every operation
+	// and is returned.
+	Bulk(ctx context.Context, operations []*TaskOperation, atomic bool) (err error)
+	// FindHistory returns the events of the task, newest first
+	FindHistory(ctx context.Context, ID int64, query GetTaskHistoryQueryParams) (events []*TaskEvent, count int64, err error)
 }
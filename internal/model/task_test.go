@@ -0,0 +1,159 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"todo-app/internal/errs"
+)
+
+func TestTaskETag(t *testing.T) {
+	createdAt := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	newTask := func() *Task {
+		return &Task{
+			ID:        1,
+			Title:     "write report",
+			Status:    TaskStatusTodo,
+			Version:   3,
+			Progress:  NewTaskProgress(2, 1),
+			Tags:      []*Tag{{ID: 10, Name: "work"}, {ID: 11, Name: "urgent"}},
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		change   func(task *Task)
+		wantSame bool
+	}{
+		{name: "unchanged", change: func(task *Task) {}, wantSame: true},
+		{name: "new version", change: func(task *Task) { task.Version++ }},
+		{name: "subtask completed", change: func(task *Task) { task.Progress = NewTaskProgress(2, 2) }},
+		{name: "subtask added", change: func(task *Task) { task.Progress = NewTaskProgress(3, 1) }},
+		{name: "tag renamed", change: func(task *Task) { task.Tags[0].Name = "office" }},
+		{name: "tag deleted", change: func(task *Task) { task.Tags = task.Tags[1:] }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := newTask().ETag()
+			task := newTask()
+			tt.change(task)
+
+			if same := task.ETag() == before; same != tt.wantSame {
+				t.Errorf("ETag() = %s before and %s after, want same %t", before, task.ETag(), tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestNewTaskProgress(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int64
+		completed int64
+		want      TaskProgress
+	}{
+		{name: "no subtasks", want: TaskProgress{}},
+		{name: "none completed", total: 4, want: TaskProgress{Total: 4}},
+		{name: "half completed", total: 4, completed: 2, want: TaskProgress{Total: 4, Completed: 2, Percent: 50}},
+		{name: "rounded to two decimals", total: 3, completed: 1, want: TaskProgress{Total: 3, Completed: 1, Percent: 33.33}},
+		{name: "rounded up", total: 3, completed: 2, want: TaskProgress{Total: 3, Completed: 2, Percent: 66.67}},
+		{name: "all completed", total: 3, completed: 3, want: TaskProgress{Total: 3, Completed: 3, Percent: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewTaskProgress(tt.total, tt.completed); *got != tt.want {
+				t.Errorf("NewTaskProgress(%d, %d) = %+v, want %+v", tt.total, tt.completed, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTasksQueryParamsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      GetTasksQueryParams
+		wantFields []errs.FieldError
+		wantErr    error
+	}{
+		{name: "defaults", query: GetTasksQueryParams{}},
+		{name: "whitelisted sort", query: GetTasksQueryParams{Sort: "due_date", Order: "ASC", TagsMode: "all"}},
+		{
+			name:  "unknown sort",
+			query: GetTasksQueryParams{Sort: "owner_id"},
+			wantFields: []errs.FieldError{{
+				Field:   "sort",
+				Rule:    "oneof",
+				Message: "sort must be one of completed created_at due_date position priority title updated_at",
+			}},
+			wantErr: errs.ErrValidation,
+		},
+		{
+			name:       "unknown order",
+			query:      GetTasksQueryParams{Order: "sideways"},
+			wantFields: []errs.FieldError{{Field: "order", Rule: "oneof", Message: "order must be one of asc desc"}},
+			wantErr:    errs.ErrValidation,
+		},
+		{
+			name:       "unknown tags mode",
+			query:      GetTasksQueryParams{TagsMode: "some"},
+			wantFields: []errs.FieldError{{Field: "tags_mode", Rule: "oneof", Message: "tags_mode must be one of any all"}},
+			wantErr:    errs.ErrValidation,
+		},
+		{
+			name:  "everything unknown",
+			query: GetTasksQueryParams{Sort: "id; DROP TABLE tasks", Order: "up", TagsMode: "some"},
+			wantFields: []errs.FieldError{
+				{Field: "sort", Rule: "oneof", Message: "sort must be one of completed created_at due_date position priority title updated_at"},
+				{Field: "order", Rule: "oneof", Message: "order must be one of asc desc"},
+				{Field: "tags_mode", Rule: "oneof", Message: "tags_mode must be one of any all"},
+			},
+			wantErr: errs.ErrValidation,
+		},
+		{
+			name:    "cursor of another sort",
+			query:   GetTasksQueryParams{Sort: "title", Cursor: EncodeCursor(Cursor{Sort: "created_at", ID: 1})},
+			wantErr: ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantFields != nil && !reflect.DeepEqual(errs.From(err).Fields, tt.wantFields) {
+				t.Errorf("Validate() fields = %+v, want %+v", errs.From(err).Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestGetTasksQueryParamsOrderClause(t *testing.T) {
+	tests := []struct {
+		name  string
+		query GetTasksQueryParams
+		want  string
+	}{
+		{name: "default", query: GetTasksQueryParams{}, want: "id DESC"},
+		{name: "unknown sort falls back to id", query: GetTasksQueryParams{Sort: "owner_id", Order: "asc"}, want: "id ASC"},
+		{name: "created_at", query: GetTasksQueryParams{Sort: "created_at", Order: "ASC"}, want: "created_at ASC, id ASC"},
+		{name: "due date without due dates last", query: GetTasksQueryParams{Sort: "due_date", Order: "asc"}, want: "COALESCE(due_at, 'infinity') ASC, id ASC"},
+		{name: "priority by level", query: GetTasksQueryParams{Sort: "priority"}, want: TaskPriorityLevelColumn + " DESC, id DESC"},
+		{name: "unknown order falls back to desc", query: GetTasksQueryParams{Sort: "title", Order: "up"}, want: "title DESC, id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.OrderClause(); got != tt.want {
+				t.Errorf("OrderClause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"time"
+	"todo-app/internal/errs"
+	"todo-app/internal/utils"
+	"todo-app/internal/validation"
+)
+
+var (
+	ErrUserNotFound       = errs.New(errs.KindNotFound, "user_not_found", "user not found")
+	ErrUserEmailTaken     = errs.New(errs.KindConflict, "user_email_taken", "email is already registered")
+	ErrInvalidCredentials = errs.New(errs.KindUnauthorized, "invalid_credentials", "email or password is invalid")
+)
+
+// User owns tasks, only the bcrypt hash of the password is stored
+type User struct {
+	ID           int64     `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RegisterInput limits the password to the 72 bytes bcrypt reads
+type RegisterInput struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+func (i RegisterInput) Validate() error {
+	return validation.Struct(i)
+}
+
+// ToModel leaves the password hash to the usecase
+func (i RegisterInput) ToModel() *User {
+	return &User{
+		ID:        utils.GenerateID(),
+		Email:     NormalizeEmail(i.Email),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+type LoginInput struct {
+	Email    string `json:"email" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+func (i LoginInput) Validate() error {
+	return validation.Struct(i)
+}
+
+// AccessToken is a bearer token issued on login
+type AccessToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NormalizeEmail makes emails which only differ in case or surrounding
+// spaces the same
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+type UserRepository interface {
+	Create(ctx context.Context, user *User) (err error)
+	FindByID(ctx context.Context, ID int64) (user *User, err error)
+	FindByEmail(ctx context.Context, email string) (user *User, err error)
+}
+
+type UserUsecase interface {
+	Register(ctx context.Context, input RegisterInput) (user *User, err error)
+	Login(ctx context.Context, input LoginInput) (token *AccessToken, err error)
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+	"todo-app/internal/errs"
+	"todo-app/internal/utils"
+	"todo-app/internal/validation"
+)
+
+var (
+	ErrWorkspaceNotFound       = errs.New(errs.KindNotFound, "workspace_not_found", "workspace not found")
+	ErrWorkspaceSlugTaken      = errs.New(errs.KindConflict, "workspace_slug_taken", "workspace slug is already taken")
+	ErrInvalidWorkspaceSlug    = errs.New(errs.KindValidation, "invalid_workspace_slug", "workspace slug must be a lower case DNS label which is not a number")
+	ErrWorkspaceMemberNotFound = errs.New(errs.KindNotFound, "workspace_member_not_found", "workspace member not found")
+	ErrWorkspaceMemberExists   = errs.New(errs.KindConflict, "workspace_member_exists", "user is already a member of the workspace")
+	ErrNotWorkspaceOwner       = errs.New(errs.KindForbidden, "not_workspace_owner", "only owners of the workspace can manage its members")
+	ErrLastWorkspaceOwner      = errs.New(errs.KindConflict, "last_workspace_owner", "the last owner cannot leave the workspace")
+)
+
+// workspaceSlugPattern keeps slugs usable as subdomains
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Workspace is a tenant, its tasks are shared by its members and isolated
+// from every other workspace
+type Workspace struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+	WorkspaceRoleMember WorkspaceRole = "member"
+)
+
+type WorkspaceMember struct {
+	WorkspaceID int64         `json:"workspace_id"`
+	UserID      int64         `json:"user_id"`
+	Role        WorkspaceRole `json:"role"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type CreateWorkspaceInput struct {
+	Name string `json:"name" validate:"required,max=255"`
+	Slug string `json:"slug" validate:"required,max=63"`
+}
+
+// Validate rejects numeric slugs, they could not be told from IDs when
+// resolving the workspace of a request
+func (i CreateWorkspaceInput) Validate() error {
+	if err := validation.Struct(i); err != nil {
+		return err
+	}
+
+	if !workspaceSlugPattern.MatchString(i.Slug) {
+		return ErrInvalidWorkspaceSlug
+	}
+	if _, err := strconv.ParseInt(i.Slug, 10, 64); err == nil {
+		return ErrInvalidWorkspaceSlug
+	}
+
+	return nil
+}
+
+func (i CreateWorkspaceInput) ToModel() *Workspace {
+	return &Workspace{
+		ID:        utils.GenerateID(),
+		Name:      i.Name,
+		Slug:      i.Slug,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// AddWorkspaceMemberInput adds the registered user with the email, as a
+// plain member unless another role is given
+type AddWorkspaceMemberInput struct {
+	Email string        `json:"email" validate:"required,email,max=255"`
+	Role  WorkspaceRole `json:"role" validate:"omitempty,oneof=owner member"`
+}
+
+func (i AddWorkspaceMemberInput) Validate() error {
+	return validation.Struct(i)
+}
+
+type GetWorkspacesQueryParams struct {
+	Pagination
+}
+
+type workspaceContextKey struct{}
+
+// ContextWithWorkspace scopes the tasks of ctx to the workspace, the caller
+// must have checked the membership of the principal
+func ContextWithWorkspace(ctx context.Context, workspaceID int64) context.Context {
+	return context.WithValue(ctx, workspaceContextKey{}, workspaceID)
+}
+
+// WorkspaceIDFromContext returns false when ctx is not scoped to a
+// workspace, its tasks are then the personal tasks of the principal
+func WorkspaceIDFromContext(ctx context.Context) (int64, bool) {
+	workspaceID, ok := ctx.Value(workspaceContextKey{}).(int64)
+	return workspaceID, ok
+}
+
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *Workspace, owner *WorkspaceMember) (err error)
+	FindByID(ctx context.Context, ID int64) (workspace *Workspace, err error)
+	FindBySlug(ctx context.Context, slug string) (workspace *Workspace, err error)
+	FindAllByUserID(ctx context.Context, userID int64, query GetWorkspacesQueryParams) (workspaces []*Workspace, err error)
+	CountAllByUserID(ctx context.Context, userID int64) (count int64, err error)
+	FindMember(ctx context.Context, workspaceID, userID int64) (member *WorkspaceMember, err error)
+	CountOwners(ctx context.Context, workspaceID int64) (count int64, err error)
+	AddMember(ctx context.Context, member *WorkspaceMember) (err error)
+	DeleteMember(ctx context.Context, workspaceID, userID int64) (err error)
+}
+
+type WorkspaceUsecase interface {
+	Create(ctx context.Context, input *Workspace) (workspace *Workspace, err error)
+	FindAll(ctx context.Context, query GetWorkspacesQueryParams) (workspaces []*Workspace, count int64, err error)
+	Resolve(ctx context.Context, ref string) (workspace *Workspace, err error)
+	AddMember(ctx context.Context, workspaceID int64, input AddWorkspaceMemberInput) (member *WorkspaceMember, err error)
+	RemoveMember(ctx context.Context, workspaceID, userID int64) (err error)
+}
@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier writes notifications to the application log
+func NewLogNotifier() model.Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, notification *model.Notification) error {
+	logrus.WithFields(logrus.Fields{
+		"kind":     notification.Kind,
+		"taskID":   notification.Task.ID,
+		"title":    notification.Task.Title,
+		"dueAt":    notification.Task.DueAt,
+		"remindAt": notification.Task.RemindAt,
+	}).Info("task notification")
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"todo-app/internal/config"
+	"todo-app/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewNotifiersFromConfig builds the sinks listed in notifier.sinks
+func NewNotifiersFromConfig() []model.Notifier {
+	notifiers := []model.Notifier{}
+	for _, sink := range config.NotifierSinks() {
+		switch sink {
+		case "log":
+			notifiers = append(notifiers, NewLogNotifier())
+		case "webhook":
+			notifiers = append(notifiers, NewWebhookNotifier(
+				config.NotifierWebhookURL(),
+				config.NotifierWebhookTimeout(),
+			))
+		case "smtp":
+			notifiers = append(notifiers, NewSMTPNotifier(
+				config.NotifierSMTPHost(),
+				config.NotifierSMTPPort(),
+				config.NotifierSMTPUsername(),
+				config.NotifierSMTPPassword(),
+				config.NotifierSMTPFrom(),
+				config.NotifierSMTPTo(),
+				config.NotifierSMTPTimeout(),
+			))
+		default:
+			logrus.WithField("sink", sink).Warn("unknown notifier sink")
+		}
+	}
+
+	return notifiers
+}
@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"todo-app/internal/model"
+)
+
+type smtpNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+	timeout  time.Duration
+}
+
+// NewSMTPNotifier mails notifications through the smtp server at host:port,
+// authentication is skipped when username is empty, e.g. for a local test
+// server. Sending a mail, from dialing to quitting, is bounded by timeout.
+func NewSMTPNotifier(host, port, username, password, from string, to []string, timeout time.Duration) model.Notifier {
+	return &smtpNotifier{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+		to:       to,
+		timeout:  timeout,
+	}
+}
+
+// Notify does what smtp.SendMail does, over a connection with a deadline
+func (n *smtpNotifier) Notify(ctx context.Context, notification *model.Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.message(notification)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (n *smtpNotifier) message(notification *model.Notification) []byte {
+	task := notification.Task
+	subject := fmt.Sprintf("Reminder: %s", task.Title)
+	if notification.Kind == model.NotificationKindDue {
+		subject = fmt.Sprintf("Due: %s", task.Title)
+	}
+
+	body := task.Todo
+	if task.DueAt != nil {
+		body = fmt.Sprintf("Due at %s\r\n\r\n%s", task.DueAt.Format(time.RFC1123), body)
+	}
+
+	return []byte(strings.Join([]string{
+		"From: " + n.from,
+		"To: " + strings.Join(n.to, ", "),
+		"Subject: " + encodeHeader(subject),
+		"Date: " + notification.FiredAt.Format(time.RFC1123Z),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n"))
+}
+
+// encodeHeader drops the line breaks of value, so that a task title cannot
+// add headers, and encodes it for a header as RFC 2047 asks
+func encodeHeader(value string) string {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	return mime.QEncoding.Encode("utf-8", value)
+}
@@ -0,0 +1,60 @@
+package notifier
+
+import (
+	"bytes"
+	"mime"
+	"net/mail"
+	"testing"
+	"time"
+
+	"todo-app/internal/model"
+)
+
+func TestSMTPNotifierMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        model.NotificationKind
+		title       string
+		wantSubject string
+	}{
+		{name: "reminder", kind: model.NotificationKindReminder, title: "write report", wantSubject: "Reminder: write report"},
+		{name: "due", kind: model.NotificationKindDue, title: "write report", wantSubject: "Due: write report"},
+		{name: "non-ASCII title", kind: model.NotificationKindDue, title: "Bericht prüfen", wantSubject: "Due: Bericht prüfen"},
+		{name: "header injection", kind: model.NotificationKindDue, title: "write report\r\nBcc: x@y", wantSubject: "Due: write reportBcc: x@y"},
+		{name: "body injection", kind: model.NotificationKindDue, title: "write report\r\n\r\nclick here", wantSubject: "Due: write reportclick here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewSMTPNotifier("localhost", "1025", "", "", "todo@example.com", []string{"alice@example.com"}, time.Second).(*smtpNotifier)
+			notification := &model.Notification{
+				Kind:    tt.kind,
+				Task:    &model.Task{Title: tt.title, Todo: "the quarterly one"},
+				FiredAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+			}
+
+			msg, err := mail.ReadMessage(bytes.NewReader(n.message(notification)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bcc := msg.Header.Get("Bcc"); bcc != "" {
+				t.Errorf("Bcc = %q, want none", bcc)
+			}
+			subject, err := (&mime.WordDecoder{}).DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", subject, tt.wantSubject)
+			}
+
+			body := new(bytes.Buffer)
+			if _, err := body.ReadFrom(msg.Body); err != nil {
+				t.Fatal(err)
+			}
+			if body.String() != "the quarterly one" {
+				t.Errorf("body = %q, want the todo only", body.String())
+			}
+		})
+	}
+}
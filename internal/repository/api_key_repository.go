@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository is not cached, revoking a key has to take effect on
+// the next request
+func NewAPIKeyRepository(db *gorm.DB) model.APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+func (ar *apiKeyRepo) Create(ctx context.Context, key *model.APIKey) error {
+	if err := ar.db.WithContext(ctx).Create(key).Error; err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":  utils.Dump(ctx),
+			"name": key.Name,
+		}).Error(err)
+		return translateError(err, model.ErrAPIKeyNotFound)
+	}
+
+	return nil
+}
+
+func (ar *apiKeyRepo) FindByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	key := &model.APIKey{}
+
+	// an unknown key is a client error, which is not worth logging on every
+	// guessed key
+	err := ar.db.WithContext(ctx).Where("key_hash = ?", hash).Take(key).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, translateError(err, model.ErrAPIKeyNotFound)
+	case err != nil:
+		logrus.WithField("ctx", utils.Dump(ctx)).Error(err)
+		return nil, translateError(err, model.ErrAPIKeyNotFound)
+	}
+
+	return key, nil
+}
+
+func (ar *apiKeyRepo) TouchByID(ctx context.Context, ID int64, usedAt time.Time) error {
+	err := ar.db.WithContext(ctx).
+		Model(&model.APIKey{}).
+		Where("id = ?", ID).
+		UpdateColumn("last_used_at", usedAt).
+		Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return translateError(err, model.ErrAPIKeyNotFound)
+	}
+
+	return nil
+}
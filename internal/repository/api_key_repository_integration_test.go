@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"todo-app/internal/model"
+)
+
+func TestAPIKeyRepositoryFindByHash(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	repo := NewAPIKeyRepository(db.app)
+	ctx := context.Background()
+
+	key, secret, err := model.NewAPIKey("ci", f.userA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repo.FindByHash(ctx, model.HashAPIKey(secret))
+	if err != nil || found.ID != key.ID {
+		t.Fatalf("FindByHash() = %+v, %v, want key %d", found, err, key.ID)
+	}
+
+	// unknown keys are expected from clients and not logged as errors
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	if _, err := repo.FindByHash(ctx, model.HashAPIKey("unknown")); !errors.Is(err, model.ErrAPIKeyNotFound) {
+		t.Errorf("FindByHash() of an unknown key error = %v, want %v", err, model.ErrAPIKeyNotFound)
+	}
+	for _, entry := range hook.AllEntries() {
+		if entry.Level <= logrus.ErrorLevel {
+			t.Errorf("FindByHash() of an unknown key logged %q", entry.Message)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"todo-app/internal/model"
 
@@ -30,6 +31,14 @@ func (c *cacheRepo) Set(ctx context.Context, key, val string) error {
 	return c.redisClient.Set(ctx, key, val, 0).Err()
 }
 
+func (c *cacheRepo) SetEX(ctx context.Context, key, val string, ttl time.Duration) error {
+	return c.redisClient.Set(ctx, key, val, ttl).Err()
+}
+
+func (c *cacheRepo) SetNX(ctx context.Context, key, val string, ttl time.Duration) (bool, error) {
+	return c.redisClient.SetNX(ctx, key, val, ttl).Result()
+}
+
 func (c *cacheRepo) Delete(ctx context.Context, keys ...string) error {
 	return c.redisClient.Del(ctx, keys...).Err()
 }
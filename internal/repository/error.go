@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"todo-app/internal/errs"
+)
+
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// translateError classifies the errors of gorm and Postgres as domain
+// errors, a missing record becomes notFound. Domain errors and errors which
+// cannot be classified are returned as is.
+func translateError(err error, notFound *errs.Error) error {
+	domainErr := &errs.Error{}
+	pgErr := &pgconn.PgError{}
+
+	switch {
+	case errors.As(err, &domainErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound
+	case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		return errs.ErrConflict.Wrap(errors.New(pgErr.Detail))
+	case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+		return errs.ErrValidation.Wrap(errors.New(pgErr.Detail))
+	default:
+		return err
+	}
+}
@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type projectRepo struct {
+	db        *gorm.DB
+	cacheRepo model.CacheRepository
+}
+
+func NewProjectRepository(db *gorm.DB, cacheRepo model.CacheRepository) model.ProjectRepository {
+	return &projectRepo{
+		db:        db,
+		cacheRepo: cacheRepo,
+	}
+}
+
+func (pr *projectRepo) Create(ctx context.Context, project *model.Project) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":     utils.Dump(ctx),
+		"project": utils.Dump(project),
+	})
+
+	tenant := tenantFromContext(ctx)
+	project.WorkspaceID, project.OwnerID = tenant.owners()
+
+	err := tenantTx(ctx, pr.db, func(tx *gorm.DB) error {
+		return tx.Create(project).Error
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrProjectNotFound)
+	}
+
+	if err := pr.cacheRepo.Delete(ctx, pr.cacheHash(tenant)); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (pr *projectRepo) DeleteByID(ctx context.Context, ID int64) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
+
+	tenant := tenantFromContext(ctx)
+	taskIDs := []int64{}
+
+	// the project is only soft deleted, so its tasks, trashed ones included,
+	// are detached here rather than by the ON DELETE SET NULL of the key
+	err := tenantTx(ctx, pr.db, func(tx *gorm.DB) error {
+		res := tx.Scopes(tenantTableScope("projects", tenant)).Delete(&model.Project{}, ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Unscoped().
+			Model(&model.Task{}).
+			Scopes(tenantScope(tenant)).
+			Where("project_id = ?", ID).
+			Pluck("id", &taskIDs).
+			Error
+		if err != nil || len(taskIDs) == 0 {
+			return err
+		}
+
+		return tx.Unscoped().
+			Model(&model.Task{}).
+			Where("id IN ?", taskIDs).
+			UpdateColumns(map[string]interface{}{
+				"project_id": nil,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": time.Now(),
+			}).
+			Error
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrProjectNotFound)
+	}
+
+	tasks := &taskRepo{}
+	cacheKeys := []string{
+		pr.findByIDCacheKey(tenant, ID),
+		pr.cacheHash(tenant),
+		tasks.cacheHash(tenant),
+	}
+	for _, taskID := range taskIDs {
+		cacheKeys = append(cacheKeys, tasks.findByIDCacheKey(tenant, taskID))
+	}
+
+	if err := pr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (pr *projectRepo) FindByID(ctx context.Context, ID int64) (*model.Project, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
+
+	project, err := pr.findByID(ctx, ID)
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrProjectNotFound)
+	}
+
+	if err := pr.loadTaskCounts(ctx, project); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (pr *projectRepo) findByID(ctx context.Context, ID int64) (*model.Project, error) {
+	tenant := tenantFromContext(ctx)
+	cacheKey := pr.findByIDCacheKey(tenant, ID)
+
+	reply, err := pr.cacheRepo.Get(ctx, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply != "" {
+		project := &model.Project{}
+		if err := json.Unmarshal([]byte(reply), &project); err != nil {
+			return nil, err
+		}
+		return project, nil
+	}
+
+	project := &model.Project{}
+
+	err = tenantTx(ctx, pr.db, func(tx *gorm.DB) error {
+		return tx.Scopes(tenantTableScope("projects", tenant)).
+			Where("id = ?", ID).
+			Take(&project).
+			Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(project)
+	if err != nil {
+		logrus.Error(err)
+		return project, nil
+	}
+
+	if err := pr.cacheRepo.Set(ctx, cacheKey, string(bytes)); err != nil {
+		logrus.Error(err)
+	}
+
+	return project, nil
+}
+
+func (pr *projectRepo) FindAll(ctx context.Context, query model.GetProjectsQueryParams) ([]*model.Project, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	projects, err := pr.findAll(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrProjectNotFound)
+	}
+
+	if err := pr.loadTaskCounts(ctx, projects...); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (pr *projectRepo) findAll(ctx context.Context, query model.GetProjectsQueryParams) ([]*model.Project, error) {
+	tenant := tenantFromContext(ctx)
+	cacheHash := pr.cacheHash(tenant)
+	cacheKey := pr.findAllByQueryParams(tenant, query)
+	reply, err := pr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply != "" {
+		projects := []*model.Project{}
+		if err := json.Unmarshal([]byte(reply), &projects); err != nil {
+			return nil, err
+		}
+		return projects, nil
+	}
+
+	projects := []*model.Project{}
+
+	err = tenantTx(ctx, pr.db, func(tx *gorm.DB) error {
+		return tx.Scopes(tenantTableScope("projects", tenant)).
+			Order("id DESC").
+			Offset(int(model.Offset(query.Page, query.Size))).
+			Limit(int(query.Size)).
+			Find(&projects).
+			Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(projects)
+	if err != nil {
+		logrus.Error(err)
+		return projects, nil
+	}
+
+	if err := pr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
+		logrus.Error(err)
+	}
+
+	return projects, nil
+}
+
+func (pr *projectRepo) CountAll(ctx context.Context) (int64, error) {
+	logger := logrus.WithField("ctx", utils.Dump(ctx))
+
+	tenant := tenantFromContext(ctx)
+	cacheHash := pr.cacheHash(tenant)
+	cacheKey := pr.countAllCacheKey(tenant)
+	reply, err := pr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	if reply != "" {
+		count := int64(0)
+		if err := json.Unmarshal([]byte(reply), &count); err != nil {
+			logger.Error(err)
+			return 0, err
+		}
+		return count, nil
+	}
+
+	count := int64(0)
+	err = tenantTx(ctx, pr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.Project{}).
+			Scopes(tenantTableScope("projects", tenant)).
+			Count(&count).
+			Error
+	})
+	if err != nil {
+		logger.Error(err)
+		return int64(0), translateError(err, model.ErrProjectNotFound)
+	}
+
+	bytes, err := json.Marshal(count)
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	if err := pr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
+		logger.Error(err)
+	}
+
+	return count, nil
+}
+
+func (pr *projectRepo) Update(ctx context.Context, project *model.Project) (*model.Project, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":     utils.Dump(ctx),
+		"project": utils.Dump(project),
+	})
+
+	tenant := tenantFromContext(ctx)
+
+	err := tenantTx(ctx, pr.db, func(tx *gorm.DB) error {
+		res := tx.Scopes(tenantTableScope("projects", tenant)).Updates(project)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrProjectNotFound)
+	}
+
+	cacheKeys := []string{
+		pr.cacheHash(tenant),
+		pr.findByIDCacheKey(tenant, project.ID),
+	}
+
+	if err := pr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return pr.FindByID(ctx, project.ID)
+}
+
+// loadTaskCounts counts the caller's tasks of the projects. The counts are
+// not cached with the project since every task write would have to
+// invalidate them.
+func (pr *projectRepo) loadTaskCounts(ctx context.Context, projects ...*model.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	IDs := []int64{}
+	for _, project := range projects {
+		IDs = append(IDs, project.ID)
+	}
+
+	rows := []struct {
+		ProjectID int64
+		Total     int64
+		Open      int64
+	}{}
+	err := tenantTx(ctx, pr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.Task{}).
+			Select("project_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE NOT completed) AS open").
+			Scopes(tenantScope(tenantFromContext(ctx))).
+			Where("project_id IN ?", IDs).
+			Group("project_id").
+			Scan(&rows).
+			Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		project.TaskCount, project.OpenTaskCount = 0, 0
+		for _, row := range rows {
+			if row.ProjectID == project.ID {
+				project.TaskCount, project.OpenTaskCount = row.Total, row.Open
+			}
+		}
+	}
+
+	return nil
+}
+
+func (pr *projectRepo) cacheHash(tenant taskTenant) string {
+	return tenant.cacheKey() + ":project"
+}
+
+func (pr *projectRepo) findByIDCacheKey(tenant taskTenant, ID int64) string {
+	return fmt.Sprintf("%s:%d", pr.cacheHash(tenant), ID)
+}
+
+func (pr *projectRepo) findAllByQueryParams(tenant taskTenant, query model.GetProjectsQueryParams) string {
+	return fmt.Sprintf("%s:page:%d:size:%d", pr.cacheHash(tenant), query.Page, query.Size)
+}
+
+func (pr *projectRepo) countAllCacheKey(tenant taskTenant) string {
+	return pr.cacheHash(tenant) + ":count"
+}
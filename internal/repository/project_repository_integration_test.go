@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"todo-app/internal/model"
+)
+
+func TestProjectRepositoryCRUD(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	ctx := f.workspace(f.userA, f.workspaceA)
+	repo := NewProjectRepository(db.app, nopCacheRepo{})
+
+	website := model.CreateProjectInput{Name: "website"}.ToModel()
+	blog := model.CreateProjectInput{Name: "blog"}.ToModel()
+	for _, project := range []*model.Project{website, blog} {
+		if err := repo.Create(ctx, project); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if website.WorkspaceID == nil || *website.WorkspaceID != f.workspaceA || website.OwnerID != nil {
+		t.Errorf("created project = %+v, want it to belong to workspace %d", website, f.workspaceA)
+	}
+
+	project, err := repo.FindByID(ctx, website.ID)
+	if err != nil || project.Name != "website" {
+		t.Fatalf("FindByID() = %+v, %v, want website", project, err)
+	}
+
+	project, err = repo.Update(ctx, model.UpdateProjectInput{Name: "homepage", Description: "the landing page"}.ToModel(website.ID))
+	if err != nil || project.Name != "homepage" || project.Description != "the landing page" {
+		t.Fatalf("Update() = %+v, %v, want homepage", project, err)
+	}
+
+	missing := model.CreateProjectInput{}.ToModel().ID
+	if _, err := repo.Update(ctx, model.UpdateProjectInput{Name: "x"}.ToModel(missing)); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("Update() of a missing project error = %v, want %v", err, model.ErrProjectNotFound)
+	}
+	if _, err := repo.FindByID(ctx, missing); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("FindByID() of a missing project error = %v, want %v", err, model.ErrProjectNotFound)
+	}
+
+	projects, err := repo.FindAll(ctx, model.GetProjectsQueryParams{Pagination: model.Pagination{Page: 1, Size: 1}})
+	if err != nil || len(projects) != 1 || projects[0].ID != blog.ID {
+		t.Errorf("FindAll() of page 1 = %+v, %v, want the newest project %d", projects, err, blog.ID)
+	}
+
+	count, err := repo.CountAll(ctx)
+	if err != nil || count != 2 {
+		t.Errorf("CountAll() = %d, %v, want 2", count, err)
+	}
+
+	if err := repo.DeleteByID(ctx, blog.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindByID(ctx, blog.ID); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("FindByID() of a deleted project error = %v, want %v", err, model.ErrProjectNotFound)
+	}
+	if err := repo.DeleteByID(ctx, blog.ID); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("DeleteByID() twice error = %v, want %v", err, model.ErrProjectNotFound)
+	}
+	if count, err := repo.CountAll(ctx); err != nil || count != 1 {
+		t.Errorf("CountAll() after the delete = %d, %v, want 1", count, err)
+	}
+}
+
+func TestProjectRepositoryTaskCounts(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	ctx := f.workspace(f.userA, f.workspaceA)
+	projectRepo := NewProjectRepository(db.app, nopCacheRepo{})
+	taskRepo := NewTaskRepository(db.app, nopCacheRepo{})
+
+	website := model.CreateProjectInput{Name: "website"}.ToModel()
+	empty := model.CreateProjectInput{Name: "empty"}.ToModel()
+	for _, project := range []*model.Project{website, empty} {
+		if err := projectRepo.Create(ctx, project); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	createTestTask(t, taskRepo, ctx, model.CreateTaskInput{Title: "open", ProjectID: &website.ID})
+	createTestTask(t, taskRepo, ctx, model.CreateTaskInput{Title: "in progress", Status: model.TaskStatusInProgress, ProjectID: &website.ID})
+	createTestTask(t, taskRepo, ctx, model.CreateTaskInput{Title: "done", Status: model.TaskStatusDone, ProjectID: &website.ID})
+	createTestTask(t, taskRepo, ctx, model.CreateTaskInput{Title: "without a project"})
+	trashed := createTestTask(t, taskRepo, ctx, model.CreateTaskInput{Title: "trashed", ProjectID: &website.ID})
+	if err := taskRepo.DeleteByID(ctx, trashed.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := projectRepo.FindByID(ctx, website.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.TaskCount != 3 || project.OpenTaskCount != 2 {
+		t.Errorf("FindByID() counts = %d total %d open, want 3 total 2 open", project.TaskCount, project.OpenTaskCount)
+	}
+
+	projects, err := projectRepo.FindAll(ctx, model.GetProjectsQueryParams{Pagination: model.Pagination{Page: 1, Size: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, project := range projects {
+		want := [2]int64{}
+		if project.ID == website.ID {
+			want = [2]int64{3, 2}
+		}
+		if got := [2]int64{project.TaskCount, project.OpenTaskCount}; got != want {
+			t.Errorf("FindAll() counts of %s = %v, want %v", project.Name, got, want)
+		}
+	}
+}
+
+func TestProjectRepositoryDeleteWithTasks(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	ctx := f.workspace(f.userA, f.workspaceA)
+	projectRepo := NewProjectRepository(db.app, nopCacheRepo{})
+	taskRepo := NewTaskRepository(db.app, nopCacheRepo{})
+
+	website := model.CreateProjectInput{Name: "website"}.ToModel()
+	if err := projectRepo.Create(ctx, website); err != nil {
+		t.Fatal(err)
+	}
+
+	live := createTestTask(t, taskRepo, ctx, model.CreateTaskInput{Title: "live", ProjectID: &website.ID})
+	trashed := createTestTask(t, taskRepo, ctx, model.CreateTaskInput{Title: "trashed", ProjectID: &website.ID})
+	if err := taskRepo.DeleteByID(ctx, trashed.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := projectRepo.DeleteByID(ctx, website.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tasks are kept, detached from the project at a new version
+	task, err := taskRepo.FindByID(ctx, live.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.ProjectID != nil || task.Version <= live.Version {
+		t.Errorf("task of the deleted project = %+v, want it without a project at a new version", task)
+	}
+
+	tasks, err := taskRepo.FindAllTrashed(ctx, model.GetTrashedTasksQueryParams{Pagination: model.Pagination{Page: 1, Size: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskIDs(tasks); !slices.Equal(got, []int64{trashed.ID}) || tasks[0].ProjectID != nil {
+		t.Errorf("FindAllTrashed() = %+v, want the trashed task without a project", tasks)
+	}
+
+	projectTasks, err := taskRepo.FindAll(ctx, model.GetTasksQueryParams{
+		Pagination: model.Pagination{Page: 1, Size: 10},
+		ProjectID:  &website.ID,
+	})
+	if err != nil || len(projectTasks) != 0 {
+		t.Errorf("FindAll() of the deleted project = %v, %v, want no task", taskIDs(projectTasks), err)
+	}
+
+	// nor can tasks be moved into the deleted project
+	err = taskRepo.Create(ctx, model.CreateTaskInput{Title: "late", Status: model.TaskStatusTodo, ProjectID: &website.ID}.ToModel())
+	if !errors.Is(err, model.ErrTaskProjectInvalid) {
+		t.Errorf("Create() in the deleted project error = %v, want %v", err, model.ErrTaskProjectInvalid)
+	}
+}
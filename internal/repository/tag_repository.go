@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type tagRepo struct {
+	db        *gorm.DB
+	cacheRepo model.CacheRepository
+}
+
+func NewTagRepository(db *gorm.DB, cacheRepo model.CacheRepository) model.TagRepository {
+	return &tagRepo{
+		db:        db,
+		cacheRepo: cacheRepo,
+	}
+}
+
+func (tr *tagRepo) Create(ctx context.Context, tag *model.Tag) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"tag": utils.Dump(tag),
+	})
+
+	tenant := tenantFromContext(ctx)
+	tag.WorkspaceID, tag.OwnerID = tenant.owners()
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Create(tag).Error
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTagNotFound)
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, tr.cacheHash(tenant)); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (tr *tagRepo) DeleteByID(ctx context.Context, ID int64) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
+
+	tenant := tenantFromContext(ctx)
+
+	// task_tags rows are removed by the foreign key cascade
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		res := tx.Scopes(tenantTableScope("tags", tenant)).Delete(&model.Tag{}, ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTagNotFound)
+	}
+
+	cacheKeys := []string{
+		tr.findByIDCacheKey(tenant, ID),
+		tr.cacheHash(tenant),
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (tr *tagRepo) FindByID(ctx context.Context, ID int64) (*model.Tag, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
+
+	tenant := tenantFromContext(ctx)
+	cacheKey := tr.findByIDCacheKey(tenant, ID)
+
+	reply, err := tr.cacheRepo.Get(ctx, cacheKey)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if reply != "" {
+		tag := &model.Tag{}
+		if err := json.Unmarshal([]byte(reply), &tag); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		return tag, nil
+	}
+
+	tag := &model.Tag{}
+
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Scopes(tenantTableScope("tags", tenant)).
+			Where("id = ?", ID).
+			Take(&tag).
+			Error
+	})
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTagNotFound)
+	}
+
+	bytes, err := json.Marshal(tag)
+	if err != nil {
+		logger.Error(err)
+		return tag, nil
+	}
+
+	if err := tr.cacheRepo.Set(ctx, cacheKey, string(bytes)); err != nil {
+		logger.Error(err)
+	}
+
+	return tag, nil
+}
+
+// FindByName returns a nil tag when no tag is named name
+func (tr *tagRepo) FindByName(ctx context.Context, name string) (*model.Tag, error) {
+	tag := &model.Tag{}
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Scopes(tenantTableScope("tags", tenantFromContext(ctx))).
+			Where("name = ?", name).
+			Take(&tag).
+			Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		logrus.WithFields(logrus.Fields{
+			"ctx":  utils.Dump(ctx),
+			"name": name,
+		}).Error(err)
+		return nil, translateError(err, model.ErrTagNotFound)
+	}
+
+	return tag, nil
+}
+
+func (tr *tagRepo) FindAll(ctx context.Context, query model.GetTagsQueryParams) ([]*model.Tag, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tenant := tenantFromContext(ctx)
+	cacheHash := tr.cacheHash(tenant)
+	cacheKey := tr.findAllByQueryParams(tenant, query)
+	reply, err := tr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if reply != "" {
+		tags := []*model.Tag{}
+		if err := json.Unmarshal([]byte(reply), &tags); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		return tags, nil
+	}
+
+	tags := []*model.Tag{}
+
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Scopes(tenantTableScope("tags", tenant)).
+			Order("name ASC").
+			Offset(int(model.Offset(query.Page, query.Size))).
+			Limit(int(query.Size)).
+			Find(&tags).
+			Error
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTagNotFound)
+	}
+
+	bytes, err := json.Marshal(tags)
+	if err != nil {
+		logger.Error(err)
+		return tags, nil
+	}
+
+	if err := tr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
+		logger.Error(err)
+	}
+
+	return tags, nil
+}
+
+func (tr *tagRepo) CountAll(ctx context.Context) (int64, error) {
+	logger := logrus.WithField("ctx", utils.Dump(ctx))
+
+	tenant := tenantFromContext(ctx)
+	cacheHash := tr.cacheHash(tenant)
+	cacheKey := tr.countAllCacheKey(tenant)
+	reply, err := tr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	if reply != "" {
+		count := int64(0)
+		if err := json.Unmarshal([]byte(reply), &count); err != nil {
+			logger.Error(err)
+			return 0, err
+		}
+		return count, nil
+	}
+
+	count := int64(0)
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.Tag{}).
+			Scopes(tenantTableScope("tags", tenant)).
+			Count(&count).
+			Error
+	})
+	if err != nil {
+		logger.Error(err)
+		return int64(0), translateError(err, model.ErrTagNotFound)
+	}
+
+	bytes, err := json.Marshal(count)
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	if err := tr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
+		logger.Error(err)
+	}
+
+	return count, nil
+}
+
+func (tr *tagRepo) Update(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"tag": utils.Dump(tag),
+	})
+
+	tenant := tenantFromContext(ctx)
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		res := tx.Scopes(tenantTableScope("tags", tenant)).Updates(tag)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTagNotFound)
+	}
+
+	cacheKeys := []string{
+		tr.cacheHash(tenant),
+		tr.findByIDCacheKey(tenant, tag.ID),
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tr.FindByID(ctx, tag.ID)
+}
+
+func (tr *tagRepo) FindTaskIDsByTagID(ctx context.Context, ID int64) ([]int64, error) {
+	taskIDs := []int64{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.TaskTag{}).
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Scopes(tenantTableScope("tags", tenantFromContext(ctx))).
+			Where("task_tags.tag_id = ?", ID).
+			Pluck("task_tags.task_id", &taskIDs).
+			Error
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return nil, translateError(err, model.ErrTagNotFound)
+	}
+
+	return taskIDs, nil
+}
+
+func (tr *tagRepo) cacheHash(tenant taskTenant) string {
+	return tenant.cacheKey() + ":tag"
+}
+
+func (tr *tagRepo) findByIDCacheKey(tenant taskTenant, ID int64) string {
+	return fmt.Sprintf("%s:%d", tr.cacheHash(tenant), ID)
+}
+
+func (tr *tagRepo) findAllByQueryParams(tenant taskTenant, query model.GetTagsQueryParams) string {
+	return fmt.Sprintf("%s:page:%d:size:%d", tr.cacheHash(tenant), query.Page, query.Size)
+}
+
+func (tr *tagRepo) countAllCacheKey(tenant taskTenant) string {
+	return tr.cacheHash(tenant) + ":count"
+}
@@ -2,13 +2,18 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	"todo-app/internal/model"
 	"todo-app/internal/utils"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type taskRepo struct {
@@ -30,22 +35,15 @@ func (tr *taskRepo) Create(ctx context.Context, task *model.Task) error {
 		"task": utils.Dump(task),
 	})
 
-	err := tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(task).Error; err != nil {
-			return err
-
-		}
-		return nil
+	cacheKeys := []string{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) (err error) {
+		cacheKeys, err = tr.create(tx, task)
+		return err
 	})
 
 	if err != nil {
 		logger.Error(err)
-		return err
-	}
-
-	cacheKeys := []string{
-		tr.cacheHash(),
-		tr.countAllCacheKey(),
+		return translateError(err, model.ErrTaskNotFound)
 	}
 
 	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
@@ -56,28 +54,66 @@ func (tr *taskRepo) Create(ctx context.Context, task *model.Task) error {
 	return nil
 }
 
-func (tr *taskRepo) DeleteByID(ctx context.Context, ID int64) error {
+// create inserts the task within tx for the tenant of the caller and returns
+// the cache keys it outdates
+func (tr *taskRepo) create(tx *gorm.DB, task *model.Task) ([]string, error) {
+	tenant := tenantFromContext(tx.Statement.Context)
+	task.WorkspaceID, task.OwnerID = tenant.WorkspaceID, tenant.OwnerID
+
+	if err := tr.checkProject(tx, tenant, task.ProjectID); err != nil {
+		return nil, err
+	}
+
+	if task.Series != nil {
+		if err := tx.Create(task.Series).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tr.appendPosition(tx, task); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Omit(clause.Associations).Create(task).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tr.replaceTags(tx, task); err != nil {
+		return nil, err
+	}
+
+	if err := tr.recordEvent(tx, task.ID, model.TaskEventCreated, model.DiffTasks(nil, task)); err != nil {
+		return nil, err
+	}
+
+	cacheKeys := []string{
+		tr.cacheHash(tenant),
+	}
+
+	if task.ParentID != nil {
+		cacheKeys = append(cacheKeys, tr.findByIDCacheKey(tenant, *task.ParentID))
+	}
+
+	return cacheKeys, nil
+}
+
+func (tr *taskRepo) DeleteByID(ctx context.Context, ID int64, version int64) error {
 
 	logger := logrus.WithFields(logrus.Fields{
-		"ctx": utils.Dump(ctx),
-		"ID":  ID,
+		"ctx":     utils.Dump(ctx),
+		"ID":      ID,
+		"version": version,
 	})
 
-	err := tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Delete(&model.Task{}, ID).Error; err != nil {
-			return err
-		}
-		return nil
+	cacheKeys := []string{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) (err error) {
+		cacheKeys, err = tr.delete(tx, ID, version)
+		return err
 	})
 
 	if err != nil {
 		logger.Error(err)
-		return err
-	}
-
-	cacheKeys := []string{
-		tr.findByIDCacheKey(ID),
-		tr.cacheHash(),
+		return translateError(err, model.ErrTaskNotFound)
 	}
 
 	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
@@ -88,13 +124,48 @@ func (tr *taskRepo) DeleteByID(ctx context.Context, ID int64) error {
 	return nil
 }
 
+// delete soft deletes the task within tx and returns the cache keys it
+// outdates
+func (tr *taskRepo) delete(tx *gorm.DB, ID int64, version int64) ([]string, error) {
+	tenant := tenantFromContext(tx.Statement.Context)
+
+	db := tx.Scopes(tenantScope(tenant))
+	if version != 0 {
+		db = db.Where("version = ?", version)
+	}
+
+	res := db.Delete(&model.Task{}, ID)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, tr.versionConflict(tx, ID)
+	}
+
+	if err := tr.recordEvent(tx, ID, model.TaskEventDeleted, nil); err != nil {
+		return nil, err
+	}
+
+	parentCacheKeys, err := tr.parentCacheKeys(tx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKeys := []string{
+		tr.findByIDCacheKey(tenant, ID),
+		tr.cacheHash(tenant),
+	}
+	return append(cacheKeys, parentCacheKeys...), nil
+}
+
 func (tr *taskRepo) FindByID(ctx context.Context, ID int64) (*model.Task, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"ctx": utils.Dump(ctx),
 		"ID":  ID,
 	})
 
-	cacheKey := tr.findByIDCacheKey(ID)
+	tenant := tenantFromContext(ctx)
+	cacheKey := tr.findByIDCacheKey(tenant, ID)
 
 	reply, err := tr.cacheRepo.Get(ctx, cacheKey)
 	if err != nil {
@@ -113,10 +184,25 @@ func (tr *taskRepo) FindByID(ctx context.Context, ID int64) (*model.Task, error)
 
 	task := &model.Task{}
 
-	err = tr.db.WithContext(ctx).Where("id = ?", ID).Take(&task).Error
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		err := tx.Scopes(tenantScope(tenant)).
+			Preload("Series").
+			// ordered so that the ETag of the task is stable
+			Preload("Tags", func(db *gorm.DB) *gorm.DB {
+				return db.Order("name ASC")
+			}).
+			Where("id = ?", ID).
+			Take(&task).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tr.loadProgress(tx, task)
+	})
 	if err != nil {
 		logger.Error(err)
-		return nil, err
+		return nil, translateError(err, model.ErrTaskNotFound)
 	}
 
 	bytes, err := json.Marshal(task)
@@ -139,8 +225,9 @@ func (tr *taskRepo) FindAll(ctx context.Context, query model.GetTasksQueryParams
 		"query": utils.Dump(query),
 	})
 
-	cacheHash := tr.cacheHash()
-	cacheKey := tr.findAllByQueryParams(query)
+	tenant := tenantFromContext(ctx)
+	cacheHash := tr.cacheHash(tenant)
+	cacheKey := tr.findAllByQueryParams(tenant, query)
 	reply, err := tr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
 
 	if err != nil {
@@ -159,18 +246,96 @@ func (tr *taskRepo) FindAll(ctx context.Context, query model.GetTasksQueryParams
 
 	tasks := []*model.Task{}
 
-	err = tr.db.WithContext(ctx).
-		Order("id DESC").
-		Offset(int(model.Offset(query.Page, query.Size))).
-		Limit(int(query.Size)).
-		Find(&tasks).
-		Error
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		err := tx.Scopes(tenantScope(tenant), tr.filterScope(tenant, query)).
+			Order(query.OrderClause()).
+			Offset(int(model.Offset(query.Page, query.Size))).
+			Limit(int(query.Size)).
+			Preload("Series").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tr.loadProgress(tx, tasks...)
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	bytes, err := json.Marshal(tasks)
+	if err != nil {
+		logger.Error(err)
+		return tasks, nil
+	}
+
+	if err := tr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
+		logger.Error(err)
+	}
+
+	return tasks, nil
+}
+
+// FindAllByCursor returns up to query.Size+1 tasks after the cursor in the
+// cursor's direction, the extra row tells the caller whether another page
+// exists. Backward pages are returned in reverse display order.
+func (tr *taskRepo) FindAllByCursor(ctx context.Context, query model.GetTasksQueryParams) ([]*model.Task, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	cursor, err := query.DecodeCursor()
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	tenant := tenantFromContext(ctx)
+	cacheHash := tr.cacheHash(tenant)
+	cacheKey := tr.findAllByCursorCacheKey(tenant, query)
+	reply, err := tr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
 
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
+	if reply != "" {
+		tasks := []*model.Task{}
+		if err := json.Unmarshal([]byte(reply), &tasks); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		return tasks, nil
+	}
+
+	tasks := []*model.Task{}
+
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		err := tx.Scopes(tenantScope(tenant), tr.filterScope(tenant, query), tr.cursorScope(query, cursor)).
+			Limit(int(query.Size) + 1).
+			Preload("Series").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tr.loadProgress(tx, tasks...)
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
 	bytes, err := json.Marshal(tasks)
 	if err != nil {
 		logger.Error(err)
@@ -184,11 +349,16 @@ func (tr *taskRepo) FindAll(ctx context.Context, query model.GetTasksQueryParams
 	return tasks, nil
 }
 
-func (tr *taskRepo) CountAll(ctx context.Context) (int64, error) {
-	logger := logrus.WithField("ctx", utils.Dump(ctx))
+func (tr *taskRepo) CountAll(ctx context.Context, query model.GetTasksQueryParams) (int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
 
-	cacheKey := tr.countAllCacheKey()
-	reply, err := tr.cacheRepo.Get(ctx, cacheKey)
+	tenant := tenantFromContext(ctx)
+	cacheHash := tr.cacheHash(tenant)
+	cacheKey := tr.countAllCacheKey(tenant, query)
+	reply, err := tr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
 	if err != nil {
 		logger.Error(err)
 		return 0, err
@@ -204,13 +374,15 @@ func (tr *taskRepo) CountAll(ctx context.Context) (int64, error) {
 	}
 
 	count := int64(0)
-	err = tr.db.WithContext(ctx).
-		Model(model.Task{}).
-		Count(&count).
-		Error
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.Task{}).
+			Scopes(tenantScope(tenant), tr.filterScope(tenant, query)).
+			Count(&count).
+			Error
+	})
 	if err != nil {
-		logrus.WithField("ctx", utils.Dump(ctx)).Error(err)
-		return int64(0), err
+		logger.Error(err)
+		return int64(0), translateError(err, model.ErrTaskNotFound)
 	}
 
 	bytes, err := json.Marshal(count)
@@ -219,7 +391,7 @@ func (tr *taskRepo) CountAll(ctx context.Context) (int64, error) {
 		return 0, err
 	}
 
-	if err := tr.cacheRepo.Set(ctx, cacheKey, string(bytes)); err != nil {
+	if err := tr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
 		logger.Error(err)
 	}
 
@@ -233,45 +405,1644 @@ func (tr *taskRepo) Update(ctx context.Context, task *model.Task) (*model.Task,
 		"task": utils.Dump(task),
 	})
 
-	err := tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Updates(task).Error; err != nil {
+	cacheKeys := []string{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) (err error) {
+		cacheKeys, err = tr.update(tx, task)
+		return err
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tr.FindByID(ctx, task.ID)
+}
+
+// update writes the task within tx while it is still at task.Version and
+// returns the cache keys it outdates
+func (tr *taskRepo) update(tx *gorm.DB, task *model.Task) ([]string, error) {
+	tenant := tenantFromContext(tx.Statement.Context)
+
+	// the task is read before writing it so that its history gets the diff,
+	// reading it also makes sure that it belongs to the caller
+	before := &model.Task{}
+	err := tx.Scopes(tenantScope(tenant)).Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Where("id = ?", task.ID).Take(before).Error
+	if err != nil {
+		return nil, err
+	}
+	if before.Version != task.Version {
+		return nil, model.ErrTaskVersionMismatch
+	}
+
+	if !sameID(before.ProjectID, task.ProjectID) {
+		if err := tr.checkProject(tx, tenant, task.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	// moving remind_at or due_at rearms the matching notification
+	err = tx.Model(&model.Task{}).
+		Where("id = ? AND remind_at IS DISTINCT FROM ?", task.ID, task.RemindAt).
+		UpdateColumn("reminded_at", nil).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Model(&model.Task{}).
+		Where("id = ? AND due_at IS DISTINCT FROM ?", task.ID, task.DueAt).
+		UpdateColumn("due_notified_at", nil).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	// the columns are listed so that zero values are written as well
+	version := task.Version
+	task.Version++
+	res := tx.Model(task).
+		Where("version = ?", version).
+		Select([]string{
+			"title", "todo", "status", "started_at", "completed_at", "priority",
+			"due_at", "remind_at", "project_id", "version", "updated_at",
+		}).
+		Omit(clause.Associations).
+		Updates(task)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, tr.versionConflict(tx, task.ID)
+	}
+
+	if err := tr.replaceTags(tx, task); err != nil {
+		return nil, err
+	}
+
+	if task.Tags == nil {
+		task.Tags = before.Tags
+	}
+
+	if changes := model.DiffTasks(before, task); len(changes) > 0 {
+		if err := tr.recordEvent(tx, task.ID, model.TaskEventUpdated, changes); err != nil {
+			return nil, err
+		}
+	}
+
+	if !before.Completed && task.Status == model.TaskStatusDone {
+		if err := tr.createNextOccurrence(tx, task.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	parentCacheKeys, err := tr.parentCacheKeys(tx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKeys := []string{
+		tr.cacheHash(tenant),
+		tr.findByIDCacheKey(tenant, task.ID),
+	}
+	return append(cacheKeys, parentCacheKeys...), nil
+}
+
+// FindAllTrashed is not cached, the trash is rarely browsed and has to reflect
+// deletes and restores immediately.
+func (tr *taskRepo) FindAllTrashed(ctx context.Context, query model.GetTrashedTasksQueryParams) ([]*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tasks := []*model.Task{}
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Scopes(tenantScope(tenantFromContext(ctx))).
+			Where("deleted_at IS NOT NULL").
+			Order("deleted_at DESC, id DESC").
+			Offset(int(model.Offset(query.Page, query.Size))).
+			Limit(int(query.Size)).
+			Preload("Series").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+		if err != nil {
 			return err
 		}
 
-		return nil
+		return tr.loadProgress(tx, tasks...)
 	})
 
 	if err != nil {
 		logger.Error(err)
-		return nil, err
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	return tasks, nil
+}
+
+func (tr *taskRepo) CountAllTrashed(ctx context.Context) (int64, error) {
+	count := int64(0)
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Unscoped().
+			Model(&model.Task{}).
+			Scopes(tenantScope(tenantFromContext(ctx))).
+			Where("deleted_at IS NOT NULL").
+			Count(&count).
+			Error
+	})
+	if err != nil {
+		logrus.WithField("ctx", utils.Dump(ctx)).Error(err)
+		return int64(0), translateError(err, model.ErrTaskNotFound)
+	}
+
+	return count, nil
+}
+
+func (tr *taskRepo) RestoreByID(ctx context.Context, ID int64) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
+
+	tenant := tenantFromContext(ctx)
+	parentCacheKeys := []string{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) (err error) {
+		res := tx.Unscoped().
+			Model(&model.Task{}).
+			Scopes(tenantScope(tenant)).
+			Where("id = ? AND deleted_at IS NOT NULL", ID).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tr.recordEvent(tx, ID, model.TaskEventRestored, nil); err != nil {
+			return err
+		}
+
+		parentCacheKeys, err = tr.parentCacheKeys(tx, ID)
+		return err
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTaskNotFound)
 	}
 
 	cacheKeys := []string{
-		tr.cacheHash(),
-		tr.countAllCacheKey(),
-		tr.findByIDCacheKey(task.ID),
+		tr.findByIDCacheKey(tenant, ID),
+		tr.cacheHash(tenant),
 	}
+	cacheKeys = append(cacheKeys, parentCacheKeys...)
 
 	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
 		logger.Error(err)
-		return nil, err
+		return err
 	}
 
-	return tr.FindByID(ctx, task.ID)
+	return nil
 }
 
-func (tr *taskRepo) cacheHash() string {
-	return "task"
-}
+func (tr *taskRepo) PurgeByID(ctx context.Context, ID int64) error {
 
-func (tr *taskRepo) findByIDCacheKey(ID int64) string {
-	return fmt.Sprintf("task:%d", ID)
-}
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
 
-func (tr *taskRepo) findAllByQueryParams(query model.GetTasksQueryParams) string {
-	return fmt.Sprintf("task:page:%d:size:%d", query.Page, query.Size)
-}
+	tenant := tenantFromContext(ctx)
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		res := tx.Unscoped().
+			Scopes(tenantScope(tenant)).
+			Where("id = ? AND deleted_at IS NOT NULL", ID).
+			Delete(&model.Task{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
 
-func (tr *taskRepo) countAllCacheKey() string {
-	return "task:count"
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTaskNotFound)
+	}
+
+	// subtasks of the purged task are removed by the foreign key cascade
+	if err := tr.cacheRepo.Delete(ctx, tr.cacheHash(tenant)); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// CompleteByID completes the task, and with cascade all of its open
+// descendants as well
+func (tr *taskRepo) CompleteByID(ctx context.Context, ID int64, cascade bool) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":     utils.Dump(ctx),
+		"ID":      ID,
+		"cascade": cascade,
+	})
+
+	cacheKeys := []string{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) (err error) {
+		cacheKeys, err = tr.complete(tx, ID, cascade)
+		return err
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTaskNotFound)
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// complete completes the task within tx and returns the cache keys it
+// outdates
+func (tr *taskRepo) complete(tx *gorm.DB, ID int64, cascade bool) ([]string, error) {
+	tenant := tenantFromContext(tx.Statement.Context)
+	now := time.Now()
+	completedIDs := []int64{ID}
+
+	status := model.TaskStatus("")
+	err := tx.Model(&model.Task{}).Scopes(tenantScope(tenant)).Select("status").Where("id = ?", ID).Scan(&status).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := tx.Model(&model.Task{}).
+		Scopes(tenantScope(tenant)).
+		Where("id = ?", ID).
+		Updates(map[string]interface{}{
+			"status":       model.TaskStatusDone,
+			"completed_at": gorm.Expr("COALESCE(completed_at, ?)", now),
+			"version":      gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if status != model.TaskStatusDone {
+		if err := tr.createNextOccurrence(tx, ID); err != nil {
+			return nil, err
+		}
+	}
+
+	completed := []completedTask{{ID: ID, Status: status}}
+	if cascade {
+		// subtasks belong to the tenant of their parent. The self join
+		// returns the status each descendant had before.
+		descendants := []completedTask{}
+		err := tx.Raw(`
+			WITH RECURSIVE descendants AS (
+				SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+				UNION ALL
+				SELECT t.id FROM tasks t
+				JOIN descendants d ON t.parent_id = d.id
+				WHERE t.deleted_at IS NULL
+			)
+			UPDATE tasks SET status = ?, completed_at = ?, version = tasks.version + 1, updated_at = ?
+			FROM tasks old
+			WHERE old.id = tasks.id AND tasks.id IN (SELECT id FROM descendants) AND tasks.status <> ?
+			RETURNING tasks.id, old.status`, ID, model.TaskStatusDone, now, now, model.TaskStatusDone).
+			Scan(&descendants).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		completed = append(completed, descendants...)
+	}
+
+	for _, task := range completed {
+		if task.Status == model.TaskStatusDone {
+			continue
+		}
+
+		changes := model.TaskChanges{
+			"status": {From: task.Status, To: model.TaskStatusDone},
+		}
+		if err := tr.recordEvent(tx, task.ID, model.TaskEventCompleted, changes); err != nil {
+			return nil, err
+		}
+
+		if task.ID != ID {
+			completedIDs = append(completedIDs, task.ID)
+		}
+	}
+
+	parentCacheKeys, err := tr.parentCacheKeys(tx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the parents of the descendants are all part of completedIDs
+	cacheKeys := []string{tr.cacheHash(tenant)}
+	for _, completedID := range completedIDs {
+		cacheKeys = append(cacheKeys, tr.findByIDCacheKey(tenant, completedID))
+	}
+	return append(cacheKeys, parentCacheKeys...), nil
+}
+
+// FindAllDue is not cached since the due window moves with the clock
+func (tr *taskRepo) FindAllDue(ctx context.Context, query model.DueTasksQuery) ([]*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tasks := []*model.Task{}
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		err := tx.Scopes(tenantScope(tenantFromContext(ctx)), tr.dueScope(query)).
+			Order("due_at ASC, id ASC").
+			Offset(int(model.Offset(query.Page, query.Size))).
+			Limit(int(query.Size)).
+			Preload("Series").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tr.loadProgress(tx, tasks...)
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	return tasks, nil
+}
+
+func (tr *taskRepo) CountAllDue(ctx context.Context, query model.DueTasksQuery) (int64, error) {
+	count := int64(0)
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.Task{}).
+			Scopes(tenantScope(tenantFromContext(ctx)), tr.dueScope(query)).
+			Count(&count).
+			Error
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":   utils.Dump(ctx),
+			"query": utils.Dump(query),
+		}).Error(err)
+		return int64(0), translateError(err, model.ErrTaskNotFound)
+	}
+
+	return count, nil
+}
+
+// FindEventsByTaskID is not cached, every write of the task appends to it.
+// Events are returned newest first, trashed tasks keep their history.
+func (tr *taskRepo) FindEventsByTaskID(ctx context.Context, taskID int64, query model.GetTaskHistoryQueryParams) ([]*model.TaskEvent, error) {
+	events := []*model.TaskEvent{}
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Where("task_id = ?", taskID).
+			Where("task_id IN (?)", tr.tenantTaskIDs(ctx)).
+			Order("created_at DESC, id DESC").
+			Offset(int(model.Offset(query.Page, query.Size))).
+			Limit(int(query.Size)).
+			Find(&events).
+			Error
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":    utils.Dump(ctx),
+			"taskID": taskID,
+			"query":  utils.Dump(query),
+		}).Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	return events, nil
+}
+
+func (tr *taskRepo) CountEventsByTaskID(ctx context.Context, taskID int64) (int64, error) {
+	count := int64(0)
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.TaskEvent{}).
+			Where("task_id = ?", taskID).
+			Where("task_id IN (?)", tr.tenantTaskIDs(ctx)).
+			Count(&count).
+			Error
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":    utils.Dump(ctx),
+			"taskID": taskID,
+		}).Error(err)
+		return int64(0), translateError(err, model.ErrTaskNotFound)
+	}
+
+	return count, nil
+}
+
+// FindAllNext is not cached, the score changes with the time.
+func (tr *taskRepo) FindAllNext(ctx context.Context, query model.NextTasksQuery) ([]*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tasks := []*model.Task{}
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		err := tx.Select(fmt.Sprintf("*, %s AS score", query.Weights.ScoreColumn()), sql.Named("now", query.Now)).
+			Scopes(tenantScope(tenantFromContext(ctx))).
+			Where("completed = ?", false).
+			Order("score DESC, created_at ASC, id ASC").
+			Offset(int(model.Offset(query.Page, query.Size))).
+			Limit(int(query.Size)).
+			Preload("Series").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tr.loadProgress(tx, tasks...)
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	return tasks, nil
+}
+
+func (tr *taskRepo) CountAllNext(ctx context.Context, query model.NextTasksQuery) (int64, error) {
+	count := int64(0)
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.Task{}).
+			Scopes(tenantScope(tenantFromContext(ctx))).
+			Where("completed = ?", false).
+			Count(&count).
+			Error
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":   utils.Dump(ctx),
+			"query": utils.Dump(query),
+		}).Error(err)
+		return int64(0), translateError(err, model.ErrTaskNotFound)
+	}
+
+	return count, nil
+}
+
+// ClaimDueNotifications is run by the reminder worker for the tasks of every
+// tenant, its transaction does not set one. The repository has to connect as
+// the role of the workers, which bypasses the row level security policies.
+func (tr *taskRepo) ClaimDueNotifications(ctx context.Context, kind model.NotificationKind, now time.Time, limit int) ([]*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"kind":  kind,
+		"now":   now,
+		"limit": limit,
+	})
+
+	timeColumn, notifiedColumn := notificationColumns(kind)
+
+	tasks := []*model.Task{}
+	err := tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(fmt.Sprintf("completed = ? AND %s <= ? AND %s IS NULL", timeColumn, notifiedColumn), false, now).
+			Order(fmt.Sprintf("%s ASC", timeColumn)).
+			Limit(limit).
+			Find(&tasks).
+			Error
+		if err != nil || len(tasks) == 0 {
+			return err
+		}
+
+		IDs := make([]int64, 0, len(tasks))
+		for _, task := range tasks {
+			IDs = append(IDs, task.ID)
+		}
+
+		return tx.Model(&model.Task{}).
+			Where("id IN ?", IDs).
+			UpdateColumn(notifiedColumn, now).
+			Error
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	return tasks, nil
+}
+
+// ReleaseNotification is run by the reminder worker for the tasks of every
+// tenant, like ClaimDueNotifications
+func (tr *taskRepo) ReleaseNotification(ctx context.Context, kind model.NotificationKind, ID int64) error {
+	_, notifiedColumn := notificationColumns(kind)
+
+	err := tr.db.WithContext(ctx).
+		Model(&model.Task{}).
+		Where("id = ?", ID).
+		UpdateColumn(notifiedColumn, nil).
+		Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":  utils.Dump(ctx),
+			"kind": kind,
+			"ID":   ID,
+		}).Error(err)
+		return translateError(err, model.ErrTaskNotFound)
+	}
+
+	return nil
+}
+
+// notificationColumns returns the column the notification of kind is due
+// by and the one marking it as notified
+func notificationColumns(kind model.NotificationKind) (timeColumn, notifiedColumn string) {
+	if kind == model.NotificationKindDue {
+		return "due_at", "due_notified_at"
+	}
+	return "remind_at", "reminded_at"
+}
+
+// createNextOccurrence creates, within tx, the occurrence following the task
+// ID which was just completed, when it belongs to a running series which is
+// not exhausted yet. The occurrence belongs to the tenant of the task.
+func (tr *taskRepo) createNextOccurrence(tx *gorm.DB, ID int64) error {
+	task := &model.Task{}
+	err := tx.Scopes(tenantScope(tenantFromContext(tx.Statement.Context))).
+		Preload("Series").
+		Where("id = ?", ID).
+		Take(task).
+		Error
+	if err != nil || task.Series == nil {
+		return err
+	}
+
+	occurrences := int64(0)
+	err = tx.Unscoped().
+		Model(&model.Task{}).
+		Where("series_id = ?", task.Series.ID).
+		Count(&occurrences).
+		Error
+	if err != nil {
+		return err
+	}
+
+	next, ok, err := task.Series.NextOccurrence(task, occurrences)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err := tr.appendPosition(tx, next); err != nil {
+		return err
+	}
+
+	// an occurrence due at the same time already exists when the previous
+	// one was reopened and completed again
+	res := tx.Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(next)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+
+	return tr.recordEvent(tx, next.ID, model.TaskEventCreated, model.DiffTasks(nil, next))
+}
+
+func (tr *taskRepo) FindSeriesByID(ctx context.Context, ID int64) (*model.TaskSeries, error) {
+	series := &model.TaskSeries{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		// series have no tenant, they belong to the tenant of their tasks
+		tenantSeriesIDs := tr.db.Unscoped().
+			Model(&model.Task{}).
+			Select("series_id").
+			Scopes(tenantScope(tenantFromContext(ctx)))
+
+		return tx.Where("id = ? AND id IN (?)", ID, tenantSeriesIDs).Take(series).Error
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return nil, translateError(err, model.ErrTaskNotRecurring)
+	}
+
+	return series, nil
+}
+
+func (tr *taskRepo) SaveSeries(ctx context.Context, taskID int64, series *model.TaskSeries) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"taskID": taskID,
+		"series": utils.Dump(series),
+	})
+
+	tenant := tenantFromContext(ctx)
+	taskIDs := []int64{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		if err := tx.Save(series).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&model.Task{}).
+			Scopes(tenantScope(tenant)).
+			Where("id = ?", taskID).
+			UpdateColumns(map[string]interface{}{
+				"series_id": series.ID,
+				"version":   gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// every occurrence embeds the series, so all of them are invalidated
+		return tx.Model(&model.Task{}).
+			Scopes(tenantScope(tenant)).
+			Where("series_id = ?", series.ID).
+			Pluck("id", &taskIDs).
+			Error
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTaskNotFound)
+	}
+
+	cacheKeys := []string{tr.cacheHash(tenant)}
+	for _, ID := range taskIDs {
+		cacheKeys = append(cacheKeys, tr.findByIDCacheKey(tenant, ID))
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (tr *taskRepo) FindAllByStatus(ctx context.Context, query model.GetBoardQueryParams) ([]*model.Task, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tenant := tenantFromContext(ctx)
+	cacheHash := tr.cacheHash(tenant)
+	cacheKey := tr.findAllByStatusCacheKey(tenant, query)
+	reply, err := tr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if reply != "" {
+		tasks := []*model.Task{}
+		if err := json.Unmarshal([]byte(reply), &tasks); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		return tasks, nil
+	}
+
+	tasks := []*model.Task{}
+
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		ranked := tr.db.Model(&model.Task{}).
+			Select("*, ROW_NUMBER() OVER (PARTITION BY status ORDER BY position ASC, id ASC) AS column_rank").
+			Scopes(tenantScope(tenant), tr.boardScope(query))
+
+		err := tx.Table("(?) AS tasks", ranked).
+			Where("column_rank <= ?", query.Size).
+			Order("status ASC, position ASC, id ASC").
+			Preload("Series").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tr.loadProgress(tx, tasks...)
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	bytes, err := json.Marshal(tasks)
+	if err != nil {
+		logger.Error(err)
+		return tasks, nil
+	}
+
+	if err := tr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
+		logger.Error(err)
+	}
+
+	return tasks, nil
+}
+
+func (tr *taskRepo) CountAllByStatus(ctx context.Context, query model.GetBoardQueryParams) (map[model.TaskStatus]int64, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tenant := tenantFromContext(ctx)
+	cacheHash := tr.cacheHash(tenant)
+	cacheKey := tr.countAllByStatusCacheKey(tenant, query)
+	reply, err := tr.cacheRepo.HashGet(ctx, cacheHash, cacheKey)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if reply != "" {
+		counts := map[model.TaskStatus]int64{}
+		if err := json.Unmarshal([]byte(reply), &counts); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		return counts, nil
+	}
+
+	rows := []struct {
+		Status model.TaskStatus
+		Count  int64
+	}{}
+
+	err = tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		return tx.Model(&model.Task{}).
+			Select("status, COUNT(*) AS count").
+			Scopes(tenantScope(tenant), tr.boardScope(query)).
+			Group("status").
+			Scan(&rows).
+			Error
+	})
+	if err != nil {
+		logger.Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	counts := map[model.TaskStatus]int64{}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	bytes, err := json.Marshal(counts)
+	if err != nil {
+		logger.Error(err)
+		return counts, nil
+	}
+
+	if err := tr.cacheRepo.HashSet(ctx, cacheHash, cacheKey, string(bytes)); err != nil {
+		logger.Error(err)
+	}
+
+	return counts, nil
+}
+
+// MoveByID changes the status and the position of the task in a single
+// transaction. The new position is ranked between the given neighbours, a
+// missing neighbour is replaced by the task next to the given one.
+func (tr *taskRepo) MoveByID(ctx context.Context, move model.TaskMove) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":  utils.Dump(ctx),
+		"move": utils.Dump(move),
+	})
+
+	tenant := tenantFromContext(ctx)
+	parentCacheKeys := []string{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) (err error) {
+		before := &model.Task{}
+		if err := tx.Scopes(tenantScope(tenant)).Where("id = ?", move.ID).Take(before).Error; err != nil {
+			return err
+		}
+		after := *before
+
+		if move.Status != "" {
+			res := tx.Model(&model.Task{}).
+				Where("id = ? AND status = ?", move.ID, move.FromStatus).
+				UpdateColumns(map[string]interface{}{
+					"status":       move.Status,
+					"started_at":   move.StartedAt,
+					"completed_at": move.CompletedAt,
+					"version":      gorm.Expr("version + 1"),
+					"updated_at":   time.Now(),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return model.ErrTaskMovedConcurrently
+			}
+			after.Status = move.Status
+		}
+
+		if move.BeforeID != nil || move.AfterID != nil {
+			position, err := tr.positionBetween(tx, move.ID, move.AfterID, move.BeforeID)
+			if err != nil {
+				return err
+			}
+
+			res := tx.Model(&model.Task{}).
+				Where("id = ?", move.ID).
+				UpdateColumns(map[string]interface{}{
+					"position":   position,
+					"version":    gorm.Expr("version + 1"),
+					"updated_at": time.Now(),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			after.Position = position
+		}
+
+		if changes := model.DiffTasks(before, &after); len(changes) > 0 {
+			if err := tr.recordEvent(tx, move.ID, model.TaskEventMoved, changes); err != nil {
+				return err
+			}
+		}
+
+		if before.Status != model.TaskStatusDone && after.Status == model.TaskStatusDone {
+			if err := tr.createNextOccurrence(tx, move.ID); err != nil {
+				return err
+			}
+		}
+
+		parentCacheKeys, err = tr.parentCacheKeys(tx, move.ID)
+		return err
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTaskNotFound)
+	}
+
+	cacheKeys := []string{
+		tr.cacheHash(tenant),
+		tr.findByIDCacheKey(tenant, move.ID),
+	}
+	cacheKeys = append(cacheKeys, parentCacheKeys...)
+
+	if err := tr.cacheRepo.Delete(ctx, cacheKeys...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// positionBetween ranks the task ID right after afterID and right before
+// beforeID. With only one of them, the other bound is the closest position
+// of the tenant's tasks on the far side of the given neighbour.
+func (tr *taskRepo) positionBetween(tx *gorm.DB, ID int64, afterID, beforeID *int64) (string, error) {
+	tenant := tenantFromContext(tx.Statement.Context)
+	lower, upper := "", ""
+
+	if afterID != nil {
+		position, err := tr.neighbourPosition(tx, ID, *afterID)
+		if err != nil {
+			return "", err
+		}
+		lower = position
+	}
+
+	if beforeID != nil {
+		position, err := tr.neighbourPosition(tx, ID, *beforeID)
+		if err != nil {
+			return "", err
+		}
+		upper = position
+	}
+
+	switch {
+	case beforeID == nil:
+		err := tx.Model(&model.Task{}).
+			Select("COALESCE(MIN(position), '')").
+			Scopes(tenantScope(tenant)).
+			Where("position > ? AND id <> ?", lower, ID).
+			Scan(&upper).
+			Error
+		if err != nil {
+			return "", err
+		}
+	case afterID == nil:
+		err := tx.Model(&model.Task{}).
+			Select("COALESCE(MAX(position), '')").
+			Scopes(tenantScope(tenant)).
+			Where("position < ? AND id <> ?", upper, ID).
+			Scan(&lower).
+			Error
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if upper != "" && lower >= upper {
+		return "", model.ErrInvalidTaskNeighbours
+	}
+
+	return utils.RankBetween(lower, upper), nil
+}
+
+func (tr *taskRepo) neighbourPosition(tx *gorm.DB, ID, neighbourID int64) (string, error) {
+	if neighbourID == ID {
+		return "", model.ErrInvalidTaskNeighbours
+	}
+
+	positions := []string{}
+	err := tx.Model(&model.Task{}).
+		Scopes(tenantScope(tenantFromContext(tx.Statement.Context))).
+		Where("id = ?", neighbourID).
+		Pluck("position", &positions).
+		Error
+	if err != nil {
+		return "", err
+	}
+	if len(positions) == 0 {
+		return "", fmt.Errorf("%w %d", model.ErrTaskNeighbourNotFound, neighbourID)
+	}
+
+	return positions[0], nil
+}
+
+// MaxPositionLength and RebalancePositions are run by the position worker,
+// the positions of all tenants are ranked together. Like the reminder
+// worker's, the repository connects as the role bypassing the policies.
+func (tr *taskRepo) MaxPositionLength(ctx context.Context) (int, error) {
+	length := 0
+	err := tr.db.WithContext(ctx).
+		Unscoped().
+		Model(&model.Task{}).
+		Select("COALESCE(MAX(LENGTH(position)), 0)").
+		Scan(&length).
+		Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+		}).Error(err)
+		return 0, translateError(err, model.ErrTaskNotFound)
+	}
+
+	return length, nil
+}
+
+// RebalancePositions renumbers the positions with fixed width hex ranks. The
+// table is locked against writes meanwhile so that no move or create ranks
+// a task against positions which are about to change.
+func (tr *taskRepo) RebalancePositions(ctx context.Context) (int64, error) {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+	})
+
+	IDs := []int64{}
+	err := tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`LOCK TABLE tasks IN EXCLUSIVE MODE`).Error; err != nil {
+			return err
+		}
+
+		return tx.Raw(`
+			UPDATE tasks SET position = ranked.position, version = version + 1
+			FROM (
+				SELECT id, LPAD(TO_HEX(ROW_NUMBER() OVER (ORDER BY position, id)), 8, '0') || 'V' AS position
+				FROM tasks
+			) AS ranked
+			WHERE tasks.id = ranked.id AND tasks.position <> ranked.position
+			RETURNING tasks.id`).
+			Scan(&IDs).
+			Error
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return 0, translateError(err, model.ErrTaskNotFound)
+	}
+
+	if len(IDs) == 0 {
+		return 0, nil
+	}
+
+	if err := tr.InvalidateCache(ctx, IDs...); err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	return int64(len(IDs)), nil
+}
+
+// appendPosition ranks a new task after every other task of the tenant,
+// which are the ones the policies let through, unless it already has a
+// position
+func (tr *taskRepo) appendPosition(tx *gorm.DB, task *model.Task) error {
+	if task.Position != "" {
+		return nil
+	}
+
+	last := ""
+	err := tx.Unscoped().
+		Model(&model.Task{}).
+		Select("COALESCE(MAX(position), '')").
+		Scan(&last).
+		Error
+	if err != nil {
+		return err
+	}
+
+	task.Position = utils.RankBetween(last, "")
+	return nil
+}
+
+// FindAllByIDs is not cached, it backs batches which read their tasks right
+// before and after writing them
+func (tr *taskRepo) FindAllByIDs(ctx context.Context, IDs []int64) ([]*model.Task, error) {
+	tasks := []*model.Task{}
+	if len(IDs) == 0 {
+		return tasks, nil
+	}
+
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		err := tx.Scopes(tenantScope(tenantFromContext(ctx))).
+			Where("id IN ?", IDs).
+			Preload("Series").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tr.loadProgress(tx, tasks...)
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"IDs": IDs,
+		}).Error(err)
+		return nil, translateError(err, model.ErrTaskNotFound)
+	}
+
+	return tasks, nil
+}
+
+func (tr *taskRepo) ApplyBatch(ctx context.Context, operations []*model.TaskOperation, atomic bool) error {
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":        utils.Dump(ctx),
+		"operations": utils.Dump(operations),
+		"atomic":     atomic,
+	})
+
+	cacheKeys := []string{}
+	err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+		for _, operation := range operations {
+			if operation.Err != nil {
+				continue
+			}
+
+			if !atomic {
+				if err := tx.SavePoint("task_operation").Error; err != nil {
+					return err
+				}
+			}
+
+			keys, err := tr.apply(tx, operation)
+			if err != nil {
+				operation.Err = err
+				if atomic {
+					return err
+				}
+
+				if err := tx.RollbackTo("task_operation").Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			cacheKeys = append(cacheKeys, keys...)
+		}
+		return nil
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return translateError(err, model.ErrTaskNotFound)
+	}
+
+	if len(cacheKeys) == 0 {
+		return nil
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, uniqueStrings(cacheKeys)...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// apply runs a single operation of a batch within tx
+func (tr *taskRepo) apply(tx *gorm.DB, operation *model.TaskOperation) ([]string, error) {
+	switch operation.Kind {
+	case model.TaskOperationCreate:
+		return tr.create(tx, operation.Task)
+	case model.TaskOperationUpdate:
+		return tr.update(tx, operation.Task)
+	case model.TaskOperationDelete:
+		return tr.delete(tx, operation.ID, operation.Version)
+	case model.TaskOperationComplete:
+		return tr.complete(tx, operation.ID, operation.Cascade)
+	default:
+		return nil, model.ErrInvalidOperation
+	}
+}
+
+// completedTask is a task completed by complete with the status it had
+// before
+type completedTask struct {
+	ID     int64
+	Status model.TaskStatus
+}
+
+// tenantTaskIDs selects the IDs of the caller's tasks, trashed ones included
+func (tr *taskRepo) tenantTaskIDs(ctx context.Context) *gorm.DB {
+	return tr.db.Unscoped().
+		Model(&model.Task{}).
+		Select("id").
+		Scopes(tenantScope(tenantFromContext(ctx)))
+}
+
+// recordEvent appends an event to the history of the task within tx, the
+// actor is taken from the context of tx
+func (tr *taskRepo) recordEvent(tx *gorm.DB, taskID int64, kind model.TaskEventKind, changes model.TaskChanges) error {
+	event := model.NewTaskEvent(tx.Statement.Context, taskID, kind, changes)
+	return tx.Create(event).Error
+}
+
+// versionConflict tells a missing task from one which is no longer at the
+// expected version, after a guarded write touched no row
+func (tr *taskRepo) versionConflict(tx *gorm.DB, ID int64) error {
+	count := int64(0)
+	err := tx.Model(&model.Task{}).
+		Scopes(tenantScope(tenantFromContext(tx.Statement.Context))).
+		Where("id = ?", ID).
+		Count(&count).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return model.ErrTaskVersionMismatch
+}
+
+// InvalidateCache looks up the tenants of the tasks, since rebalanced
+// positions change the tasks of every tenant at once. The policies let the
+// position worker see all of them, other callers only see their own.
+func (tr *taskRepo) InvalidateCache(ctx context.Context, IDs ...int64) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"IDs": IDs,
+	})
+
+	tasks := []*model.Task{}
+	if len(IDs) > 0 {
+		err := tenantTx(ctx, tr.db, func(tx *gorm.DB) error {
+			return tx.Unscoped().
+				Select("id", "workspace_id", "owner_id").
+				Where("id IN ?", IDs).
+				Find(&tasks).
+				Error
+		})
+		if err != nil {
+			logger.Error(err)
+			return translateError(err, model.ErrTaskNotFound)
+		}
+	}
+
+	cacheKeys := []string{tr.cacheHash(tenantFromContext(ctx))}
+	for _, task := range tasks {
+		cacheKeys = append(cacheKeys, tr.cacheHash(tenantOfTask(task)), tr.findByIDCacheKey(tenantOfTask(task), task.ID))
+	}
+
+	if err := tr.cacheRepo.Delete(ctx, uniqueStrings(cacheKeys)...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// checkProject makes sure that the project ID belongs to the tenant, the
+// foreign key accepts the projects of every tenant
+func (tr *taskRepo) checkProject(tx *gorm.DB, tenant taskTenant, projectID *int64) error {
+	if projectID == nil {
+		return nil
+	}
+
+	count := int64(0)
+	err := tx.Model(&model.Project{}).
+		Scopes(tenantTableScope("projects", tenant)).
+		Where("id = ?", *projectID).
+		Count(&count).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return model.ErrTaskProjectInvalid
+	}
+	return nil
+}
+
+// replaceTags replaces the tags of the task by task.Tags, creating the tags
+// of the tenant which do not exist yet. Nothing is changed when task.Tags is
+// nil.
+func (tr *taskRepo) replaceTags(tx *gorm.DB, task *model.Task) error {
+	if task.Tags == nil {
+		return nil
+	}
+
+	tenant := tenantFromContext(tx.Statement.Context)
+	workspaceID, ownerID := tenant.owners()
+
+	names := []string{}
+	for _, tag := range task.Tags {
+		names = append(names, tag.Name)
+	}
+
+	tags := []*model.Tag{}
+	if len(names) > 0 {
+		newTags := []*model.Tag{}
+		for _, name := range names {
+			newTags = append(newTags, &model.Tag{
+				ID:          utils.GenerateID(),
+				Name:        name,
+				WorkspaceID: workspaceID,
+				OwnerID:     ownerID,
+				CreatedAt:   time.Now(),
+			})
+		}
+
+		// the names are unique per tenant through partial indexes, which
+		// an ON CONFLICT without target covers
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Scopes(tenantTableScope("tags", tenant)).
+			Where("name IN ?", names).
+			Order("name ASC").
+			Find(&tags).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("task_id = ?", task.ID).Delete(&model.TaskTag{}).Error; err != nil {
+		return err
+	}
+
+	if len(tags) > 0 {
+		taskTags := []*model.TaskTag{}
+		for _, tag := range tags {
+			taskTags = append(taskTags, &model.TaskTag{TaskID: task.ID, TagID: tag.ID})
+		}
+
+		if err := tx.Create(&taskTags).Error; err != nil {
+			return err
+		}
+	}
+
+	task.Tags = tags
+	return nil
+}
+
+// loadProgress sets the progress of the tasks from their direct subtasks,
+// tasks without subtasks keep a nil progress
+func (tr *taskRepo) loadProgress(tx *gorm.DB, tasks ...*model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	IDs := []int64{}
+	for _, task := range tasks {
+		IDs = append(IDs, task.ID)
+	}
+
+	rows := []struct {
+		ParentID  int64
+		Total     int64
+		Completed int64
+	}{}
+	err := tx.Model(&model.Task{}).
+		Select("parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE completed) AS completed").
+		Where("parent_id IN ?", IDs).
+		Group("parent_id").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return err
+	}
+
+	progress := map[int64]*model.TaskProgress{}
+	for _, row := range rows {
+		progress[row.ParentID] = model.NewTaskProgress(row.Total, row.Completed)
+	}
+
+	for _, task := range tasks {
+		task.Progress = progress[task.ID]
+	}
+
+	return nil
+}
+
+// parentCacheKeys returns the cache keys of the parents of IDs, whose
+// progress depends on their subtasks
+func (tr *taskRepo) parentCacheKeys(tx *gorm.DB, IDs ...int64) ([]string, error) {
+	parentIDs := []int64{}
+	err := tx.Unscoped().
+		Model(&model.Task{}).
+		Where("id IN ? AND parent_id IS NOT NULL", IDs).
+		Pluck("parent_id", &parentIDs).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := tenantFromContext(tx.Statement.Context)
+	cacheKeys := []string{}
+	for _, parentID := range parentIDs {
+		cacheKeys = append(cacheKeys, tr.findByIDCacheKey(tenant, parentID))
+	}
+
+	return cacheKeys, nil
+}
+
+// cacheHash holds the cached listings of the tenant, the other cache keys
+// of the tenant start with it as well
+func (tr *taskRepo) cacheHash(tenant taskTenant) string {
+	return tenant.cacheKey() + ":task"
+}
+
+func (tr *taskRepo) findByIDCacheKey(tenant taskTenant, ID int64) string {
+	return fmt.Sprintf("%s:%d", tr.cacheHash(tenant), ID)
+}
+
+func (tr *taskRepo) findAllByQueryParams(tenant taskTenant, query model.GetTasksQueryParams) string {
+	return fmt.Sprintf(
+		"%s:page:%d:size:%d:sort:%s:order:%s:%s",
+		tr.cacheHash(tenant),
+		query.Page,
+		query.Size,
+		query.SortField(),
+		query.SortOrder(),
+		tr.filterCacheKey(query),
+	)
+}
+
+func (tr *taskRepo) findAllByCursorCacheKey(tenant taskTenant, query model.GetTasksQueryParams) string {
+	return fmt.Sprintf(
+		"%s:cursor:%s:size:%d:sort:%s:order:%s:%s",
+		tr.cacheHash(tenant),
+		query.Cursor,
+		query.Size,
+		query.SortField(),
+		query.SortOrder(),
+		tr.filterCacheKey(query),
+	)
+}
+
+func (tr *taskRepo) findAllByStatusCacheKey(tenant taskTenant, query model.GetBoardQueryParams) string {
+	return fmt.Sprintf("%s:board:size:%d:%s", tr.cacheHash(tenant), query.Size, tr.boardCacheKey(query))
+}
+
+func (tr *taskRepo) countAllByStatusCacheKey(tenant taskTenant, query model.GetBoardQueryParams) string {
+	return fmt.Sprintf("%s:board:count:%s", tr.cacheHash(tenant), tr.boardCacheKey(query))
+}
+
+// boardCacheKey must cover every field used by boardScope
+func (tr *taskRepo) boardCacheKey(query model.GetBoardQueryParams) string {
+	key := "project_id:"
+	if query.ProjectID != nil {
+		key += strconv.FormatInt(*query.ProjectID, 10)
+	}
+	return key
+}
+
+func (tr *taskRepo) boardScope(query model.GetBoardQueryParams) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.ProjectID != nil {
+			db = db.Where("project_id = ?", *query.ProjectID)
+		}
+		return db
+	}
+}
+
+func (tr *taskRepo) countAllCacheKey(tenant taskTenant, query model.GetTasksQueryParams) string {
+	return fmt.Sprintf("%s:count:%s", tr.cacheHash(tenant), tr.filterCacheKey(query))
+}
+
+// filterCacheKey must cover every field used by filterScope, otherwise
+// differently filtered listings would share a cache entry.
+func (tr *taskRepo) filterCacheKey(query model.GetTasksQueryParams) string {
+	key := "completed:"
+	if query.Completed != nil {
+		key += strconv.FormatBool(*query.Completed)
+	}
+
+	key += ":status:" + query.Status
+
+	key += ":created_after:" + formatCacheTime(query.CreatedAfter)
+	key += ":created_before:" + formatCacheTime(query.CreatedBefore)
+	key += ":updated_after:" + formatCacheTime(query.UpdatedAfter)
+	key += ":parent_id:"
+	if query.ParentID != nil {
+		key += strconv.FormatInt(*query.ParentID, 10)
+	}
+
+	key += ":project_id:"
+	if query.ProjectID != nil {
+		key += strconv.FormatInt(*query.ProjectID, 10)
+	}
+
+	key += ":tags:" + strings.Join(query.TagNames(), ",")
+	if query.MatchAllTags() {
+		key += ":tags_mode:" + model.TagsModeAll
+	}
+
+	key += ":q:" + query.Query
+
+	return key
+}
+
+// filterScope scopes the tag lookup to the tags of tenant itself rather than
+// leaving it to the policies, tags of another tenant may share the name
+func (tr *taskRepo) filterScope(tenant taskTenant, query model.GetTasksQueryParams) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.Completed != nil {
+			db = db.Where("completed = ?", *query.Completed)
+		}
+		if query.Status != "" {
+			db = db.Where("status = ?", query.Status)
+		}
+		if query.CreatedAfter != nil {
+			db = db.Where("created_at > ?", *query.CreatedAfter)
+		}
+		if query.CreatedBefore != nil {
+			db = db.Where("created_at < ?", *query.CreatedBefore)
+		}
+		if query.UpdatedAfter != nil {
+			db = db.Where("updated_at > ?", *query.UpdatedAfter)
+		}
+		if query.Query != "" {
+			db = db.Where("search_vector @@ websearch_to_tsquery('simple', ?)", query.Query)
+		}
+		if query.ParentID != nil {
+			db = db.Where("parent_id = ?", *query.ParentID)
+		}
+		if query.ProjectID != nil {
+			db = db.Where("project_id = ?", *query.ProjectID)
+		}
+		if names := query.TagNames(); len(names) > 0 {
+			taggedTaskIDs := db.Session(&gorm.Session{NewDB: true}).
+				Table("task_tags").
+				Select("task_tags.task_id").
+				Joins("JOIN tags ON tags.id = task_tags.tag_id").
+				Scopes(tenantTableScope("tags", tenant)).
+				Where("tags.name IN ?", names)
+			if query.MatchAllTags() {
+				taggedTaskIDs = taggedTaskIDs.
+					Group("task_tags.task_id").
+					Having("COUNT(DISTINCT tags.id) = ?", len(names))
+			}
+			db = db.Where("id IN (?)", taggedTaskIDs)
+		}
+		return db
+	}
+}
+
+// cursorScope orders by the sort column and id and seeks past the cursor
+// using a row comparison, backward cursors flip both the order and the seek
+func (tr *taskRepo) cursorScope(query model.GetTasksQueryParams, cursor *model.Cursor) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		column := query.SortColumn()
+		desc := query.SortOrder() == model.SortOrderDesc
+		if cursor != nil && cursor.Backward {
+			desc = !desc
+		}
+
+		op, order := ">", "ASC"
+		if desc {
+			op, order = "<", "DESC"
+		}
+
+		if column == "id" {
+			if cursor != nil {
+				db = db.Where(fmt.Sprintf("id %s ?", op), cursor.ID)
+			}
+			return db.Order(fmt.Sprintf("id %s", order))
+		}
+
+		if cursor != nil {
+			db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, op), cursor.Value, cursor.ID)
+		}
+		return db.Order(fmt.Sprintf("%s %s, id %s", column, order, order))
+	}
+}
+
+func (tr *taskRepo) dueScope(query model.DueTasksQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("completed = ? AND due_at < ?", false, query.To)
+		if query.From != nil {
+			db = db.Where("due_at >= ?", *query.From)
+		}
+		return db
+	}
+}
+
+func formatCacheTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"todo-app/internal/model"
+)
+
+// createTaskAt creates the task as if it had been created at createdAt
+func createTaskAt(t *testing.T, repo model.TaskRepository, ctx context.Context, input model.CreateTaskInput, createdAt time.Time) *model.Task {
+	t.Helper()
+
+	if input.Status == "" {
+		input.Status = model.TaskStatusTodo
+	}
+	task := input.ToModel()
+	task.CreatedAt = createdAt
+	task.SetStatus(task.Status, createdAt)
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create(%q) error = %v", input.Title, err)
+	}
+	return task
+}
+
+func TestTaskRepositoryFindAllNext(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	ctx := f.workspace(f.userA, f.workspaceA)
+	repo := NewTaskRepository(db.app, nopCacheRepo{})
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tasks := []*model.Task{
+		createTaskAt(t, repo, ctx, model.CreateTaskInput{Title: "plain"}, now.Add(-10*24*time.Hour)),
+		createTaskAt(t, repo, ctx, model.CreateTaskInput{Title: "due later", Priority: model.TaskPriorityLow, DueAt: at(10 * 24 * time.Hour)}, now.Add(-time.Hour)),
+		createTaskAt(t, repo, ctx, model.CreateTaskInput{Title: "due soon", DueAt: at(12 * time.Hour)}, now.Add(-2*time.Hour)),
+		createTaskAt(t, repo, ctx, model.CreateTaskInput{Title: "urgent", Priority: model.TaskPriorityUrgent}, now),
+		createTaskAt(t, repo, ctx, model.CreateTaskInput{Title: "overdue", DueAt: at(-24 * time.Hour)}, now.Add(-24*time.Hour)),
+	}
+	createTaskAt(t, repo, ctx, model.CreateTaskInput{Title: "done", Status: model.TaskStatusDone, Priority: model.TaskPriorityUrgent, DueAt: at(-time.Hour)}, now)
+
+	tests := []struct {
+		name    string
+		weights model.TaskScoreWeights
+		want    []string
+	}{
+		{name: "default weights", weights: model.TaskScoreWeights{Priority: 1, Due: 4, Age: 0.1}, want: []string{"overdue", "urgent", "due soon", "due later", "plain"}},
+		{name: "priority first", weights: model.TaskScoreWeights{Priority: 4, Due: 1, Age: 0.1}, want: []string{"urgent", "due later", "overdue", "plain", "due soon"}},
+		{name: "age only", weights: model.TaskScoreWeights{Age: 1}, want: []string{"plain", "overdue", "due soon", "due later", "urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.FindAllNext(ctx, model.NextTasksQuery{Now: now, Weights: tt.weights, Page: 1, Size: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			titles := []string{}
+			for _, task := range got {
+				titles = append(titles, task.Title)
+				want := tt.weights.Score(task, now)
+				if task.Score == nil || math.Abs(*task.Score-want) > 1e-6 {
+					t.Errorf("score of %q = %v, want %f like TaskScoreWeights.Score", task.Title, task.Score, want)
+				}
+			}
+			if !slices.Equal(titles, tt.want) {
+				t.Errorf("FindAllNext() = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+
+	count, err := repo.CountAllNext(ctx, model.NextTasksQuery{Now: now})
+	if err != nil || count != int64(len(tasks)) {
+		t.Errorf("CountAllNext() = %d, %v, want the %d open tasks", count, err, len(tasks))
+	}
+}
+
+func TestTaskRepositoryFilterByTags(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	ctx := f.workspace(f.userA, f.workspaceA)
+	repo := NewTaskRepository(db.app, nopCacheRepo{})
+
+	both := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "both", Tags: []string{"work", "urgent"}})
+	work := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "work", Tags: []string{"work"}})
+	urgent := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "urgent", Tags: []string{"urgent"}})
+	createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "untagged"})
+	// a task of another workspace with a tag of the same name
+	createTestTask(t, repo, f.workspace(f.userB, f.workspaceB), model.CreateTaskInput{Title: "work elsewhere", Tags: []string{"work"}})
+
+	tests := []struct {
+		name  string
+		query model.GetTasksQueryParams
+		want  []int64
+	}{
+		{name: "any of one", query: model.GetTasksQueryParams{Tags: "work"}, want: []int64{both.ID, work.ID}},
+		{name: "any of two", query: model.GetTasksQueryParams{Tags: "work,urgent"}, want: []int64{both.ID, work.ID, urgent.ID}},
+		{name: "all of two", query: model.GetTasksQueryParams{Tags: "Work, urgent", TagsMode: "all"}, want: []int64{both.ID}},
+		{name: "all of an unknown tag", query: model.GetTasksQueryParams{Tags: "work,someday", TagsMode: "all"}, want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Pagination = model.Pagination{Page: 1, Size: 10}
+			query.Sort, query.Order = "created_at", model.SortOrderAsc
+
+			tasks, err := repo.FindAll(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := taskIDs(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("FindAll() = %v, want %v", got, tt.want)
+			}
+
+			count, err := repo.CountAll(ctx, query)
+			if err != nil || count != int64(len(tt.want)) {
+				t.Errorf("CountAll() = %d, %v, want %d", count, err, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestTaskRepositoryTrash(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	ctx := f.workspace(f.userA, f.workspaceA)
+	repo := NewTaskRepository(db.app, nopCacheRepo{})
+	page := model.GetTrashedTasksQueryParams{Pagination: model.Pagination{Page: 1, Size: 10}}
+
+	kept := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "kept"})
+	first := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "trashed first"})
+	second := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "trashed second"})
+	for _, task := range []*model.Task{first, second} {
+		if err := repo.DeleteByID(ctx, task.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("list", func(t *testing.T) {
+		tasks, err := repo.FindAllTrashed(ctx, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{second.ID, first.ID}) {
+			t.Errorf("FindAllTrashed() = %v, want the most recently deleted first %v", got, []int64{second.ID, first.ID})
+		}
+
+		tasks, err = repo.FindAllTrashed(ctx, model.GetTrashedTasksQueryParams{Pagination: model.Pagination{Page: 2, Size: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{first.ID}) {
+			t.Errorf("FindAllTrashed() of page 2 = %v, want %v", got, []int64{first.ID})
+		}
+
+		count, err := repo.CountAllTrashed(ctx)
+		if err != nil || count != 2 {
+			t.Errorf("CountAllTrashed() = %d, %v, want 2", count, err)
+		}
+
+		if _, err := repo.FindByID(ctx, first.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("FindByID() of a trashed task error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		if err := repo.RestoreByID(ctx, first.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		task, err := repo.FindByID(ctx, first.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.DeletedAt.Valid || task.Version <= first.Version {
+			t.Errorf("restored task = %+v, want it live at a new version", task)
+		}
+
+		events, err := repo.FindEventsByTaskID(ctx, first.ID, model.GetTaskHistoryQueryParams{Pagination: model.Pagination{Page: 1, Size: 10}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) == 0 || events[0].Kind != model.TaskEventRestored {
+			t.Errorf("latest event = %+v, want %s", events, model.TaskEventRestored)
+		}
+
+		if err := repo.RestoreByID(ctx, first.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("RestoreByID() of a live task error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		if err := repo.PurgeByID(ctx, kept.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("PurgeByID() of a task outside of the trash error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+		if _, err := repo.FindByID(ctx, kept.ID); err != nil {
+			t.Errorf("FindByID() after refusing to purge it error = %v", err)
+		}
+
+		if err := repo.PurgeByID(ctx, second.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RestoreByID(ctx, second.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("RestoreByID() of a purged task error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+		if err := repo.PurgeByID(ctx, second.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("PurgeByID() twice error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+
+		count, err := repo.CountAllTrashed(ctx)
+		if err != nil || count != 0 {
+			t.Errorf("CountAllTrashed() = %d, %v, want 0", count, err)
+		}
+	})
+}
+
+func TestTaskRepositoryFindAllDue(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+	ctx := f.workspace(f.userA, f.workspaceA)
+	repo := NewTaskRepository(db.app, nopCacheRepo{})
+
+	from := time.Now().UTC().Truncate(time.Microsecond)
+	to := from.Add(24 * time.Hour)
+	at := func(t time.Time) *time.Time { return &t }
+
+	overdue := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "overdue", DueAt: at(from.Add(-time.Hour))})
+	atFrom := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "due at the start", DueAt: at(from)})
+	within := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "due within", DueAt: at(from.Add(time.Hour))})
+	beforeTo := createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "due just before the end", DueAt: at(to.Add(-time.Microsecond))})
+	createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "due at the end", DueAt: at(to)})
+	createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "due later", DueAt: at(to.Add(time.Hour))})
+	createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "without due date"})
+	createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "completed overdue", Status: model.TaskStatusDone, DueAt: at(from.Add(-time.Hour))})
+	createTestTask(t, repo, ctx, model.CreateTaskInput{Title: "completed within", Status: model.TaskStatusDone, DueAt: at(from.Add(time.Hour))})
+
+	tests := []struct {
+		name  string
+		query model.DueTasksQuery
+		want  []int64
+	}{
+		{name: "overdue", query: model.DueTasksQuery{To: from}, want: []int64{overdue.ID}},
+		{name: "due within", query: model.DueTasksQuery{From: &from, To: to}, want: []int64{atFrom.ID, within.ID, beforeTo.ID}},
+		{name: "empty window", query: model.DueTasksQuery{From: &from, To: from}, want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Page, query.Size = 1, 10
+
+			tasks, err := repo.FindAllDue(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := taskIDs(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("FindAllDue() = %v, want %v", got, tt.want)
+			}
+
+			count, err := repo.CountAllDue(ctx, query)
+			if err != nil || count != int64(len(tt.want)) {
+				t.Errorf("CountAllDue() = %d, %v, want %d", count, err, len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"todo-app/internal/model"
+)
+
+// newDryRunDB builds the SQL of the Postgres dialect without connecting
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestTaskRepoFilterScope(t *testing.T) {
+	workspaceID, ownerID, projectID := int64(7), int64(42), int64(3)
+	completed := false
+
+	tests := []struct {
+		name        string
+		tenant      taskTenant
+		query       model.GetTasksQueryParams
+		want        []string
+		wantMissing []string
+	}{
+		{
+			name:        "no filter",
+			tenant:      taskTenant{WorkspaceID: &workspaceID},
+			want:        []string{`SELECT * FROM "tasks" WHERE "tasks"."deleted_at" IS NULL`},
+			wantMissing: []string{"task_tags"},
+		},
+		{
+			name:   "columns",
+			tenant: taskTenant{WorkspaceID: &workspaceID},
+			query:  model.GetTasksQueryParams{Completed: &completed, Status: "todo", ProjectID: &projectID, Query: "report"},
+			want: []string{
+				"completed = false",
+				"status = 'todo'",
+				"project_id = 3",
+				"search_vector @@ websearch_to_tsquery('simple', 'report')",
+			},
+		},
+		{
+			name:        "any of the tags of a workspace",
+			tenant:      taskTenant{WorkspaceID: &workspaceID},
+			query:       model.GetTasksQueryParams{Tags: "Work,urgent"},
+			want:        []string{"id IN (SELECT task_tags.task_id FROM \"task_tags\" JOIN tags ON tags.id = task_tags.tag_id WHERE tags.name IN ('urgent','work') AND tags.workspace_id = 7)"},
+			wantMissing: []string{"HAVING"},
+		},
+		{
+			name:   "all of the tags of a workspace",
+			tenant: taskTenant{WorkspaceID: &workspaceID},
+			query:  model.GetTasksQueryParams{Tags: "work,urgent", TagsMode: "all"},
+			want:   []string{"WHERE tags.name IN ('urgent','work') AND tags.workspace_id = 7 GROUP BY \"task_tags\".\"task_id\" HAVING COUNT(DISTINCT tags.id) = 2"},
+		},
+		{
+			name:   "tags of a user",
+			tenant: taskTenant{OwnerID: &ownerID},
+			query:  model.GetTasksQueryParams{Tags: "work"},
+			want:   []string{"WHERE tags.name IN ('work') AND tags.workspace_id IS NULL AND tags.owner_id = 42)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDryRunDB(t)
+			stmt := db.Scopes((&taskRepo{}).filterScope(tt.tenant, tt.query)).Find(&[]*model.Task{}).Statement
+			sql := db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+
+			for _, want := range tt.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("SQL = %s\nwant it to contain %s", sql, want)
+				}
+			}
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(sql, missing) {
+					t.Errorf("SQL = %s\nwant it without %s", sql, missing)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskRepoFilterCacheKey(t *testing.T) {
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	yes, no := true, false
+	one, two := int64(1), int64(2)
+
+	// every query filters differently, so no two may share a key
+	distinct := map[string]model.GetTasksQueryParams{
+		"none":              {},
+		"completed":         {Completed: &yes},
+		"open":              {Completed: &no},
+		"status":            {Status: "todo"},
+		"other status":      {Status: "done"},
+		"created after":     {CreatedAfter: &at},
+		"created before":    {CreatedBefore: &at},
+		"updated after":     {UpdatedAfter: &at},
+		"parent":            {ParentID: &one},
+		"other parent":      {ParentID: &two},
+		"project":           {ProjectID: &one},
+		"other project":     {ProjectID: &two},
+		"tag":               {Tags: "work"},
+		"tags":              {Tags: "work,urgent"},
+		"all tags":          {Tags: "work,urgent", TagsMode: "all"},
+		"query":             {Query: "report"},
+		"status and query":  {Status: "todo", Query: "report"},
+		"parent and status": {ParentID: &one, Status: "todo"},
+	}
+
+	tr := &taskRepo{}
+	seen := map[string]string{}
+	for name, query := range distinct {
+		key := tr.filterCacheKey(query)
+		if other, ok := seen[key]; ok {
+			t.Errorf("%q and %q share the cache key %s", name, other, key)
+		}
+		seen[key] = name
+	}
+
+	// equivalent queries filter alike and may share a key
+	equivalent := []struct {
+		name string
+		a, b model.GetTasksQueryParams
+	}{
+		{name: "tag order and case", a: model.GetTasksQueryParams{Tags: "Work,urgent"}, b: model.GetTasksQueryParams{Tags: "urgent, work"}},
+		{name: "any by default", a: model.GetTasksQueryParams{Tags: "work"}, b: model.GetTasksQueryParams{Tags: "work", TagsMode: "any"}},
+		{name: "pagination and sort", a: model.GetTasksQueryParams{Status: "todo"}, b: model.GetTasksQueryParams{Status: "todo", Sort: "title", Pagination: model.Pagination{Page: 2, Size: 5}}},
+	}
+	for _, tt := range equivalent {
+		if a, b := tr.filterCacheKey(tt.a), tr.filterCacheKey(tt.b); a != b {
+			t.Errorf("%s: cache keys %s and %s differ", tt.name, a, b)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"todo-app/internal/model"
+)
+
+// taskTenant is whom the tasks of a context belong to, along with the tags
+// and projects of the tasks. Tasks of a workspace are shared by its members,
+// tasks outside of one are the personal tasks of their owner. A nil OwnerID
+// stands for unauthenticated contexts, which only access the personal tasks
+// without an owner.
+type taskTenant struct {
+	WorkspaceID *int64
+	OwnerID     *int64
+}
+
+func tenantFromContext(ctx context.Context) taskTenant {
+	tenant := taskTenant{}
+	if workspaceID, ok := model.WorkspaceIDFromContext(ctx); ok {
+		tenant.WorkspaceID = &workspaceID
+	}
+	if userID, ok := model.UserIDFromContext(ctx); ok {
+		tenant.OwnerID = &userID
+	}
+	return tenant
+}
+
+func tenantOfTask(task *model.Task) taskTenant {
+	return taskTenant{
+		WorkspaceID: task.WorkspaceID,
+		OwnerID:     task.OwnerID,
+	}
+}
+
+// tenantScope restricts the tasks to the ones of tenant
+func tenantScope(tenant taskTenant) func(db *gorm.DB) *gorm.DB {
+	return tenantTableScope("tasks", tenant)
+}
+
+// tenantTableScope restricts the rows of table, which has the workspace_id
+// and owner_id columns of the tasks, to the ones of tenant
+func tenantTableScope(table string, tenant taskTenant) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tenant.WorkspaceID != nil {
+			return db.Where(table+".workspace_id = ?", *tenant.WorkspaceID)
+		}
+
+		db = db.Where(table + ".workspace_id IS NULL")
+		if tenant.OwnerID == nil {
+			return db.Where(table + ".owner_id IS NULL")
+		}
+		return db.Where(table+".owner_id = ?", *tenant.OwnerID)
+	}
+}
+
+// owners returns the workspace and the owner of the tags and projects
+// created by tenant. The ones of a workspace belong to it rather than to the
+// member who created them.
+func (tenant taskTenant) owners() (workspaceID, ownerID *int64) {
+	if tenant.WorkspaceID != nil {
+		return tenant.WorkspaceID, nil
+	}
+	return nil, tenant.OwnerID
+}
+
+// setLocal enforces the row level security policies of the tasks, tags and
+// projects for the rest of tx, on top of the scopes. The policies let no row
+// through in transactions which do not call it.
+func (tenant taskTenant) setLocal(tx *gorm.DB) error {
+	return tx.Exec(
+		"SELECT set_config('app.workspace_id', ?, TRUE), set_config('app.owner_id', ?, TRUE)",
+		formatTenantID(tenant.WorkspaceID),
+		formatTenantID(tenant.OwnerID),
+	).Error
+}
+
+// formatTenantID formats ID for the settings read by the policies, which
+// tell a missing ID apart from an unset setting by 'none'
+func formatTenantID(ID *int64) string {
+	if ID == nil {
+		return "none"
+	}
+	return strconv.FormatInt(*ID, 10)
+}
+
+// tenantTx runs fn in a transaction of db which is restricted to the tenant
+// of ctx. Reads go through it as well as writes, since the settings read by
+// the policies only last for a transaction.
+func tenantTx(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tenantFromContext(ctx).setLocal(tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// cacheKey prefixes the cache entries of the tenant, so that a cached
+// listing never reaches another tenant
+func (tenant taskTenant) cacheKey() string {
+	switch {
+	case tenant.WorkspaceID != nil:
+		return fmt.Sprintf("workspace:%d", *tenant.WorkspaceID)
+	case tenant.OwnerID != nil:
+		return fmt.Sprintf("owner:%d", *tenant.OwnerID)
+	default:
+		return "owner:none"
+	}
+}
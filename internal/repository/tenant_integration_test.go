@@ -0,0 +1,500 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	migrate "github.com/golang-migrate/migrate/v4"
+	migratePostgres "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+// The integration tests run against the database of TEST_DATABASE_DSN,
+// which they migrate up as its owner, and are skipped without it. The API
+// and worker roles connect through TEST_APP_DATABASE_DSN and
+// TEST_WORKER_DATABASE_DSN, which default to the owner's DSN with the user
+// swapped for todo_app and todo_worker.
+//
+//	TEST_DATABASE_DSN=postgres://postgres@localhost:5432/to-do-test?sslmode=disable go test ./internal/repository/
+
+type integrationDB struct {
+	owner  *gorm.DB
+	app    *gorm.DB
+	worker *gorm.DB
+}
+
+func openIntegrationDB(t *testing.T) *integrationDB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	owner := openTestConn(t, dsn)
+	sqlDB, err := owner.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driver, err := migratePostgres.WithInstance(sqlDB, &migratePostgres.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := migrate.NewWithDatabaseInstance("file://../../db/migration", "postgres", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrations.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatal(err)
+	}
+
+	return &integrationDB{
+		owner:  owner,
+		app:    openTestConn(t, roleDSN(t, os.Getenv("TEST_APP_DATABASE_DSN"), dsn, "todo_app")),
+		worker: openTestConn(t, roleDSN(t, os.Getenv("TEST_WORKER_DATABASE_DSN"), dsn, "todo_worker")),
+	}
+}
+
+func openTestConn(t *testing.T, dsn string) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// roleDSN returns dsn when set, the owner's DSN connecting as role otherwise
+func roleDSN(t *testing.T, dsn, ownerDSN, role string) string {
+	t.Helper()
+
+	if dsn != "" {
+		return dsn
+	}
+
+	u, err := url.Parse(ownerDSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.User = url.User(role)
+	return u.String()
+}
+
+// nopCacheRepo caches nothing, so that every read reaches the database
+type nopCacheRepo struct {
+	model.CacheRepository
+}
+
+func (nopCacheRepo) Get(ctx context.Context, key string) (string, error) { return "", nil }
+
+func (nopCacheRepo) Set(ctx context.Context, key, val string) error { return nil }
+
+func (nopCacheRepo) Delete(ctx context.Context, keys ...string) error { return nil }
+
+func (nopCacheRepo) HashGet(ctx context.Context, hash, key string) (string, error) { return "", nil }
+
+func (nopCacheRepo) HashSet(ctx context.Context, hash, key, val string) error { return nil }
+
+// tenantFixture holds two users, each a member of a workspace of their own
+type tenantFixture struct {
+	userA, userB           int64
+	workspaceA, workspaceB int64
+}
+
+func newTenantFixture(t *testing.T, db *integrationDB) *tenantFixture {
+	t.Helper()
+
+	f := &tenantFixture{
+		userA:      utils.GenerateID(),
+		userB:      utils.GenerateID(),
+		workspaceA: utils.GenerateID(),
+		workspaceB: utils.GenerateID(),
+	}
+
+	for _, user := range []int64{f.userA, f.userB} {
+		err := db.owner.Create(&model.User{
+			ID:           user,
+			Email:        fmt.Sprintf("user-%d@example.com", user),
+			PasswordHash: "-",
+		}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, workspace := range []int64{f.workspaceA, f.workspaceB} {
+		err := db.owner.Create(&model.Workspace{
+			ID:   workspace,
+			Name: "workspace",
+			Slug: fmt.Sprintf("ws-%d", workspace),
+		}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return f
+}
+
+func (f *tenantFixture) personal(user int64) context.Context {
+	return model.ContextWithPrincipal(context.Background(), &model.Principal{
+		Kind:    model.PrincipalToken,
+		Subject: fmt.Sprintf("%d", user),
+		UserID:  user,
+	})
+}
+
+func (f *tenantFixture) workspace(user, workspace int64) context.Context {
+	return model.ContextWithWorkspace(f.personal(user), workspace)
+}
+
+func createTestTask(t *testing.T, repo model.TaskRepository, ctx context.Context, input model.CreateTaskInput) *model.Task {
+	t.Helper()
+
+	if input.Status == "" {
+		input.Status = model.TaskStatusTodo
+	}
+	// the usecase sets the status of new tasks, so that done ones are completed
+	task := input.ToModel()
+	task.SetStatus(task.Status, task.CreatedAt)
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create(%q) error = %v", input.Title, err)
+	}
+	return task
+}
+
+func taskIDs(tasks []*model.Task) []int64 {
+	IDs := []int64{}
+	for _, task := range tasks {
+		IDs = append(IDs, task.ID)
+	}
+	return IDs
+}
+
+func TestTaskRepositoryTenantIsolation(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+
+	taskRepo := NewTaskRepository(db.app, nopCacheRepo{})
+	projectRepo := NewProjectRepository(db.app, nopCacheRepo{})
+	tagRepo := NewTagRepository(db.app, nopCacheRepo{})
+
+	ctxA := f.workspace(f.userA, f.workspaceA)
+	ctxB := f.workspace(f.userB, f.workspaceB)
+
+	projectA := model.CreateProjectInput{Name: "launch"}.ToModel()
+	if err := projectRepo.Create(ctxA, projectA); err != nil {
+		t.Fatal(err)
+	}
+	projectB := model.CreateProjectInput{Name: "launch"}.ToModel()
+	if err := projectRepo.Create(ctxB, projectB); err != nil {
+		t.Fatal(err)
+	}
+
+	taskA := createTestTask(t, taskRepo, ctxA, model.CreateTaskInput{Title: "quarterly report", ProjectID: &projectA.ID, Tags: []string{"urgent"}})
+	taskB := createTestTask(t, taskRepo, ctxB, model.CreateTaskInput{Title: "quarterly report", ProjectID: &projectB.ID, Tags: []string{"urgent"}})
+	page := model.Pagination{Page: 1, Size: 10}
+
+	t.Run("list", func(t *testing.T) {
+		tasks, err := taskRepo.FindAll(ctxA, model.GetTasksQueryParams{Pagination: page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{taskA.ID}) {
+			t.Errorf("FindAll() = %v, want %v", got, []int64{taskA.ID})
+		}
+
+		count, err := taskRepo.CountAll(ctxA, model.GetTasksQueryParams{Pagination: page})
+		if err != nil || count != 1 {
+			t.Errorf("CountAll() = %d, %v, want 1", count, err)
+		}
+	})
+
+	t.Run("list by cursor", func(t *testing.T) {
+		tasks, err := taskRepo.FindAllByCursor(ctxA, model.GetTasksQueryParams{Pagination: page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{taskA.ID}) {
+			t.Errorf("FindAllByCursor() = %v, want %v", got, []int64{taskA.ID})
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		task, err := taskRepo.FindByID(ctxA, taskA.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(task.Tags) != 1 || task.Tags[0].WorkspaceID == nil || *task.Tags[0].WorkspaceID != f.workspaceA {
+			t.Errorf("FindByID() tags = %+v, want the urgent tag of workspace %d", task.Tags, f.workspaceA)
+		}
+
+		if _, err := taskRepo.FindByID(ctxA, taskB.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("FindByID() of another workspace error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		tasks, err := taskRepo.FindAll(ctxA, model.GetTasksQueryParams{Pagination: page, Query: "report"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{taskA.ID}) {
+			t.Errorf("FindAll(q=report) = %v, want %v", got, []int64{taskA.ID})
+		}
+
+		tasks, err = taskRepo.FindAll(ctxA, model.GetTasksQueryParams{Pagination: page, Tags: "urgent"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{taskA.ID}) {
+			t.Errorf("FindAll(tags=urgent) = %v, want %v", got, []int64{taskA.ID})
+		}
+	})
+
+	t.Run("board", func(t *testing.T) {
+		tasks, err := taskRepo.FindAllByStatus(ctxA, model.GetBoardQueryParams{Size: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{taskA.ID}) {
+			t.Errorf("FindAllByStatus() = %v, want %v", got, []int64{taskA.ID})
+		}
+
+		counts, err := taskRepo.CountAllByStatus(ctxA, model.GetBoardQueryParams{Size: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(counts) != 1 || counts[model.TaskStatusTodo] != 1 {
+			t.Errorf("CountAllByStatus() = %v, want one todo task", counts)
+		}
+
+		err = taskRepo.MoveByID(ctxA, model.TaskMove{ID: taskB.ID, Status: model.TaskStatusInProgress})
+		if !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("MoveByID() of another workspace error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		events, err := taskRepo.FindEventsByTaskID(ctxA, taskA.ID, model.GetTaskHistoryQueryParams{Pagination: page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) == 0 {
+			t.Error("FindEventsByTaskID() found no event of the task")
+		}
+
+		count, err := taskRepo.CountEventsByTaskID(ctxA, taskB.ID)
+		if err != nil || count != 0 {
+			t.Errorf("CountEventsByTaskID() of another workspace = %d, %v, want 0", count, err)
+		}
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		trashed := createTestTask(t, taskRepo, ctxB, model.CreateTaskInput{Title: "old draft"})
+		if err := taskRepo.DeleteByID(ctxB, trashed.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		tasks, err := taskRepo.FindAllTrashed(ctxA, model.GetTrashedTasksQueryParams{Pagination: page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tasks) != 0 {
+			t.Errorf("FindAllTrashed() = %v, want none", taskIDs(tasks))
+		}
+
+		if err := taskRepo.RestoreByID(ctxA, trashed.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("RestoreByID() of another workspace error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+		if err := taskRepo.PurgeByID(ctxA, trashed.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("PurgeByID() of another workspace error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+
+		tasks, err = taskRepo.FindAllTrashed(ctxB, model.GetTrashedTasksQueryParams{Pagination: page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, []int64{trashed.ID}) {
+			t.Errorf("FindAllTrashed() of the owning workspace = %v, want %v", got, []int64{trashed.ID})
+		}
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		tags, err := tagRepo.FindAll(ctxA, model.GetTagsQueryParams{Pagination: page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tags) != 1 || tags[0].Name != "urgent" {
+			t.Fatalf("FindAll() = %+v, want the urgent tag only", tags)
+		}
+
+		tagB, err := tagRepo.FindByName(ctxB, "urgent")
+		if err != nil || tagB == nil || tagB.ID == tags[0].ID {
+			t.Fatalf("FindByName() = %+v, %v, want a tag of its own for workspace B", tagB, err)
+		}
+
+		if _, err := tagRepo.FindByID(ctxA, tagB.ID); !errors.Is(err, model.ErrTagNotFound) {
+			t.Errorf("FindByID() of another workspace error = %v, want %v", err, model.ErrTagNotFound)
+		}
+		if err := tagRepo.DeleteByID(ctxA, tagB.ID); !errors.Is(err, model.ErrTagNotFound) {
+			t.Errorf("DeleteByID() of another workspace error = %v, want %v", err, model.ErrTagNotFound)
+		}
+	})
+
+	t.Run("projects", func(t *testing.T) {
+		projects, err := projectRepo.FindAll(ctxA, model.GetProjectsQueryParams{Pagination: page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(projects) != 1 || projects[0].ID != projectA.ID || projects[0].TaskCount != 1 {
+			t.Errorf("FindAll() = %+v, want project %d with one task", projects, projectA.ID)
+		}
+
+		if _, err := projectRepo.FindByID(ctxA, projectB.ID); !errors.Is(err, model.ErrProjectNotFound) {
+			t.Errorf("FindByID() of another workspace error = %v, want %v", err, model.ErrProjectNotFound)
+		}
+
+		task := model.CreateTaskInput{Title: "sneaky", Status: model.TaskStatusTodo, ProjectID: &projectB.ID}.ToModel()
+		if err := taskRepo.Create(ctxA, task); !errors.Is(err, model.ErrTaskProjectInvalid) {
+			t.Errorf("Create() with a project of another workspace error = %v, want %v", err, model.ErrTaskProjectInvalid)
+		}
+	})
+
+	t.Run("personal tasks", func(t *testing.T) {
+		ctxUserA, ctxUserB := f.personal(f.userA), f.personal(f.userB)
+		personal := createTestTask(t, taskRepo, ctxUserA, model.CreateTaskInput{Title: "dentist"})
+
+		if _, err := taskRepo.FindByID(ctxUserB, personal.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("FindByID() of another user error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+		if _, err := taskRepo.FindByID(ctxA, personal.ID); !errors.Is(err, model.ErrTaskNotFound) {
+			t.Errorf("FindByID() from a workspace error = %v, want %v", err, model.ErrTaskNotFound)
+		}
+		if _, err := taskRepo.FindByID(ctxUserA, personal.ID); err != nil {
+			t.Errorf("FindByID() of the owner error = %v", err)
+		}
+	})
+}
+
+// TestTenantPolicies checks the policies themselves on the connection of the
+// API, the scopes of the repositories left aside
+func TestTenantPolicies(t *testing.T) {
+	db := openIntegrationDB(t)
+	f := newTenantFixture(t, db)
+
+	role := struct {
+		RolSuper     bool
+		RolBypassRLS bool
+	}{}
+	err := db.app.Raw(`SELECT rolsuper, rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&role).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role.RolSuper || role.RolBypassRLS {
+		t.Fatalf("the API role is a superuser or bypasses row level security: %+v", role)
+	}
+
+	taskRepo := NewTaskRepository(db.app, nopCacheRepo{})
+	taskA := createTestTask(t, taskRepo, f.workspace(f.userA, f.workspaceA), model.CreateTaskInput{Title: "a"})
+	taskB := createTestTask(t, taskRepo, f.workspace(f.userB, f.workspaceB), model.CreateTaskInput{Title: "b"})
+	IDs := []int64{taskA.ID, taskB.ID}
+
+	visible := func(t *testing.T, tx *gorm.DB) []int64 {
+		t.Helper()
+
+		visible := []int64{}
+		err := tx.Model(&model.Task{}).Unscoped().Where("id IN ?", IDs).Order("id").Pluck("id", &visible).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		return visible
+	}
+
+	t.Run("unset settings fail closed", func(t *testing.T) {
+		if got := visible(t, db.app); len(got) != 0 {
+			t.Errorf("visible tasks = %v, want none", got)
+		}
+	})
+
+	t.Run("settings reset after a transaction fail closed", func(t *testing.T) {
+		conn, err := db.app.DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetMaxOpenConns(1)
+		defer conn.SetMaxOpenConns(0)
+
+		err = tenantTx(f.workspace(f.userA, f.workspaceA), db.app, func(tx *gorm.DB) error { return nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := visible(t, db.app); len(got) != 0 {
+			t.Errorf("visible tasks = %v, want none", got)
+		}
+	})
+
+	t.Run("workspace", func(t *testing.T) {
+		err := tenantTx(f.workspace(f.userB, f.workspaceA), db.app, func(tx *gorm.DB) error {
+			if got := visible(t, tx); !slices.Equal(got, []int64{taskA.ID}) {
+				t.Errorf("visible tasks = %v, want %v", got, []int64{taskA.ID})
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("insert into another workspace", func(t *testing.T) {
+		err := tenantTx(f.workspace(f.userA, f.workspaceA), db.app, func(tx *gorm.DB) error {
+			task := model.CreateTaskInput{Title: "c", Status: model.TaskStatusTodo}.ToModel()
+			task.Position = "V"
+			task.WorkspaceID = &f.workspaceB
+			return tx.Omit("Tags", "Series").Create(task).Error
+		})
+		if err == nil {
+			t.Error("inserting a task of another workspace succeeded")
+		}
+	})
+
+	t.Run("worker sees every tenant", func(t *testing.T) {
+		remindAt := time.Now().Add(-time.Minute)
+		err := db.owner.Model(&model.Task{}).Where("id IN ?", IDs).UpdateColumn("remind_at", remindAt).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		workerRepo := NewTaskRepository(db.worker, nopCacheRepo{})
+		tasks, err := workerRepo.ClaimDueNotifications(context.Background(), model.NotificationKindReminder, time.Now(), 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		claimed := taskIDs(tasks)
+		for _, ID := range IDs {
+			if !slices.Contains(claimed, ID) {
+				t.Errorf("ClaimDueNotifications() = %v, missing task %d", claimed, ID)
+			}
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type userRepo struct {
+	db *gorm.DB
+}
+
+// NewUserRepository is not cached, users are only read on login and when
+// minting API keys
+func NewUserRepository(db *gorm.DB) model.UserRepository {
+	return &userRepo{db: db}
+}
+
+func (ur *userRepo) Create(ctx context.Context, user *model.User) error {
+	if err := ur.db.WithContext(ctx).Create(user).Error; err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":   utils.Dump(ctx),
+			"email": user.Email,
+		}).Error(err)
+
+		err = translateError(err, model.ErrUserNotFound)
+		if errors.Is(err, errs.ErrConflict) {
+			return model.ErrUserEmailTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (ur *userRepo) FindByID(ctx context.Context, ID int64) (*model.User, error) {
+	user := &model.User{}
+	if err := ur.db.WithContext(ctx).Where("id = ?", ID).Take(user).Error; err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return nil, translateError(err, model.ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+func (ur *userRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	user := &model.User{}
+	if err := ur.db.WithContext(ctx).Where("email = ?", email).Take(user).Error; err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":   utils.Dump(ctx),
+			"email": email,
+		}).Error(err)
+		return nil, translateError(err, model.ErrUserNotFound)
+	}
+
+	return user, nil
+}
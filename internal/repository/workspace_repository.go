@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type workspaceRepo struct {
+	db *gorm.DB
+}
+
+// NewWorkspaceRepository is not cached, removing a member has to take
+// effect on the next request
+func NewWorkspaceRepository(db *gorm.DB) model.WorkspaceRepository {
+	return &workspaceRepo{db: db}
+}
+
+// Create inserts the workspace together with its first owner
+func (wr *workspaceRepo) Create(ctx context.Context, workspace *model.Workspace, owner *model.WorkspaceMember) error {
+	err := wr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+
+		owner.WorkspaceID = workspace.ID
+		return tx.Create(owner).Error
+	})
+
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":       utils.Dump(ctx),
+			"workspace": utils.Dump(workspace),
+		}).Error(err)
+
+		err = translateError(err, model.ErrWorkspaceNotFound)
+		if errors.Is(err, errs.ErrConflict) {
+			return model.ErrWorkspaceSlugTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepo) FindByID(ctx context.Context, ID int64) (*model.Workspace, error) {
+	workspace := &model.Workspace{}
+	if err := wr.db.WithContext(ctx).Where("id = ?", ID).Take(workspace).Error; err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return nil, translateError(err, model.ErrWorkspaceNotFound)
+	}
+
+	return workspace, nil
+}
+
+func (wr *workspaceRepo) FindBySlug(ctx context.Context, slug string) (*model.Workspace, error) {
+	workspace := &model.Workspace{}
+	if err := wr.db.WithContext(ctx).Where("slug = ?", slug).Take(workspace).Error; err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":  utils.Dump(ctx),
+			"slug": slug,
+		}).Error(err)
+		return nil, translateError(err, model.ErrWorkspaceNotFound)
+	}
+
+	return workspace, nil
+}
+
+func (wr *workspaceRepo) FindAllByUserID(ctx context.Context, userID int64, query model.GetWorkspacesQueryParams) ([]*model.Workspace, error) {
+	workspaces := []*model.Workspace{}
+
+	err := wr.db.WithContext(ctx).
+		Where("id IN (?)", wr.memberWorkspaceIDs(userID)).
+		Order("name ASC, id ASC").
+		Offset(int(model.Offset(query.Page, query.Size))).
+		Limit(int(query.Size)).
+		Find(&workspaces).
+		Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":    utils.Dump(ctx),
+			"userID": userID,
+			"query":  utils.Dump(query),
+		}).Error(err)
+		return nil, translateError(err, model.ErrWorkspaceNotFound)
+	}
+
+	return workspaces, nil
+}
+
+func (wr *workspaceRepo) CountAllByUserID(ctx context.Context, userID int64) (int64, error) {
+	count := int64(0)
+	err := wr.db.WithContext(ctx).
+		Model(&model.Workspace{}).
+		Where("id IN (?)", wr.memberWorkspaceIDs(userID)).
+		Count(&count).
+		Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":    utils.Dump(ctx),
+			"userID": userID,
+		}).Error(err)
+		return int64(0), translateError(err, model.ErrWorkspaceNotFound)
+	}
+
+	return count, nil
+}
+
+func (wr *workspaceRepo) FindMember(ctx context.Context, workspaceID, userID int64) (*model.WorkspaceMember, error) {
+	member := &model.WorkspaceMember{}
+	err := wr.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Take(member).
+		Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":         utils.Dump(ctx),
+			"workspaceID": workspaceID,
+			"userID":      userID,
+		}).Error(err)
+		return nil, translateError(err, model.ErrWorkspaceMemberNotFound)
+	}
+
+	return member, nil
+}
+
+func (wr *workspaceRepo) CountOwners(ctx context.Context, workspaceID int64) (int64, error) {
+	count := int64(0)
+	err := wr.db.WithContext(ctx).
+		Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, model.WorkspaceRoleOwner).
+		Count(&count).
+		Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":         utils.Dump(ctx),
+			"workspaceID": workspaceID,
+		}).Error(err)
+		return int64(0), translateError(err, model.ErrWorkspaceMemberNotFound)
+	}
+
+	return count, nil
+}
+
+func (wr *workspaceRepo) AddMember(ctx context.Context, member *model.WorkspaceMember) error {
+	if err := wr.db.WithContext(ctx).Create(member).Error; err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":    utils.Dump(ctx),
+			"member": utils.Dump(member),
+		}).Error(err)
+
+		err = translateError(err, model.ErrWorkspaceMemberNotFound)
+		if errors.Is(err, errs.ErrConflict) {
+			return model.ErrWorkspaceMemberExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepo) DeleteMember(ctx context.Context, workspaceID, userID int64) error {
+	res := wr.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Delete(&model.WorkspaceMember{})
+	if res.Error != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":         utils.Dump(ctx),
+			"workspaceID": workspaceID,
+			"userID":      userID,
+		}).Error(res.Error)
+		return translateError(res.Error, model.ErrWorkspaceMemberNotFound)
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrWorkspaceMemberNotFound
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepo) memberWorkspaceIDs(userID int64) *gorm.DB {
+	return wr.db.Model(&model.WorkspaceMember{}).
+		Select("workspace_id").
+		Where("user_id = ?", userID)
+}
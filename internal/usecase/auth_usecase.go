@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type authUsecase struct {
+	apiKeyRepo model.APIKeyRepository
+	keys       model.TokenKeys
+}
+
+func NewAuthUsecase(ar model.APIKeyRepository, keys model.TokenKeys) model.AuthUsecase {
+	return &authUsecase{apiKeyRepo: ar, keys: keys}
+}
+
+func (au *authUsecase) AuthenticateToken(ctx context.Context, token string) (*model.Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithJSONNumber(),
+	}
+	if au.keys.Issuer != "" {
+		options = append(options, jwt.WithIssuer(au.keys.Issuer))
+	}
+	if au.keys.Audience != "" {
+		options = append(options, jwt.WithAudience(au.keys.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, au.verificationKey, options...); err != nil {
+		return nil, model.ErrInvalidToken.Wrap(err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, model.ErrInvalidToken.Wrap(errors.New("sub claim is required"))
+	}
+
+	// the subject is the ID of the user, as issued on login
+	userID, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		return nil, model.ErrInvalidToken.Wrap(errors.New("sub claim is not a user ID"))
+	}
+
+	return &model.Principal{
+		Kind:    model.PrincipalToken,
+		Subject: subject,
+		UserID:  userID,
+		Claims:  claims,
+	}, nil
+}
+
+// verificationKey picks the key matching the algorithm and kid of token,
+// algorithms without a configured key are rejected
+func (au *authUsecase) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(au.keys.HMACSecret) == 0 {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return au.keys.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := au.keys.RSAKeys[kid]; ok {
+			return key, nil
+		}
+		if key, ok := au.keys.RSAKeys[""]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("no key for kid %q", kid)
+	default:
+		return nil, fmt.Errorf("algorithm %v is not accepted", token.Header["alg"])
+	}
+}
+
+func (au *authUsecase) AuthenticateAPIKey(ctx context.Context, secret string) (*model.Principal, error) {
+	logger := logrus.WithField("ctx", utils.Dump(ctx))
+
+	key, err := au.apiKeyRepo.FindByHash(ctx, model.HashAPIKey(secret))
+	switch {
+	case errors.Is(err, model.ErrAPIKeyNotFound):
+		return nil, model.ErrInvalidAPIKey
+	case err != nil:
+		logger.Error(err)
+		return nil, err
+	case key.RevokedAt != nil, key.UserID == nil:
+		return nil, model.ErrInvalidAPIKey
+	}
+
+	// the last use is informational, failing to record it does not fail
+	// the request
+	if err := au.apiKeyRepo.TouchByID(ctx, key.ID, time.Now()); err != nil {
+		logger.Error(err)
+	}
+
+	return &model.Principal{
+		Kind:    model.PrincipalAPIKey,
+		Subject: fmt.Sprintf("api_key:%d", key.ID),
+		UserID:  *key.UserID,
+	}, nil
+}
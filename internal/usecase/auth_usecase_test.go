@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"todo-app/internal/model"
+)
+
+func TestAuthUsecaseAuthenticateToken(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := model.TokenKeys{
+		HMACSecret: secret,
+		RSAKeys:    map[string]*rsa.PublicKey{"k1": &rsaKey.PublicKey},
+		Issuer:     "https://issuer.test",
+		Audience:   "todo-api",
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "42",
+			"iss": keys.Issuer,
+			"aud": keys.Audience,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	with := func(key string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	hs256 := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	rs256 := func(key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs512, err := jwt.NewWithClaims(jwt.SigningMethodHS512, validClaims()).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		keys       model.TokenKeys
+		token      string
+		wantUserID int64
+		wantErr    bool
+	}{
+		{name: "hs256", keys: keys, token: hs256(validClaims()), wantUserID: 42},
+		{name: "rs256 by kid", keys: keys, token: rs256(rsaKey, "k1", validClaims()), wantUserID: 42},
+		{name: "rs256 with an unknown kid", keys: keys, token: rs256(rsaKey, "k2", validClaims()), wantErr: true},
+		{name: "rs256 without kid and no default key", keys: keys, token: rs256(rsaKey, "", validClaims()), wantErr: true},
+		{
+			name:       "rs256 without kid on the default key",
+			keys:       model.TokenKeys{RSAKeys: map[string]*rsa.PublicKey{"": &rsaKey.PublicKey}},
+			token:      rs256(rsaKey, "", validClaims()),
+			wantUserID: 42,
+		},
+		{name: "rs256 signed by another key", keys: keys, token: rs256(otherKey, "k1", validClaims()), wantErr: true},
+		{name: "hs256 without a secret", keys: model.TokenKeys{RSAKeys: keys.RSAKeys}, token: hs256(validClaims()), wantErr: true},
+		{name: "alg none", keys: keys, token: unsigned, wantErr: true},
+		{name: "alg not accepted", keys: keys, token: hs512, wantErr: true},
+		{name: "tampered signature", keys: keys, token: hs256(validClaims()) + "x", wantErr: true},
+		{name: "wrong issuer", keys: keys, token: hs256(with("iss", "https://other.test")), wantErr: true},
+		{name: "missing issuer", keys: keys, token: hs256(with("iss", nil)), wantErr: true},
+		{name: "wrong audience", keys: keys, token: hs256(with("aud", "other-api")), wantErr: true},
+		{name: "audience among several", keys: keys, token: hs256(with("aud", []string{"other-api", "todo-api"})), wantUserID: 42},
+		{name: "expired", keys: keys, token: hs256(with("exp", time.Now().Add(-time.Minute).Unix())), wantErr: true},
+		{name: "missing expiry", keys: keys, token: hs256(with("exp", nil)), wantErr: true},
+		{name: "not yet valid", keys: keys, token: hs256(with("nbf", time.Now().Add(time.Hour).Unix())), wantErr: true},
+		{name: "missing subject", keys: keys, token: hs256(with("sub", nil)), wantErr: true},
+		{name: "subject is not a user ID", keys: keys, token: hs256(with("sub", "alice")), wantErr: true},
+		{name: "issuer and audience unchecked when not configured", keys: model.TokenKeys{HMACSecret: secret}, token: hs256(with("iss", "https://other.test")), wantUserID: 42},
+		{name: "not a token", keys: keys, token: "token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := NewAuthUsecase(nil, tt.keys).AuthenticateToken(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, model.ErrInvalidToken) {
+					t.Fatalf("AuthenticateToken() error = %v, want %v", err, model.ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateToken() error = %v", err)
+			}
+
+			if principal.Kind != model.PrincipalToken || principal.UserID != tt.wantUserID {
+				t.Errorf("AuthenticateToken() = %s user %d, want %s user %d", principal.Kind, principal.UserID, model.PrincipalToken, tt.wantUserID)
+			}
+		})
+	}
+}
+
+type fakeAPIKeyRepo struct {
+	model.APIKeyRepository
+
+	keys    map[string]*model.APIKey
+	err     error
+	touched []int64
+}
+
+func (r *fakeAPIKeyRepo) FindByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	key, ok := r.keys[hash]
+	if !ok {
+		return nil, model.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepo) TouchByID(ctx context.Context, ID int64, usedAt time.Time) error {
+	r.touched = append(r.touched, ID)
+	return nil
+}
+
+func TestAuthUsecaseAuthenticateAPIKey(t *testing.T) {
+	userID := int64(42)
+	revokedAt := time.Now()
+	errDown := errors.New("connection refused")
+
+	keys := map[string]*model.APIKey{
+		model.HashAPIKey("tdk_live"):    {ID: 1, UserID: &userID},
+		model.HashAPIKey("tdk_revoked"): {ID: 2, UserID: &userID, RevokedAt: &revokedAt},
+		model.HashAPIKey("tdk_orphan"):  {ID: 3},
+	}
+
+	tests := []struct {
+		name        string
+		secret      string
+		err         error
+		wantErr     error
+		wantTouched []int64
+	}{
+		{name: "live key", secret: "tdk_live", wantTouched: []int64{1}},
+		{name: "unknown key", secret: "tdk_unknown", wantErr: model.ErrInvalidAPIKey},
+		{name: "revoked key", secret: "tdk_revoked", wantErr: model.ErrInvalidAPIKey},
+		{name: "key without a user", secret: "tdk_orphan", wantErr: model.ErrInvalidAPIKey},
+		{name: "repository failure is not a bad key", secret: "tdk_live", err: errDown, wantErr: errDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAPIKeyRepo{keys: keys, err: tt.err}
+			principal, err := NewAuthUsecase(repo, model.TokenKeys{}).AuthenticateAPIKey(context.Background(), tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateAPIKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.touched) != len(tt.wantTouched) {
+				t.Errorf("touched %v, want %v", repo.touched, tt.wantTouched)
+			}
+			if err != nil {
+				return
+			}
+
+			if principal.Kind != model.PrincipalAPIKey || principal.UserID != userID {
+				t.Errorf("AuthenticateAPIKey() = %s user %d, want %s user %d", principal.Kind, principal.UserID, model.PrincipalAPIKey, userID)
+			}
+		})
+	}
+}
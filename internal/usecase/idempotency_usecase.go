@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type idempotencyUsecase struct {
+	cacheRepo  model.CacheRepository
+	ttl        time.Duration
+	pendingTTL time.Duration
+}
+
+// NewIdempotencyUsecase keeps responses for ttl. A key is held for
+// pendingTTL while its request is in progress, so that a replica dying
+// mid-request does not block the key for the whole ttl.
+func NewIdempotencyUsecase(cr model.CacheRepository, ttl, pendingTTL time.Duration) model.IdempotencyUsecase {
+	return &idempotencyUsecase{
+		cacheRepo:  cr,
+		ttl:        ttl,
+		pendingTTL: pendingTTL,
+	}
+}
+
+func (iu *idempotencyUsecase) Do(ctx context.Context, scope, key, requestHash string, fn func(ctx context.Context) (int, interface{}, error)) (*model.IdempotentResponse, bool, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":         utils.Dump(ctx),
+		"scope":       scope,
+		"key":         key,
+		"requestHash": requestHash,
+	})
+
+	// keys are chosen by the clients, the ones of different users and
+	// workspaces are kept apart so that none replays the response of another
+	userID, _ := model.UserIDFromContext(ctx)
+	workspaceID, _ := model.WorkspaceIDFromContext(ctx)
+	cacheKey := fmt.Sprintf("idempotency:%s:user:%d:workspace:%d:%s", scope, userID, workspaceID, key)
+
+	pending, err := json.Marshal(model.IdempotentResponse{RequestHash: requestHash})
+	if err != nil {
+		logger.Error(err)
+		return nil, false, err
+	}
+
+	// the key is claimed before running fn so that concurrent retries do
+	// not run it twice
+	claimed, err := iu.cacheRepo.SetNX(ctx, cacheKey, string(pending), iu.pendingTTL)
+	if err != nil {
+		logger.Error(err)
+		return nil, false, err
+	}
+
+	if !claimed {
+		response, err := iu.stored(ctx, cacheKey, requestHash)
+		if err != nil {
+			logger.Error(err)
+			return nil, false, err
+		}
+		return response, true, nil
+	}
+
+	status, body, err := fn(ctx)
+	if err != nil {
+		iu.release(ctx, cacheKey)
+		return nil, false, err
+	}
+
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		logger.Error(err)
+		iu.release(ctx, cacheKey)
+		return nil, false, err
+	}
+
+	response := &model.IdempotentResponse{
+		RequestHash: requestHash,
+		Status:      status,
+		Body:        bytes,
+	}
+
+	stored, err := json.Marshal(response)
+	if err != nil {
+		logger.Error(err)
+		return response, false, nil
+	}
+
+	// storing the response extends the key to the full ttl. fn already ran,
+	// failing to store its response must not fail the request, a retry then
+	// sees the key in progress until the pending key expires.
+	if err := iu.cacheRepo.SetEX(context.WithoutCancel(ctx), cacheKey, string(stored), iu.ttl); err != nil {
+		logger.Error(err)
+	}
+
+	return response, false, nil
+}
+
+// release frees the key for a retry after its request failed, even when the
+// client went away
+func (iu *idempotencyUsecase) release(ctx context.Context, cacheKey string) {
+	if err := iu.cacheRepo.Delete(context.WithoutCancel(ctx), cacheKey); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":      utils.Dump(ctx),
+			"cacheKey": cacheKey,
+		}).Error(err)
+	}
+}
+
+func (iu *idempotencyUsecase) stored(ctx context.Context, cacheKey, requestHash string) (*model.IdempotentResponse, error) {
+	reply, err := iu.cacheRepo.Get(ctx, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// the key expired between claiming and reading it
+	if reply == "" {
+		return nil, model.ErrIdempotencyKeyInProgress
+	}
+
+	response := &model.IdempotentResponse{}
+	if err := json.Unmarshal([]byte(reply), response); err != nil {
+		return nil, err
+	}
+
+	if response.RequestHash != requestHash {
+		return nil, model.ErrIdempotencyKeyReused
+	}
+
+	if !response.Done() {
+		return nil, model.ErrIdempotencyKeyInProgress
+	}
+
+	return response, nil
+}
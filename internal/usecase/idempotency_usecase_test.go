@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"todo-app/internal/model"
+)
+
+// memoryCacheRepo keeps plain keys in memory along with the ttl they were
+// last set with
+type memoryCacheRepo struct {
+	model.CacheRepository
+
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+func newMemoryCacheRepo() *memoryCacheRepo {
+	return &memoryCacheRepo{values: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (r *memoryCacheRepo) Get(ctx context.Context, key string) (string, error) {
+	return r.values[key], nil
+}
+
+func (r *memoryCacheRepo) SetEX(ctx context.Context, key, val string, ttl time.Duration) error {
+	r.values[key], r.ttls[key] = val, ttl
+	return nil
+}
+
+func (r *memoryCacheRepo) SetNX(ctx context.Context, key, val string, ttl time.Duration) (bool, error) {
+	if _, ok := r.values[key]; ok {
+		return false, nil
+	}
+	r.values[key], r.ttls[key] = val, ttl
+	return true, nil
+}
+
+func (r *memoryCacheRepo) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(r.values, key)
+		delete(r.ttls, key)
+	}
+	return nil
+}
+
+func TestIdempotencyUsecaseDo(t *testing.T) {
+	const (
+		ttl        = 24 * time.Hour
+		pendingTTL = 2 * time.Minute
+		scope      = "task:create"
+		key        = "key-1"
+		cacheKey   = "idempotency:task:create:user:0:workspace:0:key-1"
+	)
+	errFailed := errors.New("insert failed")
+
+	created := func(ctx context.Context) (int, interface{}, error) {
+		return http.StatusCreated, map[string]int{"id": 1}, nil
+	}
+	failed := func(ctx context.Context) (int, interface{}, error) {
+		return 0, nil, errFailed
+	}
+
+	type call struct {
+		hash         string
+		fn           func(ctx context.Context) (int, interface{}, error)
+		wantReplayed bool
+		wantErr      error
+	}
+
+	tests := []struct {
+		name string
+		// pending leaves the key in progress for hash "a" before the calls,
+		// as a replica dying mid-request does
+		pending bool
+		calls   []call
+		// wantTTL is the ttl of the key after the calls, 0 when it is gone
+		wantTTL time.Duration
+	}{
+		{
+			name:    "response is kept for the full ttl",
+			calls:   []call{{hash: "a", fn: created}},
+			wantTTL: ttl,
+		},
+		{
+			name: "retry replays the response",
+			calls: []call{
+				{hash: "a", fn: created},
+				{hash: "a", fn: failed, wantReplayed: true},
+			},
+			wantTTL: ttl,
+		},
+		{
+			name: "key reused with another request",
+			calls: []call{
+				{hash: "a", fn: created},
+				{hash: "b", fn: created, wantErr: model.ErrIdempotencyKeyReused},
+			},
+			wantTTL: ttl,
+		},
+		{
+			name:    "failed request frees the key",
+			calls:   []call{{hash: "a", fn: failed, wantErr: errFailed}},
+			wantTTL: 0,
+		},
+		{
+			name: "retry after a failed request runs again",
+			calls: []call{
+				{hash: "a", fn: failed, wantErr: errFailed},
+				{hash: "a", fn: created},
+			},
+			wantTTL: ttl,
+		},
+		{
+			name:    "request in progress holds the key for the pending ttl only",
+			pending: true,
+			calls:   []call{{hash: "a", fn: created, wantErr: model.ErrIdempotencyKeyInProgress}},
+			wantTTL: pendingTTL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newMemoryCacheRepo()
+			iu := NewIdempotencyUsecase(cache, ttl, pendingTTL)
+			ctx := context.Background()
+
+			if tt.pending {
+				cache.values[cacheKey] = `{"request_hash":"a"}`
+				cache.ttls[cacheKey] = pendingTTL
+			}
+
+			for i, c := range tt.calls {
+				response, replayed, err := iu.Do(ctx, scope, key, c.hash, c.fn)
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("call %d: Do() error = %v, want %v", i, err, c.wantErr)
+				}
+				if err != nil {
+					continue
+				}
+				if replayed != c.wantReplayed {
+					t.Errorf("call %d: Do() replayed = %t, want %t", i, replayed, c.wantReplayed)
+				}
+				if response.Status != http.StatusCreated || string(response.Body) != `{"id":1}` {
+					t.Errorf("call %d: Do() = %d %s, want the created response", i, response.Status, response.Body)
+				}
+			}
+
+			if len(cache.ttls) > 1 || cache.ttls[cacheKey] != tt.wantTTL {
+				t.Errorf("keys %v, want %s with ttl %s", cache.ttls, cacheKey, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestIdempotencyUsecaseDoHoldsKeyForPendingTTL(t *testing.T) {
+	cache := newMemoryCacheRepo()
+	iu := NewIdempotencyUsecase(cache, 24*time.Hour, 2*time.Minute)
+
+	_, _, err := iu.Do(context.Background(), "task:create", "key-1", "a", func(ctx context.Context) (int, interface{}, error) {
+		for cacheKey, ttl := range cache.ttls {
+			if ttl != 2*time.Minute {
+				t.Errorf("key %s ttl while in progress = %s, want %s", cacheKey, ttl, 2*time.Minute)
+			}
+		}
+
+		// a concurrent retry does not run the request again
+		_, _, err := iu.Do(ctx, "task:create", "key-1", "a", func(ctx context.Context) (int, interface{}, error) {
+			t.Error("request ran twice")
+			return http.StatusCreated, nil, nil
+		})
+		if !errors.Is(err, model.ErrIdempotencyKeyInProgress) {
+			t.Errorf("Do() while in progress error = %v, want %v", err, model.ErrIdempotencyKeyInProgress)
+		}
+
+		return http.StatusCreated, nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
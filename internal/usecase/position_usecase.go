@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type positionUsecase struct {
+	taskRepo  model.TaskRepository
+	maxLength int
+}
+
+func NewPositionUsecase(tr model.TaskRepository, maxLength int) model.PositionUsecase {
+	return &positionUsecase{
+		taskRepo:  tr,
+		maxLength: maxLength,
+	}
+}
+
+// Rebalance leaves the positions alone while they are short enough, moves
+// only ever lengthen the ranks around the moved task so a full renumbering
+// is rarely needed
+func (pu *positionUsecase) Rebalance(ctx context.Context) (int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":       utils.Dump(ctx),
+		"maxLength": pu.maxLength,
+	})
+
+	length, err := pu.taskRepo.MaxPositionLength(ctx)
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	if length <= pu.maxLength {
+		return 0, nil
+	}
+
+	rebalanced, err := pu.taskRepo.RebalancePositions(ctx)
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	return rebalanced, nil
+}
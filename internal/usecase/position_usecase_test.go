@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"todo-app/internal/model"
+)
+
+type rebalancingTaskRepo struct {
+	model.TaskRepository
+
+	length     int
+	lengthErr  error
+	rebalanced bool
+}
+
+func (r *rebalancingTaskRepo) MaxPositionLength(ctx context.Context) (int, error) {
+	return r.length, r.lengthErr
+}
+
+func (r *rebalancingTaskRepo) RebalancePositions(ctx context.Context) (int64, error) {
+	r.rebalanced = true
+	return 3, nil
+}
+
+func TestPositionUsecaseRebalance(t *testing.T) {
+	errDown := errors.New("database is down")
+
+	tests := []struct {
+		name           string
+		length         int
+		lengthErr      error
+		wantRebalanced int64
+		wantErr        error
+	}{
+		{name: "no tasks", length: 0},
+		{name: "short positions", length: 8},
+		{name: "at the limit", length: 16},
+		{name: "past the limit", length: 17, wantRebalanced: 3},
+		{name: "length unknown", lengthErr: errDown, wantErr: errDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &rebalancingTaskRepo{length: tt.length, lengthErr: tt.lengthErr}
+
+			rebalanced, err := NewPositionUsecase(repo, 16).Rebalance(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rebalance() error = %v, want %v", err, tt.wantErr)
+			}
+			if rebalanced != tt.wantRebalanced {
+				t.Errorf("Rebalance() = %d, want %d", rebalanced, tt.wantRebalanced)
+			}
+			if repo.rebalanced != (tt.wantRebalanced > 0) {
+				t.Errorf("positions rebalanced = %t, want %t", repo.rebalanced, tt.wantRebalanced > 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type projectUsecase struct {
+	projectRepo model.ProjectRepository
+}
+
+func NewProjectUsecase(pr model.ProjectRepository) model.ProjectUsecase {
+	return &projectUsecase{projectRepo: pr}
+}
+
+func (pu *projectUsecase) Create(ctx context.Context, project *model.Project) (*model.Project, error) {
+	if err := pu.projectRepo.Create(ctx, project); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":     utils.Dump(ctx),
+			"project": utils.Dump(project),
+		}).Error(err)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (pu *projectUsecase) DeleteByID(ctx context.Context, ID int64) error {
+	if err := pu.projectRepo.DeleteByID(ctx, ID); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (pu *projectUsecase) FindByID(ctx context.Context, ID int64) (*model.Project, error) {
+	project, err := pu.projectRepo.FindByID(ctx, ID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (pu *projectUsecase) FindAll(ctx context.Context, params model.GetProjectsQueryParams) ([]*model.Project, int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"params": utils.Dump(params),
+	})
+
+	projects, err := pu.projectRepo.FindAll(ctx, params)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	count, err := pu.projectRepo.CountAll(ctx)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	return projects, count, nil
+}
+
+func (pu *projectUsecase) Update(ctx context.Context, project *model.Project) (*model.Project, error) {
+	project, err := pu.projectRepo.Update(ctx, project)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":     utils.Dump(ctx),
+			"project": utils.Dump(project),
+		}).Error(err)
+		return nil, err
+	}
+
+	return project, nil
+}
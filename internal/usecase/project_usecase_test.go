@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"todo-app/internal/model"
+)
+
+// memoryProjectRepo holds the projects by ID along with the tasks counted
+// for them, which it detaches when their project is deleted
+type memoryProjectRepo struct {
+	model.ProjectRepository
+
+	projects map[int64]*model.Project
+	tasks    map[int64]*model.Task
+	errCount error
+}
+
+func newMemoryProjectRepo() *memoryProjectRepo {
+	return &memoryProjectRepo{projects: map[int64]*model.Project{}, tasks: map[int64]*model.Task{}}
+}
+
+func (r *memoryProjectRepo) Create(ctx context.Context, project *model.Project) error {
+	r.projects[project.ID] = project
+	return nil
+}
+
+func (r *memoryProjectRepo) DeleteByID(ctx context.Context, ID int64) error {
+	if _, ok := r.projects[ID]; !ok {
+		return model.ErrProjectNotFound
+	}
+	delete(r.projects, ID)
+	for _, task := range r.tasks {
+		if task.ProjectID != nil && *task.ProjectID == ID {
+			task.ProjectID = nil
+		}
+	}
+	return nil
+}
+
+func (r *memoryProjectRepo) FindByID(ctx context.Context, ID int64) (*model.Project, error) {
+	project, ok := r.projects[ID]
+	if !ok {
+		return nil, model.ErrProjectNotFound
+	}
+	project.TaskCount, project.OpenTaskCount = 0, 0
+	for _, task := range r.tasks {
+		if task.ProjectID != nil && *task.ProjectID == ID {
+			project.TaskCount++
+			if !task.Completed {
+				project.OpenTaskCount++
+			}
+		}
+	}
+	return project, nil
+}
+
+func (r *memoryProjectRepo) FindAll(ctx context.Context, query model.GetProjectsQueryParams) ([]*model.Project, error) {
+	IDs := slices.Sorted(maps.Keys(r.projects))
+	slices.Reverse(IDs)
+
+	projects := []*model.Project{}
+	for _, ID := range IDs {
+		project, _ := r.FindByID(ctx, ID)
+		projects = append(projects, project)
+	}
+
+	offset := int(model.Offset(query.Page, query.Size))
+	if offset > len(projects) {
+		return []*model.Project{}, nil
+	}
+	return projects[offset:min(offset+int(query.Size), len(projects))], nil
+}
+
+func (r *memoryProjectRepo) CountAll(ctx context.Context) (int64, error) {
+	return int64(len(r.projects)), r.errCount
+}
+
+func (r *memoryProjectRepo) Update(ctx context.Context, project *model.Project) (*model.Project, error) {
+	if _, ok := r.projects[project.ID]; !ok {
+		return nil, model.ErrProjectNotFound
+	}
+	r.projects[project.ID] = project
+	return r.FindByID(ctx, project.ID)
+}
+
+func TestProjectUsecaseCRUD(t *testing.T) {
+	repo := newMemoryProjectRepo()
+	pu := NewProjectUsecase(repo)
+	ctx := context.Background()
+
+	project, err := pu.Create(ctx, &model.Project{ID: 1, Name: "website"})
+	if err != nil || project.ID != 1 {
+		t.Fatalf("Create() = %+v, %v", project, err)
+	}
+
+	project, err = pu.Update(ctx, model.UpdateProjectInput{Name: "homepage"}.ToModel(1))
+	if err != nil || project.Name != "homepage" {
+		t.Fatalf("Update() = %+v, %v, want homepage", project, err)
+	}
+
+	if _, err := pu.Update(ctx, model.UpdateProjectInput{Name: "blog"}.ToModel(2)); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("Update() of a missing project error = %v, want %v", err, model.ErrProjectNotFound)
+	}
+
+	if err := pu.DeleteByID(ctx, 1); err != nil {
+		t.Fatalf("DeleteByID() error = %v", err)
+	}
+	if _, err := pu.FindByID(ctx, 1); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("FindByID() of a deleted project error = %v, want %v", err, model.ErrProjectNotFound)
+	}
+	if err := pu.DeleteByID(ctx, 1); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("DeleteByID() twice error = %v, want %v", err, model.ErrProjectNotFound)
+	}
+}
+
+func TestProjectUsecaseFindAll(t *testing.T) {
+	errCount := errors.New("count failed")
+
+	tests := []struct {
+		name      string
+		query     model.GetProjectsQueryParams
+		errCount  error
+		wantIDs   []int64
+		wantCount int64
+		wantErr   error
+	}{
+		{name: "first page", query: model.GetProjectsQueryParams{Pagination: model.Pagination{Page: 1, Size: 2}}, wantIDs: []int64{3, 2}, wantCount: 3},
+		{name: "last page", query: model.GetProjectsQueryParams{Pagination: model.Pagination{Page: 2, Size: 2}}, wantIDs: []int64{1}, wantCount: 3},
+		{name: "past the last page", query: model.GetProjectsQueryParams{Pagination: model.Pagination{Page: 3, Size: 2}}, wantIDs: []int64{}, wantCount: 3},
+		{name: "count fails", query: model.GetProjectsQueryParams{Pagination: model.Pagination{Page: 1, Size: 2}}, errCount: errCount, wantErr: errCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryProjectRepo()
+			repo.errCount = tt.errCount
+			for _, ID := range []int64{1, 2, 3} {
+				repo.projects[ID] = &model.Project{ID: ID}
+			}
+
+			projects, count, err := NewProjectUsecase(repo).FindAll(context.Background(), tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindAll() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			IDs := []int64{}
+			for _, project := range projects {
+				IDs = append(IDs, project.ID)
+			}
+			if !slices.Equal(IDs, tt.wantIDs) || count != tt.wantCount {
+				t.Errorf("FindAll() = %v, %d, want %v, %d", IDs, count, tt.wantIDs, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestProjectUsecaseTaskCounts(t *testing.T) {
+	repo := newMemoryProjectRepo()
+	pu := NewProjectUsecase(repo)
+	ctx := context.Background()
+
+	website, blog := int64(1), int64(2)
+	repo.projects[website] = &model.Project{ID: website}
+	repo.projects[blog] = &model.Project{ID: blog}
+	repo.tasks = map[int64]*model.Task{
+		10: {ID: 10, ProjectID: &website},
+		11: {ID: 11, ProjectID: &website, Completed: true},
+		12: {ID: 12, ProjectID: &blog},
+		13: {ID: 13},
+	}
+
+	project, err := pu.FindByID(ctx, website)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.TaskCount != 2 || project.OpenTaskCount != 1 {
+		t.Errorf("counts = %d total %d open, want 2 total 1 open", project.TaskCount, project.OpenTaskCount)
+	}
+
+	// deleting a project which still has tasks keeps the tasks without it
+	if err := pu.DeleteByID(ctx, website); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.tasks) != 4 || repo.tasks[10].ProjectID != nil || repo.tasks[11].ProjectID != nil {
+		t.Errorf("tasks of the deleted project = %+v, want them kept without a project", repo.tasks)
+	}
+	if project, err := pu.FindByID(ctx, blog); err != nil || project.TaskCount != 1 {
+		t.Errorf("FindByID(blog) = %+v, %v, want 1 task", project, err)
+	}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type reminderUsecase struct {
+	taskRepo  model.TaskRepository
+	notifiers []model.Notifier
+	batchSize int
+}
+
+func NewReminderUsecase(tr model.TaskRepository, batchSize int, notifiers ...model.Notifier) model.ReminderUsecase {
+	return &reminderUsecase{
+		taskRepo:  tr,
+		notifiers: notifiers,
+		batchSize: batchSize,
+	}
+}
+
+// DispatchDue sends reminder and due notifications for every task that
+// crossed its remind_at or due_at since the last run. Tasks are claimed
+// before sending, a notification which fails on every sink is released for
+// the next run. A worker stopped between claiming and sending loses the
+// notifications it claimed rather than sending them twice.
+func (ru *reminderUsecase) DispatchDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	dispatched := 0
+
+	for _, kind := range []model.NotificationKind{model.NotificationKindReminder, model.NotificationKindDue} {
+		logger := logrus.WithFields(logrus.Fields{
+			"ctx":  utils.Dump(ctx),
+			"kind": kind,
+		})
+
+		tasks, err := ru.taskRepo.ClaimDueNotifications(ctx, kind, now, ru.batchSize)
+		if err != nil {
+			logger.Error(err)
+			return dispatched, err
+		}
+
+		for _, task := range tasks {
+			err := ru.notify(ctx, &model.Notification{
+				Kind:    kind,
+				Task:    task,
+				FiredAt: now,
+			})
+			if err == nil {
+				dispatched++
+				continue
+			}
+
+			logger.WithField("task", utils.Dump(task)).Error(err)
+
+			// released even when ctx was cancelled while sending
+			if err := ru.taskRepo.ReleaseNotification(context.WithoutCancel(ctx), kind, task.ID); err != nil {
+				logger.WithField("task", utils.Dump(task)).Error(err)
+			}
+		}
+	}
+
+	return dispatched, nil
+}
+
+// notify fans the notification out to every sink, it is considered delivered
+// when at least one sink accepted it so that a healthy sink is not spammed
+// with retries because of a broken one
+func (ru *reminderUsecase) notify(ctx context.Context, notification *model.Notification) error {
+	var errs []error
+	for _, notifier := range ru.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			logrus.WithField("notification", utils.Dump(notification)).Error(err)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(ru.notifiers) > 0 && len(errs) == len(ru.notifiers) {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"todo-app/internal/model"
+)
+
+type claimingTaskRepo struct {
+	model.TaskRepository
+
+	due      map[model.NotificationKind][]*model.Task
+	released map[model.NotificationKind][]int64
+}
+
+func (r *claimingTaskRepo) ClaimDueNotifications(ctx context.Context, kind model.NotificationKind, now time.Time, limit int) ([]*model.Task, error) {
+	tasks := r.due[kind]
+	delete(r.due, kind)
+	return tasks, nil
+}
+
+func (r *claimingTaskRepo) ReleaseNotification(ctx context.Context, kind model.NotificationKind, ID int64) error {
+	r.released[kind] = append(r.released[kind], ID)
+	return nil
+}
+
+type fakeNotifier struct {
+	failing map[int64]bool
+	sent    []int64
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notification *model.Notification) error {
+	if n.failing[notification.Task.ID] {
+		return errors.New("sink is down")
+	}
+	n.sent = append(n.sent, notification.Task.ID)
+	return nil
+}
+
+func TestReminderUsecaseDispatchDue(t *testing.T) {
+	tests := []struct {
+		name           string
+		due            map[model.NotificationKind][]*model.Task
+		failing        map[int64]bool
+		wantDispatched int
+		wantReleased   map[model.NotificationKind][]int64
+	}{
+		{
+			name:           "nothing due",
+			due:            map[model.NotificationKind][]*model.Task{},
+			wantDispatched: 0,
+			wantReleased:   map[model.NotificationKind][]int64{},
+		},
+		{
+			name: "every notification is sent",
+			due: map[model.NotificationKind][]*model.Task{
+				model.NotificationKindReminder: {{ID: 1}, {ID: 2}},
+				model.NotificationKindDue:      {{ID: 3}},
+			},
+			wantDispatched: 3,
+			wantReleased:   map[model.NotificationKind][]int64{},
+		},
+		{
+			name: "failed notifications are released for the next run",
+			due: map[model.NotificationKind][]*model.Task{
+				model.NotificationKindReminder: {{ID: 1}, {ID: 2}},
+				model.NotificationKindDue:      {{ID: 2}},
+			},
+			failing:        map[int64]bool{2: true},
+			wantDispatched: 1,
+			wantReleased: map[model.NotificationKind][]int64{
+				model.NotificationKindReminder: {2},
+				model.NotificationKindDue:      {2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &claimingTaskRepo{due: tt.due, released: map[model.NotificationKind][]int64{}}
+			notifier := &fakeNotifier{failing: tt.failing}
+
+			dispatched, err := NewReminderUsecase(repo, 10, notifier).DispatchDue(context.Background())
+			if err != nil {
+				t.Fatalf("DispatchDue() error = %v", err)
+			}
+			if dispatched != tt.wantDispatched {
+				t.Errorf("DispatchDue() = %d, want %d", dispatched, tt.wantDispatched)
+			}
+
+			for kind, want := range tt.wantReleased {
+				if !slices.Equal(repo.released[kind], want) {
+					t.Errorf("released %s = %v, want %v", kind, repo.released[kind], want)
+				}
+			}
+			for kind, got := range repo.released {
+				if _, ok := tt.wantReleased[kind]; !ok {
+					t.Errorf("released %s = %v, want none", kind, got)
+				}
+			}
+		})
+	}
+}
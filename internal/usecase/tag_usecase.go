@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type tagUsecase struct {
+	tagRepo  model.TagRepository
+	taskRepo model.TaskRepository
+}
+
+func NewTagUsecase(tr model.TagRepository, taskRepo model.TaskRepository) model.TagUsecase {
+	return &tagUsecase{tagRepo: tr, taskRepo: taskRepo}
+}
+
+func (tu *tagUsecase) Create(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"tag": utils.Dump(tag),
+	})
+
+	if err := tu.ensureNameAvailable(ctx, tag); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if err := tu.tagRepo.Create(ctx, tag); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// DeleteByID deletes the tag and invalidates the cached tasks which embed it
+func (tu *tagUsecase) DeleteByID(ctx context.Context, ID int64) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
+
+	taskIDs, err := tu.tagRepo.FindTaskIDsByTagID(ctx, ID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if err := tu.tagRepo.DeleteByID(ctx, ID); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if err := tu.taskRepo.InvalidateCache(ctx, taskIDs...); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (tu *tagUsecase) FindByID(ctx context.Context, ID int64) (*model.Tag, error) {
+	tag, err := tu.tagRepo.FindByID(ctx, ID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
+		}).Error(err)
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (tu *tagUsecase) FindAll(ctx context.Context, params model.GetTagsQueryParams) ([]*model.Tag, int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"params": utils.Dump(params),
+	})
+
+	tags, err := tu.tagRepo.FindAll(ctx, params)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	count, err := tu.tagRepo.CountAll(ctx)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	return tags, count, nil
+}
+
+// Update renames the tag and invalidates the cached tasks which embed it
+func (tu *tagUsecase) Update(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"tag": utils.Dump(tag),
+	})
+
+	if err := tu.ensureNameAvailable(ctx, tag); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	updated, err := tu.tagRepo.Update(ctx, tag)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	taskIDs, err := tu.tagRepo.FindTaskIDsByTagID(ctx, tag.ID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if err := tu.taskRepo.InvalidateCache(ctx, taskIDs...); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (tu *tagUsecase) ensureNameAvailable(ctx context.Context, tag *model.Tag) error {
+	existing, err := tu.tagRepo.FindByName(ctx, tag.Name)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != tag.ID {
+		return model.ErrTagNameTaken
+	}
+
+	return nil
+}
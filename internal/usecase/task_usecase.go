@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -10,15 +13,25 @@ import (
 )
 
 type taskUsecase struct {
-	taskRepo model.TaskRepository
+	taskRepo     model.TaskRepository
+	scoreWeights model.TaskScoreWeights
+	workflow     model.TaskWorkflow
 }
 
-func NewTaskUsecase(tr model.TaskRepository) model.TaskUsecase {
-	return &taskUsecase{taskRepo: tr}
+func NewTaskUsecase(tr model.TaskRepository, weights model.TaskScoreWeights, workflow model.TaskWorkflow) model.TaskUsecase {
+	return &taskUsecase{taskRepo: tr, scoreWeights: weights, workflow: workflow}
 }
 
 func (tu *taskUsecase) Create(ctx context.Context, task *model.Task) (*model.Task, error) {
 
+	if err := tu.initStatus(task); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":  utils.Dump(ctx),
+			"task": utils.Dump(task),
+		}).Error(err)
+		return nil, err
+	}
+
 	if err := tu.taskRepo.Create(ctx, task); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"ctx":  utils.Dump(ctx),
@@ -30,11 +43,12 @@ func (tu *taskUsecase) Create(ctx context.Context, task *model.Task) (*model.Tas
 	return task, nil
 }
 
-func (tu *taskUsecase) DeleteByID(ctx context.Context, ID int64) error {
-	if err := tu.taskRepo.DeleteByID(ctx, ID); err != nil {
+func (tu *taskUsecase) DeleteByID(ctx context.Context, ID int64, version int64) error {
+	if err := tu.taskRepo.DeleteByID(ctx, ID, version); err != nil {
 		logrus.WithFields(logrus.Fields{
-			"ctx": utils.Dump(ctx),
-			"ID":  ID,
+			"ctx":     utils.Dump(ctx),
+			"ID":      ID,
+			"version": version,
 		}).Error(err)
 		return err
 	}
@@ -68,7 +82,7 @@ func (tu *taskUsecase) FindAll(ctx context.Context, params model.GetTasksQueryPa
 		return nil, int64(0), err
 	}
 
-	count, err := tu.taskRepo.CountAll(ctx)
+	count, err := tu.taskRepo.CountAll(ctx, params)
 	if err != nil {
 		logger.Error(err)
 		return nil, int64(0), err
@@ -77,15 +91,683 @@ func (tu *taskUsecase) FindAll(ctx context.Context, params model.GetTasksQueryPa
 	return tasks, count, nil
 }
 
+func (tu *taskUsecase) FindAllByCursor(ctx context.Context, params model.GetTasksQueryParams) ([]*model.Task, string, string, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"params": utils.Dump(params),
+	})
+
+	cursor, err := params.DecodeCursor()
+	if err != nil {
+		logger.Error(err)
+		return nil, "", "", err
+	}
+
+	tasks, err := tu.taskRepo.FindAllByCursor(ctx, params)
+	if err != nil {
+		logger.Error(err)
+		return nil, "", "", err
+	}
+
+	hasMore := int64(len(tasks)) > params.Size
+	if hasMore {
+		tasks = tasks[:params.Size]
+	}
+
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	if len(tasks) == 0 {
+		return tasks, "", "", nil
+	}
+
+	nextCursor, prevCursor := "", ""
+	// going backward there is always a next page, the one we came from
+	if hasMore || backward {
+		nextCursor = params.CursorOf(tasks[len(tasks)-1], false)
+	}
+	// going forward there is a previous page unless this is the first one
+	if (hasMore && backward) || (cursor != nil && !backward) {
+		prevCursor = params.CursorOf(tasks[0], true)
+	}
+
+	return tasks, nextCursor, prevCursor, nil
+}
+
 func (tu *taskUsecase) Update(ctx context.Context, task *model.Task) (*model.Task, error) {
-	task, err := tu.taskRepo.Update(ctx, task)
+	return tu.write(ctx, task.ID, task.Version, func(existing *model.Task) (*model.Task, error) {
+		replacement := *task
+		if err := tu.prepareUpdate(existing, &replacement); err != nil {
+			return nil, err
+		}
+		return &replacement, nil
+	})
+}
+
+// maxUnguardedWrites bounds the attempts of a write without an expected
+// version, each one reads the task afresh
+const maxUnguardedWrites = 3
+
+// write reads the task, lets replace derive the replacement from it and
+// writes the replacement while the task is still at the version read. A
+// write at the version the client expected fails once another write won.
+// Without an expected version the client asked for no guard, so a write
+// which lost, or was derived from a stale cached read, drops the cached
+// task and tries again on a fresh read.
+func (tu *taskUsecase) write(ctx context.Context, ID, version int64, replace func(existing *model.Task) (*model.Task, error)) (*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":     utils.Dump(ctx),
+		"ID":      ID,
+		"version": version,
+	})
+
+	for attempt := 1; ; attempt++ {
+		existing, err := tu.taskRepo.FindByID(ctx, ID)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		task, err := replace(existing)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		task, err = tu.taskRepo.Update(ctx, task)
+		if err == nil {
+			return task, nil
+		}
+		if version != 0 || !errors.Is(err, model.ErrTaskVersionMismatch) || attempt == maxUnguardedWrites {
+			logger.Error(err)
+			return nil, err
+		}
+
+		if err := tu.taskRepo.InvalidateCache(ctx, ID); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+	}
+}
+
+// prepareUpdate checks the expected version and moves the status of task,
+// the replacement of existing, along the workflow
+func (tu *taskUsecase) prepareUpdate(existing, task *model.Task) error {
+	if task.Version != 0 && task.Version != existing.Version {
+		return model.ErrTaskVersionMismatch
+	}
+	// the status is derived from existing, so the write must not outlive it
+	// even when the client expects no version
+	task.Version = existing.Version
+
+	// without a status completed decides, reopening a done task or keeping
+	// any other status of an open task
+	status := task.Status
+	switch {
+	case status != "":
+	case task.Completed:
+		status = model.TaskStatusDone
+	case existing.Status == model.TaskStatusDone:
+		status = model.TaskStatusTodo
+	default:
+		status = existing.Status
+	}
+
+	if err := tu.workflow.Transition(existing.Status, status); err != nil {
+		return err
+	}
+
+	task.StartedAt = existing.StartedAt
+	task.CompletedAt = existing.CompletedAt
+	task.SetStatus(status, time.Now())
+	return nil
+}
+
+func (tu *taskUsecase) Patch(ctx context.Context, ID int64, patch model.TaskPatch) (*model.Task, error) {
+	return tu.write(ctx, ID, patch.Version, func(existing *model.Task) (*model.Task, error) {
+		if patch.Version != 0 && patch.Version != existing.Version {
+			return nil, model.ErrTaskVersionMismatch
+		}
+
+		input, err := model.NewUpdateTaskInput(existing).Apply(patch)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := input.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", model.ErrInvalidPatch, err)
+		}
+
+		task := input.ToModel(ID)
+		task.Version = patch.Version
+		if err := tu.prepareUpdate(existing, task); err != nil {
+			return nil, err
+		}
+		return task, nil
+	})
+}
+
+// initStatus sets the status of a new task, defaulting to done for tasks
+// created completed by older clients and to todo otherwise
+func (tu *taskUsecase) initStatus(task *model.Task) error {
+	status := task.Status
+	switch {
+	case status != "":
+	case task.Completed:
+		status = model.TaskStatusDone
+	default:
+		status = model.TaskStatusTodo
+	}
+
+	if !tu.workflow.Has(status) {
+		return fmt.Errorf("%w: %q", model.ErrUnknownTaskStatus, status)
+	}
+
+	task.SetStatus(status, time.Now())
+	return nil
+}
+
+func (tu *taskUsecase) FindAllTrashed(ctx context.Context, params model.GetTrashedTasksQueryParams) ([]*model.Task, int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"params": utils.Dump(params),
+	})
+
+	tasks, err := tu.taskRepo.FindAllTrashed(ctx, params)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	count, err := tu.taskRepo.CountAllTrashed(ctx)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	return tasks, count, nil
+}
+
+func (tu *taskUsecase) RestoreByID(ctx context.Context, ID int64) (*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ID":  ID,
+	})
+
+	if err := tu.taskRepo.RestoreByID(ctx, ID); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	task, err := tu.taskRepo.FindByID(ctx, ID)
 	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (tu *taskUsecase) PurgeByID(ctx context.Context, ID int64) error {
+	if err := tu.taskRepo.PurgeByID(ctx, ID); err != nil {
 		logrus.WithFields(logrus.Fields{
-			"ctx":  utils.Dump(ctx),
-			"task": utils.Dump(task),
+			"ctx": utils.Dump(ctx),
+			"ID":  ID,
 		}).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (tu *taskUsecase) FindOverdue(ctx context.Context, params model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	return tu.findAllDue(ctx, model.DueTasksQuery{
+		To:   time.Now(),
+		Page: params.Page,
+		Size: params.Size,
+	})
+}
+
+func (tu *taskUsecase) FindDueWithin(ctx context.Context, within time.Duration, params model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	now := time.Now()
+	return tu.findAllDue(ctx, model.DueTasksQuery{
+		From: &now,
+		To:   now.Add(within),
+		Page: params.Page,
+		Size: params.Size,
+	})
+}
+
+func (tu *taskUsecase) FindNext(ctx context.Context, params model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	query := model.NextTasksQuery{
+		Now:     time.Now(),
+		Weights: tu.scoreWeights,
+		Page:    params.Page,
+		Size:    params.Size,
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tasks, err := tu.taskRepo.FindAllNext(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	count, err := tu.taskRepo.CountAllNext(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	return tasks, count, nil
+}
+
+func (tu *taskUsecase) findAllDue(ctx context.Context, query model.DueTasksQuery) ([]*model.Task, int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tasks, err := tu.taskRepo.FindAllDue(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	count, err := tu.taskRepo.CountAllDue(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	return tasks, count, nil
+}
+
+func (tu *taskUsecase) FindSeriesByTaskID(ctx context.Context, taskID int64) (*model.TaskSeries, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"taskID": taskID,
+	})
+
+	task, err := tu.taskRepo.FindByID(ctx, taskID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if task.SeriesID == nil {
+		return nil, model.ErrTaskNotRecurring
+	}
+
+	series, err := tu.taskRepo.FindSeriesByID(ctx, *task.SeriesID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// UpdateSeries replaces the rule of the task's series and resumes it, a task
+// which is not recurring yet becomes the first occurrence of a new series
+func (tu *taskUsecase) UpdateSeries(ctx context.Context, taskID int64, rule string) (*model.TaskSeries, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"taskID": taskID,
+		"rule":   rule,
+	})
+
+	parsed, err := model.ParseRecurrenceRule(rule)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	series, err := tu.FindSeriesByTaskID(ctx, taskID)
+	switch {
+	case errors.Is(err, model.ErrTaskNotRecurring):
+		series = &model.TaskSeries{
+			ID:        utils.GenerateID(),
+			CreatedAt: time.Now(),
+		}
+	case err != nil:
+		logger.Error(err)
+		return nil, err
+	}
+
+	series.Rule = parsed.String()
+	series.StoppedAt = nil
+	series.UpdatedAt = time.Now()
+
+	if err := tu.taskRepo.SaveSeries(ctx, taskID, series); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// StopSeries keeps the existing occurrences but no longer creates new ones
+func (tu *taskUsecase) StopSeries(ctx context.Context, taskID int64) (*model.TaskSeries, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"taskID": taskID,
+	})
+
+	series, err := tu.FindSeriesByTaskID(ctx, taskID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	series.StoppedAt = &now
+	series.UpdatedAt = now
+
+	if err := tu.taskRepo.SaveSeries(ctx, taskID, series); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (tu *taskUsecase) CreateSubtask(ctx context.Context, parentID int64, task *model.Task) (*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":      utils.Dump(ctx),
+		"parentID": parentID,
+		"task":     utils.Dump(task),
+	})
+
+	if _, err := tu.taskRepo.FindByID(ctx, parentID); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if err := tu.initStatus(task); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	task.ParentID = &parentID
+	if err := tu.taskRepo.Create(ctx, task); err != nil {
+		logger.Error(err)
 		return nil, err
 	}
 
 	return task, nil
 }
+
+func (tu *taskUsecase) FindSubtasks(ctx context.Context, parentID int64, params model.GetTasksQueryParams) ([]*model.Task, int64, error) {
+	params.ParentID = &parentID
+	return tu.FindAll(ctx, params)
+}
+
+func (tu *taskUsecase) FindHistory(ctx context.Context, ID int64, params model.GetTaskHistoryQueryParams) ([]*model.TaskEvent, int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"ID":     ID,
+		"params": utils.Dump(params),
+	})
+
+	count, err := tu.taskRepo.CountEventsByTaskID(ctx, ID)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	// tasks created before the history was recorded have no events, which
+	// must not be mistaken for a missing task
+	if count == 0 {
+		if _, err := tu.taskRepo.FindByID(ctx, ID); err != nil {
+			logger.Error(err)
+			return nil, int64(0), err
+		}
+		return []*model.TaskEvent{}, int64(0), nil
+	}
+
+	events, err := tu.taskRepo.FindEventsByTaskID(ctx, ID, params)
+	if err != nil {
+		logger.Error(err)
+		return nil, int64(0), err
+	}
+
+	return events, count, nil
+}
+
+func (tu *taskUsecase) CompleteByID(ctx context.Context, ID int64, cascade bool) (*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":     utils.Dump(ctx),
+		"ID":      ID,
+		"cascade": cascade,
+	})
+
+	existing, err := tu.taskRepo.FindByID(ctx, ID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	// descendants are completed whatever their status, only the task itself
+	// has to follow the workflow
+	if err := tu.workflow.Transition(existing.Status, model.TaskStatusDone); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if err := tu.taskRepo.CompleteByID(ctx, ID, cascade); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	task, err := tu.taskRepo.FindByID(ctx, ID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (tu *taskUsecase) FindBoard(ctx context.Context, query model.GetBoardQueryParams) (*model.Board, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"query": utils.Dump(query),
+	})
+
+	tasks, err := tu.taskRepo.FindAllByStatus(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	counts, err := tu.taskRepo.CountAllByStatus(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	board := &model.Board{
+		ProjectID: query.ProjectID,
+		Columns:   map[model.TaskStatus]*model.BoardColumn{},
+	}
+	for status := range tu.workflow {
+		board.Columns[status] = &model.BoardColumn{
+			Status: status,
+			Count:  counts[status],
+			Tasks:  []*model.Task{},
+		}
+	}
+
+	for _, task := range tasks {
+		// tasks left in a status removed from the workflow still get a column
+		column, ok := board.Columns[task.Status]
+		if !ok {
+			column = &model.BoardColumn{Status: task.Status, Count: counts[task.Status]}
+			board.Columns[task.Status] = column
+		}
+		column.Tasks = append(column.Tasks, task)
+	}
+
+	return board, nil
+}
+
+func (tu *taskUsecase) Move(ctx context.Context, move model.TaskMove) (*model.Task, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":  utils.Dump(ctx),
+		"move": utils.Dump(move),
+	})
+
+	existing, err := tu.taskRepo.FindByID(ctx, move.ID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	move.FromStatus = existing.Status
+	if move.Status == existing.Status {
+		move.Status = ""
+	}
+
+	if move.Status != "" {
+		if err := tu.workflow.Transition(existing.Status, move.Status); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		moved := *existing
+		moved.SetStatus(move.Status, time.Now())
+		move.StartedAt = moved.StartedAt
+		move.CompletedAt = moved.CompletedAt
+	}
+
+	if err := tu.taskRepo.MoveByID(ctx, move); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	task, err := tu.taskRepo.FindByID(ctx, move.ID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (tu *taskUsecase) Bulk(ctx context.Context, operations []*model.TaskOperation, atomic bool) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":        utils.Dump(ctx),
+		"operations": utils.Dump(operations),
+		"atomic":     atomic,
+	})
+
+	existing, err := tu.findExisting(ctx, operations)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	for _, operation := range operations {
+		if operation.Err != nil {
+			continue
+		}
+		operation.Err = tu.prepareOperation(existing, operation)
+	}
+
+	if err := firstOperationErr(operations); atomic && err != nil {
+		logger.Error(err)
+		markNotApplied(operations)
+		return err
+	}
+
+	if err := tu.taskRepo.ApplyBatch(ctx, operations, atomic); err != nil {
+		logger.Error(err)
+		markNotApplied(operations)
+		return err
+	}
+
+	written, err := tu.findExisting(ctx, operations)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	for _, operation := range operations {
+		if operation.Err != nil || operation.Kind == model.TaskOperationDelete {
+			continue
+		}
+
+		operation.Task = written[operation.ID]
+	}
+
+	return nil
+}
+
+// findExisting loads the tasks the operations refer to, keyed by ID
+func (tu *taskUsecase) findExisting(ctx context.Context, operations []*model.TaskOperation) (map[int64]*model.Task, error) {
+	IDs := []int64{}
+	for _, operation := range operations {
+		if operation.Err == nil {
+			IDs = append(IDs, operation.ID)
+		}
+	}
+
+	tasks, err := tu.taskRepo.FindAllByIDs(ctx, IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[int64]*model.Task{}
+	for _, task := range tasks {
+		existing[task.ID] = task
+	}
+	return existing, nil
+}
+
+// prepareOperation applies the checks of the single task endpoints to an
+// operation before it is written
+func (tu *taskUsecase) prepareOperation(existing map[int64]*model.Task, operation *model.TaskOperation) error {
+	if operation.Kind == model.TaskOperationCreate {
+		return tu.initStatus(operation.Task)
+	}
+
+	task, ok := existing[operation.ID]
+	if !ok {
+		return fmt.Errorf("%w: %d", model.ErrTaskNotFound, operation.ID)
+	}
+
+	switch operation.Kind {
+	case model.TaskOperationUpdate:
+		return tu.prepareUpdate(task, operation.Task)
+	case model.TaskOperationComplete:
+		return tu.workflow.Transition(task.Status, model.TaskStatusDone)
+	}
+	return nil
+}
+
+func firstOperationErr(operations []*model.TaskOperation) error {
+	for _, operation := range operations {
+		if operation.Err != nil {
+			return operation.Err
+		}
+	}
+	return nil
+}
+
+// markNotApplied marks the operations of a batch which was rolled back
+func markNotApplied(operations []*model.TaskOperation) {
+	for _, operation := range operations {
+		if operation.Err == nil {
+			operation.Err = model.ErrOperationNotApplied
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo-app/internal/errs"
+	"todo-app/internal/model"
+)
+
+// historyTaskRepo holds task 1 with events, and task 2 created before the
+// history was recorded
+type historyTaskRepo struct {
+	model.TaskRepository
+
+	events []*model.TaskEvent
+}
+
+func (r *historyTaskRepo) FindByID(ctx context.Context, ID int64) (*model.Task, error) {
+	if ID != 1 && ID != 2 {
+		return nil, model.ErrTaskNotFound
+	}
+	return &model.Task{ID: ID}, nil
+}
+
+func (r *historyTaskRepo) CountEventsByTaskID(ctx context.Context, taskID int64) (int64, error) {
+	if taskID != 1 {
+		return 0, nil
+	}
+	return int64(len(r.events)), nil
+}
+
+func (r *historyTaskRepo) FindEventsByTaskID(ctx context.Context, taskID int64, query model.GetTaskHistoryQueryParams) ([]*model.TaskEvent, error) {
+	return r.events, nil
+}
+
+func TestTaskUsecaseFindHistory(t *testing.T) {
+	events := []*model.TaskEvent{
+		{ID: 11, TaskID: 1, Kind: model.TaskEventUpdated},
+		{ID: 10, TaskID: 1, Kind: model.TaskEventCreated},
+	}
+
+	tests := []struct {
+		name       string
+		ID         int64
+		wantEvents int
+		wantCount  int64
+		wantErr    error
+	}{
+		{name: "with events", ID: 1, wantEvents: 2, wantCount: 2},
+		{name: "created before the history", ID: 2},
+		{name: "missing task", ID: 3, wantErr: model.ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := NewTaskUsecase(&historyTaskRepo{events: events}, model.TaskScoreWeights{}, nil)
+
+			got, count, err := tu.FindHistory(context.Background(), tt.ID, model.GetTaskHistoryQueryParams{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindHistory() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got == nil || len(got) != tt.wantEvents {
+				t.Errorf("FindHistory() = %v, want %d events", got, tt.wantEvents)
+			}
+			if count != tt.wantCount {
+				t.Errorf("FindHistory() count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+// racingTaskRepo holds task 1 at version 3, cached at version 2 while
+// stale, and lets another write win the next races writes
+type racingTaskRepo struct {
+	model.TaskRepository
+
+	task   model.Task
+	stale  bool
+	races  int
+	writes int
+}
+
+func newRacingTaskRepo(stale bool, races int) *racingTaskRepo {
+	return &racingTaskRepo{
+		task:  model.Task{ID: 1, Title: "write report", Status: model.TaskStatusTodo, Version: 3},
+		stale: stale,
+		races: races,
+	}
+}
+
+func (r *racingTaskRepo) FindByID(ctx context.Context, ID int64) (*model.Task, error) {
+	task := r.task
+	if r.stale {
+		task.Version--
+	}
+	return &task, nil
+}
+
+func (r *racingTaskRepo) Update(ctx context.Context, task *model.Task) (*model.Task, error) {
+	if r.races > 0 {
+		r.races--
+		r.task.Version++
+	}
+	if task.Version != r.task.Version {
+		return nil, model.ErrTaskVersionMismatch
+	}
+
+	r.writes++
+	r.task = *task
+	r.task.Version++
+	return &r.task, nil
+}
+
+func (r *racingTaskRepo) InvalidateCache(ctx context.Context, IDs ...int64) error {
+	r.stale = false
+	return nil
+}
+
+func TestTaskUsecaseWriteVersions(t *testing.T) {
+	workflow := model.NewTaskWorkflow(map[string][]string{"todo": {"done"}, "done": {"todo"}})
+	put := func(version int64) func(tu model.TaskUsecase) (*model.Task, error) {
+		return func(tu model.TaskUsecase) (*model.Task, error) {
+			return tu.Update(context.Background(), &model.Task{ID: 1, Title: "write the report", Version: version})
+		}
+	}
+	patch := func(version int64) func(tu model.TaskUsecase) (*model.Task, error) {
+		return func(tu model.TaskUsecase) (*model.Task, error) {
+			return tu.Patch(context.Background(), 1, model.TaskPatch{
+				ContentType: model.MergePatchContentType,
+				Body:        []byte(`{"title":"write the report"}`),
+				Version:     version,
+			})
+		}
+	}
+
+	tests := []struct {
+		name    string
+		write   func(tu model.TaskUsecase) (*model.Task, error)
+		stale   bool
+		races   int
+		wantErr error
+	}{
+		{name: "PUT without If-Match over a stale cache", write: put(0), stale: true},
+		{name: "PUT without If-Match losing races", write: put(0), races: maxUnguardedWrites - 1},
+		{name: "PUT without If-Match losing every race", write: put(0), races: maxUnguardedWrites, wantErr: model.ErrTaskVersionMismatch},
+		{name: "PUT with the current If-Match", write: put(3)},
+		{name: "PUT with the current If-Match losing a race", write: put(3), races: 1, wantErr: model.ErrTaskVersionMismatch},
+		{name: "PUT with an old If-Match", write: put(1), wantErr: model.ErrTaskVersionMismatch},
+		{name: "PATCH without If-Match over a stale cache", write: patch(0), stale: true},
+		{name: "PATCH without If-Match losing races", write: patch(0), races: maxUnguardedWrites - 1},
+		{name: "PATCH with the current If-Match", write: patch(3)},
+		{name: "PATCH with an old If-Match", write: patch(1), wantErr: model.ErrTaskVersionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRacingTaskRepo(tt.stale, tt.races)
+			tu := NewTaskUsecase(repo, model.TaskScoreWeights{}, workflow)
+
+			task, err := tt.write(tu)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("write error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.writes != 0 {
+					t.Errorf("%d writes, want none", repo.writes)
+				}
+				return
+			}
+			if repo.writes != 1 || task.Title != "write the report" {
+				t.Errorf("task = %+v after %d writes, want the new title written once", task, repo.writes)
+			}
+		})
+	}
+}
+
+func TestTaskUsecaseUpdateStatus(t *testing.T) {
+	workflow := model.NewTaskWorkflow(map[string][]string{
+		"todo":        {"in_progress", "done"},
+		"in_progress": {"todo", "done"},
+		"done":        {"todo"},
+	})
+	startedAt := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		existing        model.Task
+		input           model.Task
+		wantStatus      model.TaskStatus
+		wantStartedAt   bool
+		wantCompletedAt bool
+		wantKind        errs.Kind
+		wantErr         error
+	}{
+		{name: "start", existing: model.Task{Status: model.TaskStatusTodo}, input: model.Task{Status: model.TaskStatusInProgress}, wantStatus: model.TaskStatusInProgress, wantStartedAt: true},
+		{name: "complete", existing: model.Task{Status: model.TaskStatusInProgress, StartedAt: &startedAt}, input: model.Task{Status: model.TaskStatusDone}, wantStatus: model.TaskStatusDone, wantStartedAt: true, wantCompletedAt: true},
+		{name: "complete by the completed flag", existing: model.Task{Status: model.TaskStatusTodo}, input: model.Task{Completed: true}, wantStatus: model.TaskStatusDone, wantCompletedAt: true},
+		{name: "keep the status", existing: model.Task{Status: model.TaskStatusInProgress, StartedAt: &startedAt}, input: model.Task{}, wantStatus: model.TaskStatusInProgress, wantStartedAt: true},
+		{name: "reopen by the completed flag", existing: model.Task{Status: model.TaskStatusDone, Completed: true, CompletedAt: &startedAt}, input: model.Task{}, wantStatus: model.TaskStatusTodo},
+		{name: "forbidden transition", existing: model.Task{Status: model.TaskStatusDone, Completed: true}, input: model.Task{Status: model.TaskStatusInProgress}, wantKind: errs.KindConflict, wantErr: model.ErrTaskStatusTransition},
+		{name: "unknown status", existing: model.Task{Status: model.TaskStatusTodo}, input: model.Task{Status: model.TaskStatusBlocked}, wantKind: errs.KindValidation, wantErr: model.ErrUnknownTaskStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRacingTaskRepo(false, 0)
+			existing := tt.existing
+			existing.ID, existing.Title, existing.Version = 1, "write report", 3
+			repo.task = existing
+
+			input := tt.input
+			input.ID, input.Title = 1, "write report"
+			task, err := NewTaskUsecase(repo, model.TaskScoreWeights{}, workflow).Update(context.Background(), &input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if kind := errs.From(err).Kind; kind != tt.wantKind {
+					t.Errorf("Update() error kind = %s, want %s", kind, tt.wantKind)
+				}
+				if repo.writes != 0 {
+					t.Errorf("%d writes, want none", repo.writes)
+				}
+				return
+			}
+
+			if task.Status != tt.wantStatus || task.Completed != (tt.wantStatus == model.TaskStatusDone) {
+				t.Errorf("status = %q completed %t, want %q", task.Status, task.Completed, tt.wantStatus)
+			}
+			if (task.StartedAt != nil) != tt.wantStartedAt {
+				t.Errorf("started_at = %v, want set %t", task.StartedAt, tt.wantStartedAt)
+			}
+			if (task.CompletedAt != nil) != tt.wantCompletedAt {
+				t.Errorf("completed_at = %v, want set %t", task.CompletedAt, tt.wantCompletedAt)
+			}
+		})
+	}
+}
+
+// dueTaskRepo records the query of the last due listing
+type dueTaskRepo struct {
+	model.TaskRepository
+
+	query model.DueTasksQuery
+}
+
+func (r *dueTaskRepo) FindAllDue(ctx context.Context, query model.DueTasksQuery) ([]*model.Task, error) {
+	r.query = query
+	return []*model.Task{}, nil
+}
+
+func (r *dueTaskRepo) CountAllDue(ctx context.Context, query model.DueTasksQuery) (int64, error) {
+	return 0, nil
+}
+
+func TestTaskUsecaseDueWindows(t *testing.T) {
+	page := model.GetTasksQueryParams{Pagination: model.Pagination{Page: 2, Size: 5}}
+
+	tests := []struct {
+		name     string
+		find     func(tu model.TaskUsecase) error
+		wantFrom bool
+		within   time.Duration
+	}{
+		{
+			name: "overdue is open ended",
+			find: func(tu model.TaskUsecase) error {
+				_, _, err := tu.FindOverdue(context.Background(), page)
+				return err
+			},
+		},
+		{
+			name: "due within starts now",
+			find: func(tu model.TaskUsecase) error {
+				_, _, err := tu.FindDueWithin(context.Background(), 2*time.Hour, page)
+				return err
+			},
+			wantFrom: true,
+			within:   2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &dueTaskRepo{}
+			before := time.Now()
+			if err := tt.find(NewTaskUsecase(repo, model.TaskScoreWeights{}, nil)); err != nil {
+				t.Fatal(err)
+			}
+			after := time.Now()
+
+			query := repo.query
+			if (query.From != nil) != tt.wantFrom {
+				t.Fatalf("from = %v, want set %t", query.From, tt.wantFrom)
+			}
+			if query.To.Before(before.Add(tt.within)) || query.To.After(after.Add(tt.within)) {
+				t.Errorf("to = %s, want now plus %s", query.To, tt.within)
+			}
+			if query.From != nil && !query.To.Equal(query.From.Add(tt.within)) {
+				t.Errorf("window = %s to %s, want %s long", query.From, query.To, tt.within)
+			}
+			if query.Page != 2 || query.Size != 5 {
+				t.Errorf("page = %d size %d, want page 2 size 5", query.Page, query.Size)
+			}
+		})
+	}
+}
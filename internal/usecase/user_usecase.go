@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type userUsecase struct {
+	userRepo model.UserRepository
+	keys     model.TokenKeys
+	tokenTTL time.Duration
+
+	// dummyHash is compared against on unknown emails, so that they take
+	// as long to reject as wrong passwords
+	dummyHash []byte
+}
+
+// NewUserUsecase issues HS256 tokens signed with keys.HMACSecret, which
+// the auth usecase accepts with the same keys
+func NewUserUsecase(ur model.UserRepository, keys model.TokenKeys, tokenTTL time.Duration) model.UserUsecase {
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	if err != nil {
+		logrus.Error(err)
+	}
+
+	return &userUsecase{
+		userRepo:  ur,
+		keys:      keys,
+		tokenTTL:  tokenTTL,
+		dummyHash: dummyHash,
+	}
+}
+
+func (uu *userUsecase) Register(ctx context.Context, input model.RegisterInput) (*model.User, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"email": input.Email,
+	})
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	user := input.ToModel()
+	user.PasswordHash = string(passwordHash)
+
+	if err := uu.userRepo.Create(ctx, user); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (uu *userUsecase) Login(ctx context.Context, input model.LoginInput) (*model.AccessToken, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":   utils.Dump(ctx),
+		"email": input.Email,
+	})
+
+	user, err := uu.userRepo.FindByEmail(ctx, model.NormalizeEmail(input.Email))
+	switch {
+	case errors.Is(err, model.ErrUserNotFound):
+		_ = bcrypt.CompareHashAndPassword(uu.dummyHash, []byte(input.Password))
+		return nil, model.ErrInvalidCredentials
+	case err != nil:
+		logger.Error(err)
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		return nil, model.ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Issuer:    uu.keys.Issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(uu.tokenTTL)),
+	}
+	if uu.keys.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{uu.keys.Audience}
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(uu.keys.HMACSecret)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return &model.AccessToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(uu.tokenTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"todo-app/internal/model"
+)
+
+// memoryUserRepo keys the users by email like the unique index does
+type memoryUserRepo struct {
+	model.UserRepository
+
+	users map[string]*model.User
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *model.User) error {
+	if _, ok := r.users[user.Email]; ok {
+		return model.ErrUserEmailTaken
+	}
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *memoryUserRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	user, ok := r.users[email]
+	if !ok {
+		return nil, model.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func TestUserUsecaseRegisterAndLogin(t *testing.T) {
+	keys := model.TokenKeys{HMACSecret: []byte("test-secret"), Issuer: "todo-api", Audience: "todo-api"}
+	uu := NewUserUsecase(&memoryUserRepo{users: map[string]*model.User{}}, keys, time.Hour)
+	ctx := context.Background()
+
+	user, err := uu.Register(ctx, model.RegisterInput{Email: " Alice@Example.com ", Password: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "alice@example.com" {
+		t.Errorf("email = %q, want it normalized", user.Email)
+	}
+	if user.PasswordHash == "" || user.PasswordHash == "correct horse" {
+		t.Errorf("password hash = %q, want a bcrypt hash", user.PasswordHash)
+	}
+
+	_, err = uu.Register(ctx, model.RegisterInput{Email: "ALICE@example.com", Password: "another one"})
+	if !errors.Is(err, model.ErrUserEmailTaken) {
+		t.Errorf("Register() of a taken email error = %v, want %v", err, model.ErrUserEmailTaken)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "valid", email: "alice@example.com", password: "correct horse"},
+		{name: "email in another case", email: "ALICE@example.com ", password: "correct horse"},
+		{name: "wrong password", email: "alice@example.com", password: "wrong horse", wantErr: model.ErrInvalidCredentials},
+		{name: "unknown email", email: "bob@example.com", password: "correct horse", wantErr: model.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := uu.Login(ctx, model.LoginInput{Email: tt.email, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if token.TokenType != "Bearer" || token.ExpiresIn != 3600 {
+				t.Errorf("token = %+v, want a bearer token expiring in an hour", token)
+			}
+
+			// the issued token authenticates its user
+			principal, err := NewAuthUsecase(nil, keys).AuthenticateToken(ctx, token.AccessToken)
+			if err != nil {
+				t.Fatalf("AuthenticateToken() error = %v", err)
+			}
+			if principal.UserID != user.ID || principal.Subject != strconv.FormatInt(user.ID, 10) {
+				t.Errorf("principal = %+v, want user %d", principal, user.ID)
+			}
+		})
+	}
+}
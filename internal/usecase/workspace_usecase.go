@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+	"todo-app/internal/utils"
+)
+
+type workspaceUsecase struct {
+	workspaceRepo model.WorkspaceRepository
+	userRepo      model.UserRepository
+}
+
+func NewWorkspaceUsecase(wr model.WorkspaceRepository, ur model.UserRepository) model.WorkspaceUsecase {
+	return &workspaceUsecase{
+		workspaceRepo: wr,
+		userRepo:      ur,
+	}
+}
+
+// Create makes the caller the first owner of the workspace
+func (wu *workspaceUsecase) Create(ctx context.Context, workspace *model.Workspace) (*model.Workspace, error) {
+	userID, ok := model.UserIDFromContext(ctx)
+	if !ok {
+		return nil, model.ErrMissingCredentials
+	}
+
+	owner := &model.WorkspaceMember{
+		UserID:    userID,
+		Role:      model.WorkspaceRoleOwner,
+		CreatedAt: time.Now(),
+	}
+
+	if err := wu.workspaceRepo.Create(ctx, workspace, owner); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ctx":       utils.Dump(ctx),
+			"workspace": utils.Dump(workspace),
+		}).Error(err)
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// FindAll returns the workspaces the caller is a member of
+func (wu *workspaceUsecase) FindAll(ctx context.Context, params model.GetWorkspacesQueryParams) ([]*model.Workspace, int64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":    utils.Dump(ctx),
+		"params": utils.Dump(params),
+	})
+
+	userID, ok := model.UserIDFromContext(ctx)
+	if !ok {
+		return nil, 0, model.ErrMissingCredentials
+	}
+
+	workspaces, err := wu.workspaceRepo.FindAllByUserID(ctx, userID, params)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	count, err := wu.workspaceRepo.CountAllByUserID(ctx, userID)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return workspaces, count, nil
+}
+
+// Resolve finds the workspace by its ID or slug. Workspaces the caller is
+// not a member of are reported as missing, so that their existence does
+// not leak.
+func (wu *workspaceUsecase) Resolve(ctx context.Context, ref string) (*model.Workspace, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx": utils.Dump(ctx),
+		"ref": ref,
+	})
+
+	userID, ok := model.UserIDFromContext(ctx)
+	if !ok {
+		return nil, model.ErrMissingCredentials
+	}
+
+	var workspace *model.Workspace
+	var err error
+	if ID, parseErr := strconv.ParseInt(ref, 10, 64); parseErr == nil {
+		workspace, err = wu.workspaceRepo.FindByID(ctx, ID)
+	} else {
+		workspace, err = wu.workspaceRepo.FindBySlug(ctx, ref)
+	}
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	_, err = wu.workspaceRepo.FindMember(ctx, workspace.ID, userID)
+	switch {
+	case errors.Is(err, model.ErrWorkspaceMemberNotFound):
+		return nil, model.ErrWorkspaceNotFound
+	case err != nil:
+		logger.Error(err)
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// AddMember is reserved to the owners of the workspace
+func (wu *workspaceUsecase) AddMember(ctx context.Context, workspaceID int64, input model.AddWorkspaceMemberInput) (*model.WorkspaceMember, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":         utils.Dump(ctx),
+		"workspaceID": workspaceID,
+		"input":       utils.Dump(input),
+	})
+
+	caller, err := wu.callerMember(ctx, workspaceID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if caller.Role != model.WorkspaceRoleOwner {
+		return nil, model.ErrNotWorkspaceOwner
+	}
+
+	user, err := wu.userRepo.FindByEmail(ctx, model.NormalizeEmail(input.Email))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	role := input.Role
+	if role == "" {
+		role = model.WorkspaceRoleMember
+	}
+
+	member := &model.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      user.ID,
+		Role:        role,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := wu.workspaceRepo.AddMember(ctx, member); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// RemoveMember is reserved to the owners of the workspace, except for
+// members leaving it. The last owner cannot be removed.
+func (wu *workspaceUsecase) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"ctx":         utils.Dump(ctx),
+		"workspaceID": workspaceID,
+		"userID":      userID,
+	})
+
+	caller, err := wu.callerMember(ctx, workspaceID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if caller.Role != model.WorkspaceRoleOwner && caller.UserID != userID {
+		return model.ErrNotWorkspaceOwner
+	}
+
+	member, err := wu.workspaceRepo.FindMember(ctx, workspaceID, userID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if member.Role == model.WorkspaceRoleOwner {
+		owners, err := wu.workspaceRepo.CountOwners(ctx, workspaceID)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+		if owners <= 1 {
+			return model.ErrLastWorkspaceOwner
+		}
+	}
+
+	if err := wu.workspaceRepo.DeleteMember(ctx, workspaceID, userID); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// callerMember returns the membership of the caller, a workspace the caller
+// is not a member of is reported as missing
+func (wu *workspaceUsecase) callerMember(ctx context.Context, workspaceID int64) (*model.WorkspaceMember, error) {
+	userID, ok := model.UserIDFromContext(ctx)
+	if !ok {
+		return nil, model.ErrMissingCredentials
+	}
+
+	member, err := wu.workspaceRepo.FindMember(ctx, workspaceID, userID)
+	if errors.Is(err, model.ErrWorkspaceMemberNotFound) {
+		return nil, model.ErrWorkspaceNotFound
+	}
+	return member, err
+}
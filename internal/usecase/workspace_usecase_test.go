@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"todo-app/internal/model"
+)
+
+// memoryWorkspaceRepo holds workspace 1, acme, whose owner is user 1 and
+// whose member is user 2
+type memoryWorkspaceRepo struct {
+	model.WorkspaceRepository
+
+	members map[int64]*model.WorkspaceMember
+}
+
+func newMemoryWorkspaceRepo() *memoryWorkspaceRepo {
+	return &memoryWorkspaceRepo{members: map[int64]*model.WorkspaceMember{
+		1: {WorkspaceID: 1, UserID: 1, Role: model.WorkspaceRoleOwner},
+		2: {WorkspaceID: 1, UserID: 2, Role: model.WorkspaceRoleMember},
+	}}
+}
+
+func (r *memoryWorkspaceRepo) FindByID(ctx context.Context, ID int64) (*model.Workspace, error) {
+	if ID != 1 {
+		return nil, model.ErrWorkspaceNotFound
+	}
+	return &model.Workspace{ID: 1, Slug: "acme"}, nil
+}
+
+func (r *memoryWorkspaceRepo) FindBySlug(ctx context.Context, slug string) (*model.Workspace, error) {
+	if slug != "acme" {
+		return nil, model.ErrWorkspaceNotFound
+	}
+	return &model.Workspace{ID: 1, Slug: "acme"}, nil
+}
+
+func (r *memoryWorkspaceRepo) FindMember(ctx context.Context, workspaceID, userID int64) (*model.WorkspaceMember, error) {
+	member, ok := r.members[userID]
+	if !ok || workspaceID != 1 {
+		return nil, model.ErrWorkspaceMemberNotFound
+	}
+	return member, nil
+}
+
+func (r *memoryWorkspaceRepo) CountOwners(ctx context.Context, workspaceID int64) (int64, error) {
+	owners := int64(0)
+	for _, member := range r.members {
+		if member.Role == model.WorkspaceRoleOwner {
+			owners++
+		}
+	}
+	return owners, nil
+}
+
+func (r *memoryWorkspaceRepo) AddMember(ctx context.Context, member *model.WorkspaceMember) error {
+	if _, ok := r.members[member.UserID]; ok {
+		return model.ErrWorkspaceMemberExists
+	}
+	r.members[member.UserID] = member
+	return nil
+}
+
+func (r *memoryWorkspaceRepo) DeleteMember(ctx context.Context, workspaceID, userID int64) error {
+	delete(r.members, userID)
+	return nil
+}
+
+func asUser(userID int64) context.Context {
+	return model.ContextWithPrincipal(context.Background(), &model.Principal{Kind: model.PrincipalToken, UserID: userID})
+}
+
+func TestWorkspaceUsecaseResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		ref     string
+		wantErr error
+	}{
+		{name: "by ID", ctx: asUser(2), ref: "1"},
+		{name: "by slug", ctx: asUser(2), ref: "acme"},
+		{name: "unknown", ctx: asUser(2), ref: "globex", wantErr: model.ErrWorkspaceNotFound},
+		{name: "not a member", ctx: asUser(3), ref: "acme", wantErr: model.ErrWorkspaceNotFound},
+		{name: "unauthenticated", ctx: context.Background(), ref: "acme", wantErr: model.ErrMissingCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wu := NewWorkspaceUsecase(newMemoryWorkspaceRepo(), &memoryUserRepo{})
+
+			workspace, err := wu.Resolve(tt.ctx, tt.ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve(%q) error = %v, want %v", tt.ref, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && workspace.ID != 1 {
+				t.Errorf("Resolve(%q) = %+v, want workspace 1", tt.ref, workspace)
+			}
+		})
+	}
+}
+
+func TestWorkspaceUsecaseAddMember(t *testing.T) {
+	users := &memoryUserRepo{users: map[string]*model.User{
+		"carol@example.com": {ID: 3, Email: "carol@example.com"},
+		"bob@example.com":   {ID: 2, Email: "bob@example.com"},
+	}}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		input    model.AddWorkspaceMemberInput
+		wantRole model.WorkspaceRole
+		wantErr  error
+	}{
+		{name: "member by default", ctx: asUser(1), input: model.AddWorkspaceMemberInput{Email: " Carol@example.com"}, wantRole: model.WorkspaceRoleMember},
+		{name: "owner", ctx: asUser(1), input: model.AddWorkspaceMemberInput{Email: "carol@example.com", Role: model.WorkspaceRoleOwner}, wantRole: model.WorkspaceRoleOwner},
+		{name: "already a member", ctx: asUser(1), input: model.AddWorkspaceMemberInput{Email: "bob@example.com"}, wantErr: model.ErrWorkspaceMemberExists},
+		{name: "unregistered user", ctx: asUser(1), input: model.AddWorkspaceMemberInput{Email: "dave@example.com"}, wantErr: model.ErrUserNotFound},
+		{name: "by a plain member", ctx: asUser(2), input: model.AddWorkspaceMemberInput{Email: "carol@example.com"}, wantErr: model.ErrNotWorkspaceOwner},
+		{name: "by an outsider", ctx: asUser(4), input: model.AddWorkspaceMemberInput{Email: "carol@example.com"}, wantErr: model.ErrWorkspaceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wu := NewWorkspaceUsecase(newMemoryWorkspaceRepo(), users)
+
+			member, err := wu.AddMember(tt.ctx, 1, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddMember() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (member.UserID != 3 || member.Role != tt.wantRole) {
+				t.Errorf("AddMember() = %+v, want user 3 as %s", member, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestWorkspaceUsecaseRemoveMember(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		userID  int64
+		wantErr error
+	}{
+		{name: "owner removes a member", ctx: asUser(1), userID: 2},
+		{name: "member leaves", ctx: asUser(2), userID: 2},
+		{name: "member removes the owner", ctx: asUser(2), userID: 1, wantErr: model.ErrNotWorkspaceOwner},
+		{name: "last owner leaves", ctx: asUser(1), userID: 1, wantErr: model.ErrLastWorkspaceOwner},
+		{name: "not a member", ctx: asUser(1), userID: 3, wantErr: model.ErrWorkspaceMemberNotFound},
+		{name: "by an outsider", ctx: asUser(3), userID: 2, wantErr: model.ErrWorkspaceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryWorkspaceRepo()
+			wu := NewWorkspaceUsecase(repo, &memoryUserRepo{})
+
+			err := wu.RemoveMember(tt.ctx, 1, tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveMember() error = %v, want %v", err, tt.wantErr)
+			}
+			wantMembers := 2
+			if tt.wantErr == nil {
+				wantMembers = 1
+			}
+			if len(repo.members) != wantMembers {
+				t.Errorf("%d members left, want %d", len(repo.members), wantMembers)
+			}
+		})
+	}
+}
@@ -1,10 +1,29 @@
 package utils
 
-import "net/http"
+import (
+	"net/http"
 
+	"todo-app/internal/errs"
+)
+
+var statusCodes = map[errs.Kind]int{
+	errs.KindNotFound:             http.StatusNotFound,
+	errs.KindValidation:           http.StatusBadRequest,
+	errs.KindConflict:             http.StatusConflict,
+	errs.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	errs.KindUnprocessable:        http.StatusUnprocessableEntity,
+	errs.KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	errs.KindUnauthorized:         http.StatusUnauthorized,
+	errs.KindForbidden:            http.StatusForbidden,
+	errs.KindRateLimited:          http.StatusTooManyRequests,
+	errs.KindFailedDependency:     http.StatusFailedDependency,
+}
+
+// ParseHTTPErrorStatusCode maps the kind of the domain error in err to its
+// status code, unclassified errors are internal server errors
 func ParseHTTPErrorStatusCode(err error) int {
-	switch err {
-	default:
-		return http.StatusInternalServerError
+	if status, ok := statusCodes[errs.From(err).Kind]; ok {
+		return status
 	}
+	return http.StatusInternalServerError
 }
@@ -0,0 +1,28 @@
+package utils
+
+import "strings"
+
+// MatchETag reports whether etag is listed by an If-Match or If-None-Match
+// header value. If-Match compares strongly, so weak tags never match it,
+// If-None-Match compares weakly.
+func MatchETag(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(candidate, "W/") && candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		etag   string
+		weak   bool
+		want   bool
+	}{
+		{name: "strong match", header: `"3-abc"`, etag: `"3-abc"`, want: true},
+		{name: "strong mismatch", header: `"2-abc"`, etag: `"3-abc"`},
+		{name: "strong match in a list", header: `"1-x", "3-abc"`, etag: `"3-abc"`, want: true},
+		{name: "wildcard", header: `*`, etag: `"3-abc"`, want: true},
+		{name: "weak tag never matches strongly", header: `W/"3-abc"`, etag: `"3-abc"`},
+		{name: "weak match", header: `W/"3-abc"`, etag: `"3-abc"`, weak: true, want: true},
+		{name: "weak mismatch", header: `W/"2-abc"`, etag: `"3-abc"`, weak: true},
+		{name: "weak wildcard", header: `*`, etag: `"3-abc"`, weak: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchETag(tt.header, tt.etag, tt.weak); got != tt.want {
+				t.Errorf("MatchETag(%q, %q, %t) = %t, want %t", tt.header, tt.etag, tt.weak, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,195 @@
 package utils
 
 import (
-	"math/rand"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
 	"time"
 )
 
+const (
+	IDGeneratorSnowflake = "snowflake"
+	IDGeneratorULID      = "ulid"
+	IDGeneratorUUIDv7    = "uuidv7"
+)
+
+// IDGenerator mints the int64 IDs stored in the BIGINT id columns, IDs of a
+// generator increase with time
+type IDGenerator interface {
+	NextID() int64
+}
+
+var (
+	idGenerator   IDGenerator = &snowflakeGenerator{clock: newSnowflakeClock()}
+	idGeneratorMu sync.RWMutex
+)
+
+// SetIDGenerator replaces the generator behind GenerateID, every binary has
+// to call it with the generator of its config before minting IDs
+func SetIDGenerator(generator IDGenerator) {
+	idGeneratorMu.Lock()
+	defer idGeneratorMu.Unlock()
+	idGenerator = generator
+}
+
+// GenerateID mints an ID from the configured generator. Until
+// SetIDGenerator is called it mints on node 0, which is only fit for tests.
 func GenerateID() int64 {
-	return time.Now().UnixNano() + int64(rand.Intn(10000))
+	idGeneratorMu.RLock()
+	defer idGeneratorMu.RUnlock()
+	return idGenerator.NextID()
+}
+
+// NewIDGenerator builds the generator of the given kind, an empty kind is a
+// snowflake generator. Only the snowflake generator uses node.
+func NewIDGenerator(kind string, node int64) (IDGenerator, error) {
+	switch kind {
+	case "", IDGeneratorSnowflake:
+		return NewSnowflakeGenerator(node)
+	case IDGeneratorULID:
+		return NewULIDGenerator(), nil
+	case IDGeneratorUUIDv7:
+		return NewUUIDv7Generator(), nil
+	default:
+		return nil, fmt.Errorf("id generator %q is unknown", kind)
+	}
+}
+
+// monotonicClock hands out (millisecond, sequence) pairs which strictly
+// increase. When the sequence of a millisecond runs out or the wall clock
+// goes backwards it carries on from the last millisecond instead of waiting.
+type monotonicClock struct {
+	mu       sync.Mutex
+	last     int64
+	sequence int64
+	max      int64
+	// reseed picks the first sequence of a millisecond, zero when nil
+	reseed func() int64
+}
+
+func (c *monotonicClock) next() (int64, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	switch {
+	case now > c.last:
+		c.last = now
+		c.sequence = c.first()
+	case c.sequence < c.max:
+		c.sequence++
+	default:
+		c.last++
+		c.sequence = c.first()
+	}
+	return c.last, c.sequence
+}
+
+func (c *monotonicClock) first() int64 {
+	if c.reseed == nil {
+		return 0
+	}
+	return c.reseed()
+}
+
+// randomBits returns n bits read from crypto/rand
+func randomBits(n uint) int64 {
+	b := [8]byte{}
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) >> (64 - n))
+}
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+
+	// snowflakeEpoch keeps snowflake IDs above the nanosecond timestamps the
+	// IDs used to be, so they sort after every existing row
+	snowflakeEpoch = 1288834974657
+)
+
+type snowflakeGenerator struct {
+	node  int64
+	clock *monotonicClock
+}
+
+// NewSnowflakeGenerator lays out IDs as 41 bits of milliseconds, 10 bits of
+// node and 12 bits of sequence. IDs never collide as long as every replica
+// runs on its own node.
+func NewSnowflakeGenerator(node int64) (IDGenerator, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, fmt.Errorf("snowflake node %d is out of range 0-%d", node, snowflakeMaxNode)
+	}
+
+	return &snowflakeGenerator{
+		node:  node,
+		clock: newSnowflakeClock(),
+	}, nil
+}
+
+func newSnowflakeClock() *monotonicClock {
+	return &monotonicClock{max: 1<<snowflakeSequenceBits - 1}
+}
+
+func (g *snowflakeGenerator) NextID() int64 {
+	ms, sequence := g.clock.next()
+	return (ms-snowflakeEpoch)<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | sequence
+}
+
+const ulidRandomBits = 15
+
+type ulidGenerator struct {
+	clock *monotonicClock
+}
+
+// NewULIDGenerator folds a monotonic ULID into 63 bits: its 48 bits of
+// milliseconds followed by 15 bits of its randomness, which count up within
+// a millisecond from a random start. Replicas need no node, but two of them
+// minting in the same millisecond may collide, which the primary key
+// rejects. The IDs sort before the nanosecond IDs minted before generators.
+func NewULIDGenerator() IDGenerator {
+	return &ulidGenerator{
+		clock: &monotonicClock{
+			max: 1<<ulidRandomBits - 1,
+			// start in the lower half so that the counter has room
+			reseed: func() int64 { return randomBits(ulidRandomBits - 1) },
+		},
+	}
+}
+
+func (g *ulidGenerator) NextID() int64 {
+	ms, random := g.clock.next()
+	return ms<<ulidRandomBits | random
+}
+
+const (
+	uuidv7Version  = 7
+	uuidv7RandBits = 12
+)
+
+type uuidv7Generator struct {
+	clock *monotonicClock
+}
+
+// NewUUIDv7Generator folds a UUIDv7 into its upper 64 bits: 48 bits of
+// milliseconds, the version and rand_a used as a counter seeded randomly
+// each millisecond, as RFC 9562 allows. The sign bit stays clear until the
+// year 6429. Like ULIDs, replicas may collide within a millisecond and the
+// IDs sort before the nanosecond IDs minted before generators.
+func NewUUIDv7Generator() IDGenerator {
+	return &uuidv7Generator{
+		clock: &monotonicClock{
+			max:    1<<uuidv7RandBits - 1,
+			reseed: func() int64 { return randomBits(uuidv7RandBits - 1) },
+		},
+	}
+}
+
+func (g *uuidv7Generator) NextID() int64 {
+	ms, counter := g.clock.next()
+	return ms<<16 | uuidv7Version<<uuidv7RandBits | counter
 }
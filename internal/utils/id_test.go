@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSnowflakeGenerator(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    int64
+		wantErr bool
+	}{
+		{name: "first node", node: 0},
+		{name: "last node", node: snowflakeMaxNode},
+		{name: "negative node", node: -1, wantErr: true},
+		{name: "node past the node bits", node: snowflakeMaxNode + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator, err := NewSnowflakeGenerator(tt.node)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSnowflakeGenerator(%d) error = %v, wantErr %v", tt.node, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			ID := generator.NextID()
+			if node := ID >> snowflakeSequenceBits & snowflakeMaxNode; node != tt.node {
+				t.Errorf("NextID() = %d carries node %d, want %d", ID, node, tt.node)
+			}
+		})
+	}
+}
+
+func TestSnowflakeGeneratorSortsAfterLegacyIDs(t *testing.T) {
+	generator, err := NewSnowflakeGenerator(snowflakeMaxNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// IDs used to be the nanosecond timestamp plus up to 10000
+	legacy := time.Now().UnixNano() + 10000
+	if ID := generator.NextID(); ID <= legacy {
+		t.Errorf("NextID() = %d, want above the legacy ID %d", ID, legacy)
+	}
+}
+
+func TestNewIDGenerator(t *testing.T) {
+	tests := []struct {
+		kind    string
+		wantErr bool
+	}{
+		{kind: ""},
+		{kind: IDGeneratorSnowflake},
+		{kind: IDGeneratorULID},
+		{kind: IDGeneratorUUIDv7},
+		{kind: "uuidv4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			generator, err := NewIDGenerator(tt.kind, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIDGenerator(%q) error = %v, wantErr %v", tt.kind, err, tt.wantErr)
+			}
+			if !tt.wantErr && generator.NextID() <= 0 {
+				t.Errorf("NextID() is not positive")
+			}
+		})
+	}
+}
+
+func TestUUIDv7GeneratorCarriesVersionAndTime(t *testing.T) {
+	before := time.Now().UnixMilli()
+	ID := NewUUIDv7Generator().NextID()
+
+	if version := ID >> uuidv7RandBits & 0xf; version != uuidv7Version {
+		t.Errorf("NextID() = %d carries version %d, want %d", ID, version, uuidv7Version)
+	}
+	if ms := ID >> 16; ms < before || ms > time.Now().UnixMilli()+1 {
+		t.Errorf("NextID() = %d carries millisecond %d, want about %d", ID, ms, before)
+	}
+}
+
+// newTestGenerators returns generators of every kind, replicas of them when
+// the kind runs on a node of its own
+func newTestGenerators(t *testing.T, replicas int) map[string][]IDGenerator {
+	t.Helper()
+
+	generators := map[string][]IDGenerator{}
+	for node := 0; node < replicas; node++ {
+		generator, err := NewSnowflakeGenerator(int64(node))
+		if err != nil {
+			t.Fatal(err)
+		}
+		generators[IDGeneratorSnowflake] = append(generators[IDGeneratorSnowflake], generator)
+	}
+	generators[IDGeneratorULID] = []IDGenerator{NewULIDGenerator()}
+	generators[IDGeneratorUUIDv7] = []IDGenerator{NewUUIDv7Generator()}
+	return generators
+}
+
+func TestIDGeneratorsIncrease(t *testing.T) {
+	for kind, generators := range newTestGenerators(t, 1) {
+		t.Run(kind, func(t *testing.T) {
+			// more IDs than the sequence of one millisecond holds
+			last := int64(0)
+			for i := 0; i < 4*(1<<ulidRandomBits); i++ {
+				ID := generators[0].NextID()
+				if ID <= last {
+					t.Fatalf("NextID() = %d after %d, want increasing IDs", ID, last)
+				}
+				last = ID
+			}
+		})
+	}
+}
+
+func TestIDGeneratorsAreUniqueUnderConcurrency(t *testing.T) {
+	const (
+		replicas   = 4
+		goroutines = 32
+		perRoutine = 2000
+	)
+
+	for kind, generators := range newTestGenerators(t, replicas) {
+		t.Run(kind, func(t *testing.T) {
+			total := len(generators) * goroutines * perRoutine
+			IDs := make(chan int64, total)
+			wg := sync.WaitGroup{}
+			for _, generator := range generators {
+				for i := 0; i < goroutines; i++ {
+					wg.Add(1)
+					go func(generator IDGenerator) {
+						defer wg.Done()
+						for j := 0; j < perRoutine; j++ {
+							IDs <- generator.NextID()
+						}
+					}(generator)
+				}
+			}
+			wg.Wait()
+			close(IDs)
+
+			seen := make(map[int64]struct{}, total)
+			for ID := range IDs {
+				if ID <= 0 {
+					t.Fatalf("ID %d does not fit a positive BIGINT", ID)
+				}
+				if _, ok := seen[ID]; ok {
+					t.Fatalf("ID %d was minted twice", ID)
+				}
+				seen[ID] = struct{}{}
+			}
+			if len(seen) != total {
+				t.Errorf("minted %d IDs, want %d", len(seen), total)
+			}
+		})
+	}
+}
+
+func TestGenerateIDIsUniqueUnderConcurrency(t *testing.T) {
+	const (
+		goroutines = 64
+		perRoutine = 1000
+	)
+
+	results := make([][]int64, goroutines)
+	wg := sync.WaitGroup{}
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			IDs := make([]int64, perRoutine)
+			for j := range IDs {
+				IDs[j] = GenerateID()
+			}
+			results[i] = IDs
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]struct{}, goroutines*perRoutine)
+	for _, IDs := range results {
+		for j, ID := range IDs {
+			if _, ok := seen[ID]; ok {
+				t.Fatalf("ID %d was minted twice", ID)
+			}
+			if j > 0 && ID <= IDs[j-1] {
+				t.Fatalf("GenerateID() = %d after %d within a goroutine, want increasing IDs", ID, IDs[j-1])
+			}
+			seen[ID] = struct{}{}
+		}
+	}
+}
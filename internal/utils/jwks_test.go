@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestParseJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+
+	tests := []struct {
+		name     string
+		jwks     string
+		wantKids []string
+		wantErr  bool
+	}{
+		{
+			name:     "signing key",
+			jwks:     fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","use":"sig","n":"%s","e":"%s"}]}`, n, e),
+			wantKids: []string{"k1"},
+		},
+		{
+			name:     "key without use",
+			jwks:     fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","n":"%s","e":"%s"}]}`, n, e),
+			wantKids: []string{"k1"},
+		},
+		{
+			name: "encryption and non RSA keys are skipped",
+			jwks: fmt.Sprintf(`{"keys":[
+				{"kty":"RSA","kid":"enc","use":"enc","n":"%s","e":"%s"},
+				{"kty":"EC","kid":"ec","crv":"P-256","x":"AA","y":"AA"},
+				{"kty":"RSA","kid":"k2","use":"sig","n":"%s","e":"%s"}
+			]}`, n, e, n, e),
+			wantKids: []string{"k2"},
+		},
+		{name: "empty set", jwks: `{"keys":[]}`, wantKids: []string{}},
+		{name: "not json", jwks: `keys`, wantErr: true},
+		{name: "modulus not base64url", jwks: fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","n":"%%%%","e":"%s"}]}`, e), wantErr: true},
+		{name: "missing exponent", jwks: fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","n":"%s"}]}`, n), wantErr: true},
+		{name: "missing modulus", jwks: fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","e":"%s"}]}`, e), wantErr: true},
+		{name: "exponent too large", jwks: fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","n":"%s","e":"AQAAAAAB"}]}`, n), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseJWKS([]byte(tt.jwks))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJWKS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(keys) != len(tt.wantKids) {
+				t.Fatalf("ParseJWKS() returned %d keys, want %d", len(keys), len(tt.wantKids))
+			}
+			for _, kid := range tt.wantKids {
+				if !key.PublicKey.Equal(keys[kid]) {
+					t.Errorf("ParseJWKS() key %q does not match the encoded key", kid)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRSAPublicKeyPEM(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPKIX, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "pkix", data: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix})},
+		{name: "pkcs1", data: pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})},
+		{name: "not pem", data: []byte("public key"), wantErr: true},
+		{name: "not an RSA key", data: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecPKIX}), wantErr: true},
+		{name: "garbage in the block", data: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("garbage")}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRSAPublicKeyPEM(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRSAPublicKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !key.PublicKey.Equal(got) {
+				t.Error("ParseRSAPublicKeyPEM() does not match the encoded key")
+			}
+		})
+	}
+}
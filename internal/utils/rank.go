@@ -0,0 +1,45 @@
+package utils
+
+import "strings"
+
+// rankDigits are ordered by their byte value, ranks therefore compare like
+// plain strings as long as the database compares them bytewise
+const rankDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const rankBase = len(rankDigits)
+
+// RankBetween returns a rank sorting strictly between a and b. An empty a
+// stands for the start and an empty b for the end of the list. Ranks never
+// end with the lowest digit, so there is always room before any rank.
+func RankBetween(a, b string) string {
+	if b != "" && a >= b {
+		b = ""
+	}
+
+	prefix := strings.Builder{}
+	for i := 0; ; i++ {
+		da, db := 0, rankBase
+		if i < len(a) {
+			da = strings.IndexByte(rankDigits, a[i])
+		}
+		if b != "" && i < len(b) {
+			db = strings.IndexByte(rankDigits, b[i])
+		}
+
+		switch {
+		case da == db:
+			prefix.WriteByte(rankDigits[da])
+		case db-da > 1:
+			prefix.WriteByte(rankDigits[(da+db)/2])
+			return prefix.String()
+		default:
+			// no digit fits between, keep da and go past the rest of a
+			prefix.WriteByte(rankDigits[da])
+			rest := ""
+			if i+1 < len(a) {
+				rest = a[i+1:]
+			}
+			return prefix.String() + RankBetween(rest, "")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "empty list", want: "V"},
+		{name: "at the end", a: "V", want: "k"},
+		{name: "at the start", b: "V", want: "F"},
+		{name: "between far apart", a: "F", b: "V", want: "N"},
+		{name: "between adjacent digits", a: "A", b: "B", want: "AV"},
+		{name: "after the highest digit", a: "z", want: "zV"},
+		{name: "before a rank of the lowest digits", b: "01", want: "00V"},
+		{name: "past the rest of a", a: "0V", b: "1", want: "0k"},
+		{name: "b not after a is ignored", a: "a", b: "a", want: "n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RankBetween(tt.a, tt.b); got != tt.want {
+				t.Errorf("RankBetween(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRankBetweenKeepsOrder inserts ranks at random places of a list, every
+// rank must sort between its neighbours and leave room before it
+func TestRankBetweenKeepsOrder(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	ranks := []string{}
+
+	for i := 0; i < 2000; i++ {
+		at := random.Intn(len(ranks) + 1)
+		a, b := "", ""
+		if at > 0 {
+			a = ranks[at-1]
+		}
+		if at < len(ranks) {
+			b = ranks[at]
+		}
+
+		rank := RankBetween(a, b)
+		if rank <= a || (b != "" && rank >= b) {
+			t.Fatalf("RankBetween(%q, %q) = %q, want a rank between them", a, b, rank)
+		}
+		if rank[len(rank)-1] == rankDigits[0] {
+			t.Fatalf("RankBetween(%q, %q) = %q ends with the lowest digit", a, b, rank)
+		}
+
+		ranks = append(ranks[:at], append([]string{rank}, ranks[at:]...)...)
+	}
+}
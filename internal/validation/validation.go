@@ -0,0 +1,81 @@
+// Package validation enforces the validate struct tags of the inputs, see
+// https://pkg.go.dev/github.com/go-playground/validator/v10 for the rules.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"todo-app/internal/errs"
+)
+
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// fields are reported by the name clients send them under
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "param"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	return v
+}
+
+// Struct checks the validate tags of s, every offending field is listed in
+// the returned validation error
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+
+	fieldErrs := validator.ValidationErrors{}
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	fields := []errs.FieldError{}
+	for _, fieldErr := range fieldErrs {
+		fields = append(fields, errs.FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Message: message(fieldErr),
+		})
+	}
+
+	return errs.ErrValidation.WithFields(fields...)
+}
+
+func message(fieldErr validator.FieldError) string {
+	unit := ""
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Map:
+		unit = " items"
+	}
+
+	field := fieldErr.Field()
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", field, fieldErr.Param(), unit)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", field, fieldErr.Param(), unit)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, fieldErr.Param())
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
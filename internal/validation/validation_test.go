@@ -0,0 +1,119 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"todo-app/internal/errs"
+)
+
+type input struct {
+	Title     string   `json:"title" validate:"required,max=5"`
+	Todo      string   `json:"todo,omitempty" validate:"min=2"`
+	ProjectID *int64   `json:"project_id" validate:"omitempty,gt=0"`
+	Tags      []string `json:"tags" validate:"max=2,dive,max=3"`
+	Page      int64    `query:"page" validate:"gte=0,lte=9"`
+	Mode      string   `json:"mode" validate:"oneof=atomic partial"`
+	Unnamed   string   `json:"-" validate:"email"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := func() input {
+		return input{Title: "ok", Todo: "ok", Mode: "atomic", Unnamed: "a@example.com"}
+	}
+	zero := int64(0)
+
+	tests := []struct {
+		name   string
+		change func(i *input)
+		want   []errs.FieldError
+	}{
+		{
+			name:   "valid",
+			change: func(i *input) {},
+		},
+		{
+			name:   "required",
+			change: func(i *input) { i.Title = "" },
+			want:   []errs.FieldError{{Field: "title", Rule: "required", Message: "title is required"}},
+		},
+		{
+			name:   "string too long",
+			change: func(i *input) { i.Title = "report" },
+			want:   []errs.FieldError{{Field: "title", Rule: "max", Message: "title must be at most 5 characters"}},
+		},
+		{
+			name:   "string too short",
+			change: func(i *input) { i.Todo = "x" },
+			want:   []errs.FieldError{{Field: "todo", Rule: "min", Message: "todo must be at least 2 characters"}},
+		},
+		{
+			name:   "number not greater",
+			change: func(i *input) { i.ProjectID = &zero },
+			want:   []errs.FieldError{{Field: "project_id", Rule: "gt", Message: "project_id must be greater than 0"}},
+		},
+		{
+			name:   "too many items",
+			change: func(i *input) { i.Tags = []string{"a", "b", "c"} },
+			want:   []errs.FieldError{{Field: "tags", Rule: "max", Message: "tags must be at most 2 items"}},
+		},
+		{
+			name:   "item too long",
+			change: func(i *input) { i.Tags = []string{"a", "home"} },
+			want:   []errs.FieldError{{Field: "tags[1]", Rule: "max", Message: "tags[1] must be at most 3 characters"}},
+		},
+		{
+			name:   "query param bounds",
+			change: func(i *input) { i.Page = 10 },
+			want:   []errs.FieldError{{Field: "page", Rule: "lte", Message: "page must be at most 9"}},
+		},
+		{
+			name:   "one of",
+			change: func(i *input) { i.Mode = "some" },
+			want:   []errs.FieldError{{Field: "mode", Rule: "oneof", Message: "mode must be one of atomic partial"}},
+		},
+		{
+			name:   "other rules",
+			change: func(i *input) { i.Unnamed = "nobody" },
+			want:   []errs.FieldError{{Field: "Unnamed", Rule: "email", Message: "Unnamed is invalid"}},
+		},
+		{
+			name:   "every field is listed",
+			change: func(i *input) { i.Title, i.Mode = "", "" },
+			want: []errs.FieldError{
+				{Field: "title", Rule: "required", Message: "title is required"},
+				{Field: "mode", Rule: "oneof", Message: "mode must be one of atomic partial"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := valid()
+			tt.change(&i)
+
+			err := Struct(i)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Struct() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, errs.ErrValidation) {
+				t.Fatalf("Struct() error = %v, want a validation error", err)
+			}
+			if got := errs.From(err).Fields; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() fields = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructNotAStruct(t *testing.T) {
+	err := Struct("title")
+	if err == nil || errors.Is(err, errs.ErrValidation) {
+		t.Errorf("Struct() error = %v, want the error of the validator", err)
+	}
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+)
+
+type PositionWorker struct {
+	PositionUsecase model.PositionUsecase
+	Interval        time.Duration
+}
+
+func NewPositionWorker(pu model.PositionUsecase, interval time.Duration) *PositionWorker {
+	return &PositionWorker{
+		PositionUsecase: pu,
+		Interval:        interval,
+	}
+}
+
+// Start rebalances the task positions every interval until ctx is done. The
+// rebalance locks the tasks table against writes, running it on several
+// replicas only serializes them.
+func (w *PositionWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rebalanced, err := w.PositionUsecase.Rebalance(ctx)
+			if err != nil {
+				logrus.Error(err)
+				continue
+			}
+
+			if rebalanced > 0 {
+				logrus.Infof("Rebalanced the positions of %d tasks", rebalanced)
+			}
+		}
+	}
+}
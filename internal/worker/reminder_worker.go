@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"todo-app/internal/model"
+)
+
+type ReminderWorker struct {
+	ReminderUsecase model.ReminderUsecase
+	Interval        time.Duration
+}
+
+func NewReminderWorker(ru model.ReminderUsecase, interval time.Duration) *ReminderWorker {
+	return &ReminderWorker{
+		ReminderUsecase: ru,
+		Interval:        interval,
+	}
+}
+
+// Start dispatches due notifications every interval until ctx is done. Tasks
+// are claimed with SKIP LOCKED so several replicas can run side by side.
+func (w *ReminderWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dispatched, err := w.ReminderUsecase.DispatchDue(ctx)
+			if err != nil {
+				logrus.Error(err)
+				continue
+			}
+
+			if dispatched > 0 {
+				logrus.Infof("Dispatched %d task notifications", dispatched)
+			}
+		}
+	}
+}